@@ -2,7 +2,7 @@ package backend
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -24,6 +24,9 @@ type AmazonDownloader struct {
 	lastAPICallTime  time.Time
 	apiCallCount     int
 	apiCallResetTime time.Time
+
+	ExistingFiles ExistingFileAction
+	Release       ReleaseTags
 }
 
 type SongLinkResponse struct {
@@ -65,9 +68,7 @@ type LucidaStatusResponse struct {
 
 func NewAmazonDownloader() *AmazonDownloader {
 	return &AmazonDownloader{
-		client: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		client:           newHTTPClient("amazon", 120*time.Second),
 		regions:          []string{"us", "eu"},
 		apiCallResetTime: time.Now(),
 	}
@@ -81,7 +82,7 @@ func (a *AmazonDownloader) getRandomUserAgent() string {
 		rand.Intn(7)+530, rand.Intn(6)+30)
 }
 
-func (a *AmazonDownloader) GetAmazonURLFromSpotify(spotifyTrackID string) (string, error) {
+func (a *AmazonDownloader) GetAmazonURLFromSpotify(ctx context.Context, spotifyTrackID string) (string, error) {
 
 	now := time.Now()
 	if now.Sub(a.apiCallResetTime) >= time.Minute {
@@ -92,8 +93,10 @@ func (a *AmazonDownloader) GetAmazonURLFromSpotify(spotifyTrackID string) (strin
 	if a.apiCallCount >= 9 {
 		waitTime := time.Minute - now.Sub(a.apiCallResetTime)
 		if waitTime > 0 {
-			fmt.Printf("Rate limit reached, waiting %v...\n", waitTime.Round(time.Second))
-			time.Sleep(waitTime)
+			amazonLog.Info("song.link rate limit reached, waiting", "wait", waitTime.Round(time.Second))
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return "", err
+			}
 			a.apiCallCount = 0
 			a.apiCallResetTime = time.Now()
 		}
@@ -104,8 +107,10 @@ func (a *AmazonDownloader) GetAmazonURLFromSpotify(spotifyTrackID string) (strin
 		minDelay := 7 * time.Second
 		if timeSinceLastCall < minDelay {
 			waitTime := minDelay - timeSinceLastCall
-			fmt.Printf("Rate limiting: waiting %v...\n", waitTime.Round(time.Second))
-			time.Sleep(waitTime)
+			amazonLog.Debug("rate limiting song.link requests", "wait", waitTime.Round(time.Second))
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return "", err
+			}
 		}
 	}
 
@@ -115,43 +120,20 @@ func (a *AmazonDownloader) GetAmazonURLFromSpotify(spotifyTrackID string) (strin
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9hcGkuc29uZy5saW5rL3YxLWFscGhhLjEvbGlua3M/dXJsPQ==")
 	apiURL := fmt.Sprintf("%s%s", string(apiBase), url.QueryEscape(spotifyURL))
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", a.getRandomUserAgent())
 
-	fmt.Println("Getting Amazon URL...")
+	amazonLog.Info("getting Amazon URL from song.link", "spotify_id", spotifyTrackID)
 
-	maxRetries := 3
-	var resp *http.Response
-	for i := 0; i < maxRetries; i++ {
-		resp, err = a.client.Do(req)
-		if err != nil {
-			return "", fmt.Errorf("failed to get Amazon URL: %w", err)
-		}
-
-		a.lastAPICallTime = time.Now()
-		a.apiCallCount++
-
-		if resp.StatusCode == 429 {
-			resp.Body.Close()
-			if i < maxRetries-1 {
-				waitTime := 15 * time.Second
-				fmt.Printf("Rate limited by API, waiting %v before retry...\n", waitTime)
-				time.Sleep(waitTime)
-				continue
-			}
-			return "", fmt.Errorf("API rate limit exceeded after %d retries", maxRetries)
-		}
-
-		if resp.StatusCode != 200 {
-			resp.Body.Close()
-			return "", fmt.Errorf("API returned status %d", resp.StatusCode)
-		}
-
-		break
+	resp, err := doRequest(ctx, a.client, req, "song.link", SongLinkRetryPolicy)
+	a.lastAPICallTime = time.Now()
+	a.apiCallCount++
+	if err != nil {
+		return "", fmt.Errorf("failed to get Amazon URL: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -176,7 +158,7 @@ func (a *AmazonDownloader) GetAmazonURLFromSpotify(spotifyTrackID string) (strin
 
 	amazonLink, ok := songLinkResp.LinksByPlatform["amazonMusic"]
 	if !ok || amazonLink.URL == "" {
-		return "", fmt.Errorf("amazon Music link not found")
+		return "", newServiceError("song.link", ErrNotFound, "amazon Music link not found", nil)
 	}
 
 	amazonURL := amazonLink.URL
@@ -190,10 +172,25 @@ func (a *AmazonDownloader) GetAmazonURLFromSpotify(spotifyTrackID string) (strin
 		}
 	}
 
-	fmt.Printf("Found Amazon URL: %s\n", amazonURL)
+	amazonLog.Info("found Amazon URL", "url", amazonURL)
 	return amazonURL, nil
 }
 
+// amazonTrackASIN returns the track ASIN from an Amazon Music track URL
+func amazonTrackASIN(amazonURL string) string {
+	parsed, err := url.Parse(amazonURL)
+	if err != nil {
+		return ""
+	}
+	if asin := parsed.Query().Get("trackAsin"); asin != "" {
+		return asin
+	}
+	if _, asin, ok := strings.Cut(parsed.Path, "/tracks/"); ok {
+		return strings.Trim(asin, "/")
+	}
+	return ""
+}
+
 func (a *AmazonDownloader) extractData(html string, patterns []string) string {
 	for _, p := range patterns {
 		re := regexp.MustCompile(p)
@@ -205,26 +202,22 @@ func (a *AmazonDownloader) extractData(html string, patterns []string) string {
 	return ""
 }
 
-func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality string) (string, error) {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
+func (a *AmazonDownloader) DownloadFromLucida(ctx context.Context, amazonURL, outputDir, quality string) (string, error) {
 	jar, _ := cookiejar.New(nil)
-	client := &http.Client{
-		Transport: tr,
-		Jar:       jar,
-		Timeout:   120 * time.Second,
-	}
+	client := newHTTPClient("lucida", 120*time.Second)
+	client.Jar = jar
 
 	userAgent := a.getRandomUserAgent()
 
-	fmt.Printf("Initializing lucida for Amazon Music... (Target: %s)\n", amazonURL)
+	amazonLog.Info("initializing lucida", "url", amazonURL)
 	lucidaBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9sdWNpZGEudG8vP3VybD0lcyZjb3VudHJ5PWF1dG8=")
 	lucidaURL := fmt.Sprintf(string(lucidaBase), url.QueryEscape(amazonURL))
-	req, _ := http.NewRequest("GET", lucidaURL, nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", lucidaURL, nil)
 	req.Header.Set("User-Agent", userAgent)
 
+	start := time.Now()
 	resp, err := client.Do(req)
+	observeMirror("amazon", lucidaURL, start, responseError("lucida", resp, err))
 	if err != nil {
 		return "", err
 	}
@@ -240,9 +233,9 @@ func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality stri
 	if token == "" || streamURL == "" {
 		errorMsg := a.extractData(html, []string{`error:"([^"]+)"`, `"error"\s*:\s*"([^"]+)"`})
 		if errorMsg != "" {
-			return "", fmt.Errorf("lucida error: %s", errorMsg)
+			return "", newServiceError("lucida", ErrUpstreamDown, errorMsg, nil)
 		}
-		return "", fmt.Errorf("could not extract required data from lucida")
+		return "", newServiceError("lucida", ErrUpstreamDown, "could not extract required data", nil)
 	}
 
 	decodedToken := token
@@ -253,7 +246,7 @@ func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality stri
 	}
 
 	streamURL = strings.ReplaceAll(streamURL, `\/`, `/`)
-	fmt.Printf("Fetching Amazon stream via Lucida...\n")
+	amazonLog.Debug("fetching stream via lucida")
 
 	loadPayload := map[string]interface{}{
 		"account": map[string]string{"id": "auto", "type": "country"},
@@ -266,7 +259,7 @@ func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality stri
 
 	payloadBytes, _ := json.Marshal(loadPayload)
 	loadAPI, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9sdWNpZGEudG8vYXBpL2xvYWQ/dXJsPS9hcGkvZmV0Y2gvc3RyZWFtL3Yy")
-	req, _ = http.NewRequest("POST", string(loadAPI), bytes.NewBuffer(payloadBytes))
+	req, _ = http.NewRequestWithContext(ctx, "POST", string(loadAPI), bytes.NewBuffer(payloadBytes))
 	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("Content-Type", "application/json")
 
@@ -286,17 +279,17 @@ func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality stri
 	json.NewDecoder(resp.Body).Decode(&loadData)
 
 	if !loadData.Success {
-		return "", fmt.Errorf("lucida load request failed: %s", loadData.Error)
+		return "", newServiceError("lucida", ErrUpstreamDown, "load request failed: "+loadData.Error, nil)
 	}
 
 	serviceBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly8=")
 	completionBase, _ := base64.StdEncoding.DecodeString("Lmx1Y2lkYS50by9hcGkvZmV0Y2gvcmVxdWVzdC8=")
 	completionURL := fmt.Sprintf("%s%s%s%s", string(serviceBase), loadData.Server, string(completionBase), loadData.Handoff)
-	fmt.Println("Processing on Lucida server...")
+	amazonLog.Info("processing on lucida server")
 
 	var finalStatus LucidaStatusResponse
 	for {
-		req, _ = http.NewRequest("GET", completionURL, nil)
+		req, _ = http.NewRequestWithContext(ctx, "GET", completionURL, nil)
 		req.Header.Set("User-Agent", userAgent)
 		resp, err = client.Do(req)
 		if err != nil {
@@ -307,20 +300,23 @@ func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality stri
 		resp.Body.Close()
 
 		if finalStatus.Status == "completed" {
-			fmt.Println("\nTrack processing completed!")
+			progressf("\n")
+			amazonLog.Info("lucida processing completed")
 			break
 		} else if finalStatus.Status == "error" {
-			return "", fmt.Errorf("lucida processing failed: %s", finalStatus.Message)
+			return "", newServiceError("lucida", ErrUpstreamDown, "processing failed: "+finalStatus.Message, nil)
 		} else if finalStatus.Progress.Total > 0 {
 			percent := (finalStatus.Progress.Current * 100) / finalStatus.Progress.Total
-			fmt.Printf("\rLucida Progress: %d%%", percent)
+			progressf("\rLucida Progress: %d%%", percent)
+		}
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return "", err
 		}
-		time.Sleep(2 * time.Second)
 	}
 
 	downloadSuffix, _ := base64.StdEncoding.DecodeString("L2Rvd25sb2Fk")
 	downloadURL := fmt.Sprintf("%s%s%s%s%s", string(serviceBase), loadData.Server, string(completionBase), loadData.Handoff, string(downloadSuffix))
-	req, _ = http.NewRequest("GET", downloadURL, nil)
+	req, _ = http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
 	req.Header.Set("User-Agent", userAgent)
 	resp, err = client.Do(req)
 	if err != nil {
@@ -329,7 +325,7 @@ func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality stri
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("lucida download failed with status %d", resp.StatusCode)
+		return "", fmt.Errorf("lucida download failed: %w", statusError("lucida", resp))
 	}
 
 	fileName := "track.flac"
@@ -357,9 +353,9 @@ func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality stri
 	}
 	defer out.Close()
 
-	fmt.Printf("Downloading from Lucida: %s\n", fileName)
+	amazonLog.Info("downloading from lucida", "file", fileName)
 
-	pw := NewProgressWriter(out)
+	pw := NewProgressWriter(ctx, out)
 	_, err = io.Copy(pw, resp.Body)
 	if err != nil {
 		out.Close()
@@ -367,23 +363,26 @@ func (a *AmazonDownloader) DownloadFromLucida(amazonURL, outputDir, quality stri
 		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
-	fmt.Printf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
+	progressf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
 	return filePath, nil
 }
 
-func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality string) (string, error) {
-	fmt.Println("Attempting download via Lucida (Priority)...")
-	filePath, err := a.DownloadFromLucida(amazonURL, outputDir, quality)
+func (a *AmazonDownloader) DownloadFromService(ctx context.Context, amazonURL, outputDir, quality string) (string, error) {
+	amazonLog.Info("attempting download via lucida")
+	filePath, err := a.DownloadFromLucida(ctx, amazonURL, outputDir, quality)
 	if err == nil {
 		return filePath, nil
 	}
-	fmt.Printf("Lucida failed: %v\nTrying Double-Double as fallback...\n", err)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	amazonLog.Warn("lucida failed, trying Double-Double", "error", err)
 
 	var lastError error
 	lastError = err
 
 	for _, region := range a.regions {
-		fmt.Printf("\nTrying region: %s...\n", region)
+		amazonLog.Info("trying Double-Double region", "region", region)
 
 		serviceBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly8=")
 		serviceDomain, _ := base64.StdEncoding.DecodeString("LmRvdWJsZWRvdWJsZS50b3A=")
@@ -392,7 +391,7 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 		encodedURL := url.QueryEscape(amazonURL)
 		submitURL := fmt.Sprintf("%s/dl?url=%s", baseURL, encodedURL)
 
-		req, err := http.NewRequest("GET", submitURL, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", submitURL, nil)
 		if err != nil {
 			lastError = fmt.Errorf("failed to create request: %w", err)
 			continue
@@ -400,8 +399,10 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 
 		req.Header.Set("User-Agent", a.getRandomUserAgent())
 
-		fmt.Println("Submitting download request...")
+		amazonLog.Debug("submitting download request", "region", region)
+		start := time.Now()
 		resp, err := a.client.Do(req)
+		observeMirror("amazon", baseURL, start, responseError("doubledouble", resp, err))
 		if err != nil {
 			lastError = fmt.Errorf("failed to submit request: %w", err)
 			continue
@@ -409,7 +410,7 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 
 		if resp.StatusCode != 200 {
 			resp.Body.Close()
-			lastError = fmt.Errorf("submit failed with status %d", resp.StatusCode)
+			lastError = fmt.Errorf("submit failed: %w", statusError("doubledouble", resp))
 			continue
 		}
 
@@ -427,20 +428,21 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 		}
 
 		downloadID := submitResp.ID
-		fmt.Printf("Download ID: %s\n", downloadID)
+		amazonLog.Debug("download submitted", "region", region, "id", downloadID)
 
 		statusURL := fmt.Sprintf("%s/dl/%s", baseURL, downloadID)
-		fmt.Println("Waiting for download to complete...")
 
 		maxWait := 300 * time.Second
 		elapsed := time.Duration(0)
 		pollInterval := 3 * time.Second
 
 		for elapsed < maxWait {
-			time.Sleep(pollInterval)
+			if err := sleepContext(ctx, pollInterval); err != nil {
+				return "", err
+			}
 			elapsed += pollInterval
 
-			statusReq, err := http.NewRequest("GET", statusURL, nil)
+			statusReq, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
 			if err != nil {
 				continue
 			}
@@ -449,26 +451,27 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 
 			statusResp, err := a.client.Do(statusReq)
 			if err != nil {
-				fmt.Printf("\rStatus check failed, retrying...")
+				amazonLog.Debug("status check failed, retrying", "region", region, "error", err)
 				continue
 			}
 
 			if statusResp.StatusCode != 200 {
 				statusResp.Body.Close()
-				fmt.Printf("\rStatus check failed (status %d), retrying...", statusResp.StatusCode)
+				amazonLog.Debug("status check failed, retrying", "region", region, "status", statusResp.StatusCode)
 				continue
 			}
 
 			var status DoubleDoubleStatusResponse
 			if err := json.NewDecoder(statusResp.Body).Decode(&status); err != nil {
 				statusResp.Body.Close()
-				fmt.Printf("\rInvalid JSON response, retrying...")
+				amazonLog.Debug("invalid status response, retrying", "region", region, "error", err)
 				continue
 			}
 			statusResp.Body.Close()
 
 			if status.Status == "done" {
-				fmt.Println("\nDownload ready!")
+				progressf("\n")
+				amazonLog.Info("download ready", "region", region)
 
 				fileURL := status.URL
 				if strings.HasPrefix(fileURL, "./") {
@@ -480,9 +483,9 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 				trackName := status.Current.Name
 				artist := status.Current.Artist
 
-				fmt.Printf("Downloading: %s - %s\n", artist, trackName)
+				amazonLog.Info("downloading", "artist", artist, "title", trackName)
 
-				downloadReq, err := http.NewRequest("GET", fileURL, nil)
+				downloadReq, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
 				if err != nil {
 					lastError = fmt.Errorf("failed to create download request: %w", err)
 					break
@@ -498,7 +501,7 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 				defer fileResp.Body.Close()
 
 				if fileResp.StatusCode != 200 {
-					lastError = fmt.Errorf("download failed with status %d", fileResp.StatusCode)
+					lastError = fmt.Errorf("download failed: %w", statusError("doubledouble", fileResp))
 					break
 				}
 
@@ -517,17 +520,15 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 				}
 				defer out.Close()
 
-				fmt.Println("Downloading...")
-
-				pw := NewProgressWriter(out)
+				pw := NewProgressWriter(ctx, out)
 				_, err = io.Copy(pw, fileResp.Body)
 				if err != nil {
 					out.Close()
+					os.Remove(filePath)
 					return "", fmt.Errorf("failed to write file: %w", err)
 				}
 
-				fmt.Printf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
-				fmt.Println("Download complete!")
+				progressf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
 				return filePath, nil
 
 			} else if status.Status == "error" {
@@ -543,25 +544,25 @@ func (a *AmazonDownloader) DownloadFromService(amazonURL, outputDir, quality str
 				if friendlyStatus == "" {
 					friendlyStatus = status.Status
 				}
-				fmt.Printf("\r%s...", friendlyStatus)
+				progressf("\r%s...", friendlyStatus)
 			}
 		}
 
 		if elapsed >= maxWait {
-			lastError = fmt.Errorf("download timeout")
-			fmt.Printf("\nError with %s region: %v\n", region, lastError)
+			lastError = newServiceError("doubledouble", ErrUpstreamDown, "download timeout", nil)
+			amazonLog.Warn("region failed", "region", region, "error", lastError)
 			continue
 		}
 
 		if lastError != nil {
-			fmt.Printf("\nError with %s region: %v\n", region, lastError)
+			amazonLog.Warn("region failed", "region", region, "error", lastError)
 		}
 	}
 
-	return "", fmt.Errorf("all regions failed. Last error: %v", lastError)
+	return "", newServiceError("doubledouble", ErrUpstreamDown, "all regions failed", lastError)
 }
 
-func (a *AmazonDownloader) DownloadByURL(amazonURL, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, spotifyCoverURL string, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, embedMaxQualityCover bool, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
+func (a *AmazonDownloader) DownloadByURL(ctx context.Context, amazonURL, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, spotifyCoverURL string, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, embedMaxQualityCover bool, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
 
 	if outputDir != "." {
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -571,17 +572,18 @@ func (a *AmazonDownloader) DownloadByURL(amazonURL, outputDir, quality, filename
 
 	if spotifyTrackName != "" && spotifyArtistName != "" {
 		expectedFilename := BuildExpectedFilename(spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, filenameFormat, includeTrackNumber, position, spotifyDiscNumber, false)
-		expectedPath := filepath.Join(outputDir, expectedFilename)
+		expectedPath, exists := resolveOutputPath(filepath.Join(outputDir, expectedFilename), a.ExistingFiles)
 
-		if fileInfo, err := os.Stat(expectedPath); err == nil && fileInfo.Size() > 0 {
-			fmt.Printf("File already exists: %s (%.2f MB)\n", expectedPath, float64(fileInfo.Size())/(1024*1024))
+		if exists {
+			fileInfo, _ := os.Stat(expectedPath)
+			amazonLog.Info("file already exists", "path", expectedPath, "size_mb", float64(fileInfo.Size())/(1024*1024))
 			return "EXISTS:" + expectedPath, nil
 		}
 	}
 
-	fmt.Printf("Using Amazon URL: %s\n", amazonURL)
+	amazonLog.Info("using Amazon URL", "url", amazonURL)
 
-	filePath, err := a.DownloadFromService(amazonURL, outputDir, quality)
+	filePath, err := a.DownloadFromService(ctx, amazonURL, outputDir, quality)
 	if err != nil {
 		return "", err
 	}
@@ -638,29 +640,28 @@ func (a *AmazonDownloader) DownloadByURL(amazonURL, outputDir, quality, filename
 		}
 
 		newFilename = newFilename + ".flac"
-		newFilePath := filepath.Join(outputDir, newFilename)
+		newFilePath, _ := resolveOutputPath(filepath.Join(outputDir, newFilename), a.ExistingFiles)
+		newFilename = filepath.Base(newFilePath)
 
 		if err := os.Rename(filePath, newFilePath); err != nil {
-			fmt.Printf("Warning: Failed to rename file: %v\n", err)
+			amazonLog.Warn("failed to rename file", "error", err)
 		} else {
 			filePath = newFilePath
-			fmt.Printf("Renamed to: %s\n", newFilename)
+			amazonLog.Debug("renamed file", "name", newFilename)
 		}
 	}
 
-	fmt.Println("Embedding Spotify metadata...")
-
 	coverPath := ""
 
 	if spotifyCoverURL != "" {
 		coverPath = filePath + ".cover.jpg"
 		coverClient := NewCoverClient()
-		if err := coverClient.DownloadCoverToPath(spotifyCoverURL, coverPath, embedMaxQualityCover); err != nil {
-			fmt.Printf("Warning: Failed to download Spotify cover: %v\n", err)
+		if err := coverClient.DownloadCoverToPath(ctx, spotifyCoverURL, coverPath, embedMaxQualityCover); err != nil {
+			amazonLog.Warn("failed to download Spotify cover", "error", err)
 			coverPath = ""
 		} else {
 			defer os.Remove(coverPath)
-			fmt.Println("Spotify cover downloaded")
+			amazonLog.Debug("Spotify cover downloaded", "path", coverPath)
 		}
 	}
 
@@ -683,25 +684,29 @@ func (a *AmazonDownloader) DownloadByURL(amazonURL, outputDir, quality, filename
 		Copyright:   spotifyCopyright,
 		Publisher:   spotifyPublisher,
 		Description: "https://github.com/afkarxyz/SpotiFLAC",
+		SpotifyID:   spotifyTrackIDFromURL(spotifyURL),
+
+		SourceService: "amazon",
+		SourceTrackID: amazonTrackASIN(amazonURL),
 	}
+	a.Release.apply(&metadata)
 
 	if err := EmbedMetadata(filePath, metadata, coverPath); err != nil {
-		fmt.Printf("Warning: Failed to embed metadata: %v\n", err)
+		amazonLog.Warn("failed to embed metadata", "path", filePath, "error", err)
 	} else {
-		fmt.Println("Metadata embedded successfully")
+		amazonLog.Debug("metadata embedded", "path", filePath)
 	}
 
-	fmt.Println("Done")
-	fmt.Println("✓ Downloaded successfully from Amazon Music")
+	amazonLog.Info("downloaded", "path", filePath)
 	return filePath, nil
 }
 
-func (a *AmazonDownloader) DownloadBySpotifyID(spotifyTrackID, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, spotifyCoverURL string, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, embedMaxQualityCover bool, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
+func (a *AmazonDownloader) DownloadBySpotifyID(ctx context.Context, spotifyTrackID, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, spotifyCoverURL string, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, embedMaxQualityCover bool, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
 
-	amazonURL, err := a.GetAmazonURLFromSpotify(spotifyTrackID)
+	amazonURL, err := a.GetAmazonURLFromSpotify(ctx, spotifyTrackID)
 	if err != nil {
 		return "", err
 	}
 
-	return a.DownloadByURL(amazonURL, outputDir, quality, filenameFormat, includeTrackNumber, position, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, spotifyCoverURL, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks, embedMaxQualityCover, spotifyTotalDiscs, spotifyCopyright, spotifyPublisher, spotifyURL)
+	return a.DownloadByURL(ctx, amazonURL, outputDir, quality, filenameFormat, includeTrackNumber, position, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, spotifyCoverURL, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks, embedMaxQualityCover, spotifyTotalDiscs, spotifyCopyright, spotifyPublisher, spotifyURL)
 }
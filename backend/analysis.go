@@ -74,7 +74,7 @@ func AnalyzeTrack(filepath string) (*AnalysisResult, error) {
 	spectrum, err := AnalyzeSpectrum(filepath)
 	if err != nil {
 
-		fmt.Printf("Warning: failed to analyze spectrum: %v\n", err)
+		analysisLog.Warn("failed to analyze spectrum", "path", filepath, "error", err)
 	} else {
 		result.Spectrum = spectrum
 
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -59,7 +60,7 @@ type CoverClient struct {
 
 func NewCoverClient() *CoverClient {
 	return &CoverClient{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: newHTTPClient("cover", 30*time.Second),
 	}
 }
 
@@ -135,7 +136,7 @@ func (c *CoverClient) getMaxResolutionURL(imageURL string) string {
 	return mediumURL
 }
 
-func (c *CoverClient) DownloadCoverToPath(coverURL, outputPath string, embedMaxQualityCover bool) error {
+func (c *CoverClient) DownloadCoverToPath(ctx context.Context, coverURL, outputPath string, embedMaxQualityCover bool) error {
 	if coverURL == "" {
 		return fmt.Errorf("cover URL is required")
 	}
@@ -145,7 +146,7 @@ func (c *CoverClient) DownloadCoverToPath(coverURL, outputPath string, embedMaxQ
 		downloadURL = c.getMaxResolutionURL(downloadURL)
 	}
 
-	resp, err := c.httpClient.Get(downloadURL)
+	resp, err := httpGet(ctx, c.httpClient, downloadURL)
 	if err != nil {
 		return fmt.Errorf("failed to download cover: %v", err)
 	}
@@ -163,13 +164,15 @@ func (c *CoverClient) DownloadCoverToPath(coverURL, outputPath string, embedMaxQ
 
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
+		file.Close()
+		os.Remove(outputPath)
 		return fmt.Errorf("failed to write cover file: %v", err)
 	}
 
 	return nil
 }
 
-func (c *CoverClient) DownloadCover(req CoverDownloadRequest) (*CoverDownloadResponse, error) {
+func (c *CoverClient) DownloadCover(ctx context.Context, req CoverDownloadRequest) (*CoverDownloadResponse, error) {
 	if req.CoverURL == "" {
 		return &CoverDownloadResponse{
 			Success: false,
@@ -209,7 +212,7 @@ func (c *CoverClient) DownloadCover(req CoverDownloadRequest) (*CoverDownloadRes
 
 	downloadURL := c.getMaxResolutionURL(req.CoverURL)
 
-	resp, err := c.httpClient.Get(downloadURL)
+	resp, err := httpGet(ctx, c.httpClient, downloadURL)
 	if err != nil {
 		return &CoverDownloadResponse{
 			Success: false,
@@ -236,6 +239,8 @@ func (c *CoverClient) DownloadCover(req CoverDownloadRequest) (*CoverDownloadRes
 
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
+		file.Close()
+		os.Remove(filePath)
 		return &CoverDownloadResponse{
 			Success: false,
 			Error:   fmt.Sprintf("failed to write cover file: %v", err),
@@ -249,7 +254,7 @@ func (c *CoverClient) DownloadCover(req CoverDownloadRequest) (*CoverDownloadRes
 	}, nil
 }
 
-func (c *CoverClient) DownloadHeader(req HeaderDownloadRequest) (*HeaderDownloadResponse, error) {
+func (c *CoverClient) DownloadHeader(ctx context.Context, req HeaderDownloadRequest) (*HeaderDownloadResponse, error) {
 	if req.HeaderURL == "" {
 		return &HeaderDownloadResponse{
 			Success: false,
@@ -291,7 +296,7 @@ func (c *CoverClient) DownloadHeader(req HeaderDownloadRequest) (*HeaderDownload
 		}, nil
 	}
 
-	resp, err := c.httpClient.Get(req.HeaderURL)
+	resp, err := httpGet(ctx, c.httpClient, req.HeaderURL)
 	if err != nil {
 		return &HeaderDownloadResponse{
 			Success: false,
@@ -318,6 +323,8 @@ func (c *CoverClient) DownloadHeader(req HeaderDownloadRequest) (*HeaderDownload
 
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
+		file.Close()
+		os.Remove(filePath)
 		return &HeaderDownloadResponse{
 			Success: false,
 			Error:   fmt.Sprintf("failed to write header file: %v", err),
@@ -346,7 +353,7 @@ type GalleryImageDownloadResponse struct {
 	AlreadyExists bool   `json:"already_exists,omitempty"`
 }
 
-func (c *CoverClient) DownloadGalleryImage(req GalleryImageDownloadRequest) (*GalleryImageDownloadResponse, error) {
+func (c *CoverClient) DownloadGalleryImage(ctx context.Context, req GalleryImageDownloadRequest) (*GalleryImageDownloadResponse, error) {
 	if req.ImageURL == "" {
 		return &GalleryImageDownloadResponse{
 			Success: false,
@@ -388,7 +395,7 @@ func (c *CoverClient) DownloadGalleryImage(req GalleryImageDownloadRequest) (*Ga
 		}, nil
 	}
 
-	resp, err := c.httpClient.Get(req.ImageURL)
+	resp, err := httpGet(ctx, c.httpClient, req.ImageURL)
 	if err != nil {
 		return &GalleryImageDownloadResponse{
 			Success: false,
@@ -415,6 +422,8 @@ func (c *CoverClient) DownloadGalleryImage(req GalleryImageDownloadRequest) (*Ga
 
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
+		file.Close()
+		os.Remove(filePath)
 		return &GalleryImageDownloadResponse{
 			Success: false,
 			Error:   fmt.Sprintf("failed to write gallery image file: %v", err),
@@ -442,7 +451,7 @@ type AvatarDownloadResponse struct {
 	AlreadyExists bool   `json:"already_exists,omitempty"`
 }
 
-func (c *CoverClient) DownloadAvatar(req AvatarDownloadRequest) (*AvatarDownloadResponse, error) {
+func (c *CoverClient) DownloadAvatar(ctx context.Context, req AvatarDownloadRequest) (*AvatarDownloadResponse, error) {
 	if req.AvatarURL == "" {
 		return &AvatarDownloadResponse{
 			Success: false,
@@ -484,7 +493,7 @@ func (c *CoverClient) DownloadAvatar(req AvatarDownloadRequest) (*AvatarDownload
 		}, nil
 	}
 
-	resp, err := c.httpClient.Get(req.AvatarURL)
+	resp, err := httpGet(ctx, c.httpClient, req.AvatarURL)
 	if err != nil {
 		return &AvatarDownloadResponse{
 			Success: false,
@@ -511,6 +520,8 @@ func (c *CoverClient) DownloadAvatar(req AvatarDownloadRequest) (*AvatarDownload
 
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
+		file.Close()
+		os.Remove(filePath)
 		return &AvatarDownloadResponse{
 			Success: false,
 			Error:   fmt.Sprintf("failed to write avatar file: %v", err),
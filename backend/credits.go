@@ -0,0 +1,234 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Credits are the songwriter, producer and performer credits of a track
+type Credits struct {
+	Composers  []string
+	Lyricists  []string
+	Writers    []string
+	Producers  []string
+	Engineers  []string
+	Mixers     []string
+	Performers []Performer
+}
+
+// Performer is a musician and what they play or sing. Role is empty when
+// Spotify only lists them as a performer.
+type Performer struct {
+	Name string
+	Role string
+}
+
+func (p Performer) String() string {
+	if p.Role == "" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, p.Role)
+}
+
+func (c Credits) IsEmpty() bool {
+	return len(c.Composers)+len(c.Lyricists)+len(c.Writers)+len(c.Producers)+
+		len(c.Engineers)+len(c.Mixers)+len(c.Performers) == 0
+}
+
+// fields returns the credits under their Vorbis comment names, which are
+// also used for MP4 freeform atoms
+func (c Credits) fields() []tagField {
+	var fields []tagField
+	addAll := func(name string, values []string) {
+		for _, value := range values {
+			fields = append(fields, tagField{name, value})
+		}
+	}
+	addAll("COMPOSER", c.Composers)
+	addAll("LYRICIST", c.Lyricists)
+	addAll("WRITER", c.Writers)
+	addAll("PRODUCER", c.Producers)
+	addAll("ENGINEER", c.Engineers)
+	addAll("MIXER", c.Mixers)
+	for _, performer := range c.Performers {
+		fields = append(fields, tagField{"PERFORMER", performer.String()})
+	}
+	return fields
+}
+
+// involvedPeople returns the ID3v2 TIPL role/name pairs
+func (c Credits) involvedPeople() []string {
+	var pairs []string
+	for _, group := range []struct {
+		role  string
+		names []string
+	}{{"producer", c.Producers}, {"engineer", c.Engineers}, {"mix", c.Mixers}} {
+		for _, name := range group.names {
+			pairs = append(pairs, group.role, name)
+		}
+	}
+	return pairs
+}
+
+// musicians returns the ID3v2 TMCL instrument/name pairs
+func (c Credits) musicians() []string {
+	var pairs []string
+	for _, performer := range c.Performers {
+		role := performer.Role
+		if role == "" {
+			role = "performer"
+		}
+		pairs = append(pairs, role, performer.Name)
+	}
+	return pairs
+}
+
+// parsePerformer reverses Performer.String
+func parsePerformer(value string) Performer {
+	if name, role, ok := strings.Cut(value, " ("); ok && strings.HasSuffix(role, ")") {
+		return Performer{Name: name, Role: strings.TrimSuffix(role, ")")}
+	}
+	return Performer{Name: value}
+}
+
+type spotifyCreditsResponse struct {
+	RoleCredits []struct {
+		RoleTitle string `json:"roleTitle"`
+		Artists   []struct {
+			Name     string   `json:"name"`
+			Subroles []string `json:"subroles"`
+		} `json:"artists"`
+	} `json:"roleCredits"`
+}
+
+// GetTrackCredits fetches a track's credits from the web player's credits
+// view, the same data its "Show credits" dialog displays
+func (c *SpotifyClient) GetTrackCredits(ctx context.Context, trackID string) (Credits, error) {
+	if c.accessToken == "" || c.clientToken == "" {
+		if err := c.Initialize(ctx); err != nil {
+			return Credits{}, err
+		}
+	}
+
+	url := fmt.Sprintf("https://spclient.wg.spotify.com/track-credits-view/v0/experimental/%s/credits", trackID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return Credits{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Client-Token", c.clientToken)
+	req.Header.Set("App-Platform", "WebPlayer")
+	req.Header.Set("Spotify-App-Version", c.clientVersion)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Credits{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return Credits{}, fmt.Errorf("%w: credits request failed: %w", SpotifyError, statusError("spotify", resp))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Credits{}, err
+	}
+	var raw spotifyCreditsResponse
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return Credits{}, err
+	}
+	return raw.credits(), nil
+}
+
+// FetchTrackCredits returns the credits of a Spotify track
+func FetchTrackCredits(ctx context.Context, trackID string) (Credits, error) {
+	return NewSpotifyClient().GetTrackCredits(ctx, trackID)
+}
+
+// credits sorts each credited name into a field by its subroles, falling
+// back to the section it is listed under
+func (r spotifyCreditsResponse) credits() Credits {
+	var credits Credits
+	add := func(list *[]string, name string) {
+		if !slices.Contains(*list, name) {
+			*list = append(*list, name)
+		}
+	}
+
+	for _, section := range r.RoleCredits {
+		title := strings.ToLower(section.RoleTitle)
+		for _, artist := range section.Artists {
+			name := strings.TrimSpace(artist.Name)
+			if name == "" {
+				continue
+			}
+			subroles := artist.Subroles
+			if len(subroles) == 0 {
+				subroles = []string{""}
+			}
+
+			var roles []string
+			credited := false
+			for _, subrole := range subroles {
+				role := strings.ToLower(strings.TrimSpace(subrole))
+				if list := credits.listFor(role); list != nil {
+					add(list, name)
+					credited = true
+					continue
+				}
+				switch {
+				case strings.Contains(title, "perform"):
+					// "Main Artist" and the like say nothing an ARTIST tag doesn't
+					if role != "" && !strings.Contains(role, "artist") {
+						roles = append(roles, role)
+					}
+				case strings.Contains(title, "writ"):
+					add(&credits.Writers, name)
+				case strings.Contains(title, "produc"):
+					add(&credits.Producers, name)
+				}
+			}
+
+			if strings.Contains(title, "perform") && (len(roles) > 0 || !credited) {
+				if len(roles) == 0 {
+					roles = []string{""}
+				}
+				for _, role := range roles {
+					performer := Performer{Name: name, Role: role}
+					if !slices.Contains(credits.Performers, performer) {
+						credits.Performers = append(credits.Performers, performer)
+					}
+				}
+			}
+		}
+	}
+	return credits
+}
+
+// listFor returns the field a credit subrole belongs in, or nil when the
+// subrole is an instrument or doesn't say
+func (c *Credits) listFor(role string) *[]string {
+	switch {
+	case strings.Contains(role, "lyric"):
+		return &c.Lyricists
+	case strings.Contains(role, "compos"):
+		return &c.Composers
+	case strings.Contains(role, "writ"):
+		return &c.Writers
+	case strings.Contains(role, "mix"):
+		return &c.Mixers
+	case strings.Contains(role, "engineer"), strings.Contains(role, "master"):
+		return &c.Engineers
+	case strings.Contains(role, "produc"):
+		return &c.Producers
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Error kinds shared by all services. Match them with errors.Is; use
+// errors.As with *ServiceError for the service and HTTP status.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrAuth               = errors.New("authentication failed")
+	ErrUpstreamDown       = errors.New("upstream unavailable")
+	ErrQualityUnavailable = errors.New("quality unavailable")
+	ErrCorruptOutput      = errors.New("corrupt output")
+	ErrConversionFailed   = errors.New("conversion failed")
+)
+
+type ServiceError struct {
+	Kind       error
+	Service    string
+	StatusCode int
+	RetryAfter time.Duration
+	Message    string
+	Err        error
+}
+
+func (e *ServiceError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Kind != nil {
+		msg = e.Kind.Error()
+	}
+	if e.StatusCode != 0 {
+		msg = fmt.Sprintf("%s (HTTP %d)", msg, e.StatusCode)
+	}
+	if e.Service != "" {
+		msg = e.Service + ": " + msg
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *ServiceError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+func newServiceError(service string, kind error, message string, cause error) *ServiceError {
+	return &ServiceError{Kind: kind, Service: service, Message: message, Err: cause}
+}
+
+// responseError returns err, or a *ServiceError if resp isn't a 200
+func responseError(service string, resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(service, resp)
+	}
+	return nil
+}
+
+// statusError classifies a non-200 response. The body is not read or closed.
+func statusError(service string, resp *http.Response) *ServiceError {
+	e := &ServiceError{
+		Service:    service,
+		StatusCode: resp.StatusCode,
+		Message:    "unexpected response",
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		e.Kind = ErrNotFound
+		e.Message = ""
+	case resp.StatusCode == http.StatusTooManyRequests:
+		e.Kind = ErrRateLimited
+		e.Message = ""
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			e.RetryAfter = time.Duration(secs) * time.Second
+		}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		e.Kind = ErrAuth
+		e.Message = ""
+	case resp.StatusCode >= 500:
+		e.Kind = ErrUpstreamDown
+		e.Message = ""
+	}
+	return e
+}
+
+// retriesExhausted wraps an error a RetryPolicy already gave up on, so a
+// retry around the caller doesn't repeat every attempt again
+type retriesExhausted struct {
+	err error
+}
+
+func (e *retriesExhausted) Error() string { return e.err.Error() }
+func (e *retriesExhausted) Unwrap() error { return e.err }
+
+// IsTransient reports whether retrying the same operation may succeed.
+// Errors that were already retried are not transient any more.
+func IsTransient(err error) bool {
+	var exhausted *retriesExhausted
+	if errors.As(err, &exhausted) {
+		return false
+	}
+	return isTransientKind(err)
+}
+
+func isTransientKind(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstreamDown) || errors.Is(err, ErrCorruptOutput) {
+		return true
+	}
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrAuth) || errors.Is(err, ErrQualityUnavailable) || errors.Is(err, ErrConversionFailed) {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ErrorKind returns a short label for err, used in reports and summaries.
+func ErrorKind(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled):
+		return "interrupted"
+	case errors.Is(err, ErrNotFound):
+		return "not-found"
+	case errors.Is(err, ErrRateLimited):
+		return "rate-limited"
+	case errors.Is(err, ErrAuth):
+		return "auth"
+	case errors.Is(err, ErrUpstreamDown):
+		return "upstream-down"
+	case errors.Is(err, ErrQualityUnavailable):
+		return "quality-unavailable"
+	case errors.Is(err, ErrCorruptOutput):
+		return "corrupt-output"
+	case errors.Is(err, ErrConversionFailed):
+		return "conversion-failed"
+	case isTransientKind(err):
+		return "network"
+	default:
+		return "error"
+	}
+}
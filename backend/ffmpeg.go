@@ -3,6 +3,7 @@ package backend
 import (
 	"archive/tar"
 	"archive/zip"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -169,27 +170,27 @@ func DownloadFFmpeg(progressCallback func(int)) error {
 		if !ffmpegInstalled && !ffprobeInstalled {
 
 			ffmpegURL, _ := decodeBase64(ffmpegMacOSURL)
-			fmt.Printf("[FFmpeg] Downloading ffmpeg from: %s\n", ffmpegURL)
+			ffmpegLog.Info("downloading ffmpeg", "url", ffmpegURL)
 			if err := downloadAndExtract(ffmpegURL, ffmpegDir, progressCallback, 0, 50); err != nil {
 				return err
 			}
 
 			ffprobeURL, _ := decodeBase64(ffprobeMacOSURL)
-			fmt.Printf("[FFmpeg] Downloading ffprobe from: %s\n", ffprobeURL)
+			ffmpegLog.Info("downloading ffprobe", "url", ffprobeURL)
 			if err := downloadAndExtract(ffprobeURL, ffmpegDir, progressCallback, 50, 100); err != nil {
 				return fmt.Errorf("failed to download ffprobe: %w", err)
 			}
 		} else if !ffmpegInstalled {
 
 			ffmpegURL, _ := decodeBase64(ffmpegMacOSURL)
-			fmt.Printf("[FFmpeg] Downloading ffmpeg from: %s\n", ffmpegURL)
+			ffmpegLog.Info("downloading ffmpeg", "url", ffmpegURL)
 			if err := downloadAndExtract(ffmpegURL, ffmpegDir, progressCallback, 0, 100); err != nil {
 				return err
 			}
 		} else if !ffprobeInstalled {
 
 			ffprobeURL, _ := decodeBase64(ffprobeMacOSURL)
-			fmt.Printf("[FFmpeg] Downloading ffprobe from: %s\n", ffprobeURL)
+			ffmpegLog.Info("downloading ffprobe", "url", ffprobeURL)
 			if err := downloadAndExtract(ffprobeURL, ffmpegDir, progressCallback, 0, 100); err != nil {
 				return fmt.Errorf("failed to download ffprobe: %w", err)
 			}
@@ -212,7 +213,7 @@ func DownloadFFmpeg(progressCallback func(int)) error {
 		return fmt.Errorf("failed to decode ffmpeg URL: %w", err)
 	}
 
-	fmt.Printf("[FFmpeg] Downloading from: %s\n", url)
+	ffmpegLog.Info("downloading ffmpeg", "url", url)
 
 	if err := downloadAndExtract(url, ffmpegDir, progressCallback, 0, 100); err != nil {
 		return err
@@ -230,7 +231,7 @@ func downloadAndExtract(url, destDir string, progressCallback func(int), progres
 	defer os.Remove(tmpFile.Name())
 	defer tmpFile.Close()
 
-	resp, err := http.Get(url)
+	resp, err := newHTTPClient("ffmpeg", 0).Get(url)
 	if err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
@@ -247,9 +248,7 @@ func downloadAndExtract(url, destDir string, progressCallback func(int), progres
 
 	if totalSize > 0 {
 		totalSizeMB := float64(totalSize) / (1024 * 1024)
-		fmt.Printf("[FFmpeg] Total size: %.2f MB\n", totalSizeMB)
-	} else {
-		fmt.Printf("[FFmpeg] Downloading... (size unknown)\n")
+		ffmpegLog.Info("download size", "size_mb", totalSizeMB)
 	}
 
 	buf := make([]byte, 32*1024)
@@ -288,17 +287,17 @@ func downloadAndExtract(url, destDir string, progressCallback func(int), progres
 			if totalSize > 0 {
 				percent := float64(downloaded) * 100 / float64(totalSize)
 				if speedMBps > 0 {
-					fmt.Printf("\r[FFmpeg] Downloading: %.2f MB / %.2f MB (%.1f%%) - %.2f MB/s",
+					progressf("\r[FFmpeg] Downloading: %.2f MB / %.2f MB (%.1f%%) - %.2f MB/s",
 						mbDownloaded, float64(totalSize)/(1024*1024), percent, speedMBps)
 				} else {
-					fmt.Printf("\r[FFmpeg] Downloading: %.2f MB / %.2f MB (%.1f%%)",
+					progressf("\r[FFmpeg] Downloading: %.2f MB / %.2f MB (%.1f%%)",
 						mbDownloaded, float64(totalSize)/(1024*1024), percent)
 				}
 			} else {
 				if speedMBps > 0 {
-					fmt.Printf("\r[FFmpeg] Downloading: %.2f MB - %.2f MB/s", mbDownloaded, speedMBps)
+					progressf("\r[FFmpeg] Downloading: %.2f MB - %.2f MB/s", mbDownloaded, speedMBps)
 				} else {
-					fmt.Printf("\r[FFmpeg] Downloading: %.2f MB", mbDownloaded)
+					progressf("\r[FFmpeg] Downloading: %.2f MB", mbDownloaded)
 				}
 			}
 		}
@@ -313,12 +312,12 @@ func downloadAndExtract(url, destDir string, progressCallback func(int), progres
 	tmpFile.Close()
 
 	if totalSize > 0 {
-		fmt.Printf("\r[FFmpeg] Download complete: %.2f MB / %.2f MB (100%%)          \n",
+		progressf("\r[FFmpeg] Download complete: %.2f MB / %.2f MB (100%%)          \n",
 			float64(downloaded)/(1024*1024), float64(totalSize)/(1024*1024))
 	} else {
-		fmt.Printf("\r[FFmpeg] Download complete: %.2f MB          \n", float64(downloaded)/(1024*1024))
+		progressf("\r[FFmpeg] Download complete: %.2f MB          \n", float64(downloaded)/(1024*1024))
 	}
-	fmt.Printf("[FFmpeg] Extracting...\n")
+	ffmpegLog.Info("extracting archive", "path", tmpFile.Name())
 
 	if strings.HasSuffix(url, ".tar.xz") || runtime.GOOS == "linux" {
 		return extractTarXz(tmpFile.Name(), destDir)
@@ -361,7 +360,7 @@ func extractZip(zipPath, destDir string) error {
 			continue
 		}
 
-		fmt.Printf("[FFmpeg] Found: %s\n", f.Name)
+		ffmpegLog.Debug("found archive entry", "name", f.Name)
 
 		rc, err := f.Open()
 		if err != nil {
@@ -382,7 +381,7 @@ func extractZip(zipPath, destDir string) error {
 			return fmt.Errorf("failed to extract file: %w", err)
 		}
 
-		fmt.Printf("[FFmpeg] Extracted to: %s\n", destPath)
+		ffmpegLog.Debug("extracted", "path", destPath)
 	}
 
 	if !foundFFmpeg && !foundFFprobe {
@@ -390,10 +389,10 @@ func extractZip(zipPath, destDir string) error {
 	}
 
 	if foundFFmpeg {
-		fmt.Printf("[FFmpeg] ffmpeg extracted successfully\n")
+		ffmpegLog.Info("ffmpeg extracted", "dir", destDir)
 	}
 	if foundFFprobe {
-		fmt.Printf("[FFmpeg] ffprobe extracted successfully\n")
+		ffmpegLog.Info("ffprobe extracted", "dir", destDir)
 	}
 
 	return nil
@@ -445,7 +444,7 @@ func extractTarXz(tarXzPath, destDir string) error {
 			continue
 		}
 
-		fmt.Printf("[FFmpeg] Found: %s\n", header.Name)
+		ffmpegLog.Debug("found archive entry", "name", header.Name)
 
 		outFile, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
 		if err != nil {
@@ -459,7 +458,7 @@ func extractTarXz(tarXzPath, destDir string) error {
 			return fmt.Errorf("failed to extract file: %w", err)
 		}
 
-		fmt.Printf("[FFmpeg] Extracted to: %s\n", destPath)
+		ffmpegLog.Debug("extracted", "path", destPath)
 	}
 
 	if !foundFFmpeg && !foundFFprobe {
@@ -467,10 +466,10 @@ func extractTarXz(tarXzPath, destDir string) error {
 	}
 
 	if foundFFmpeg {
-		fmt.Printf("[FFmpeg] ffmpeg extracted successfully\n")
+		ffmpegLog.Info("ffmpeg extracted", "dir", destDir)
 	}
 	if foundFFprobe {
-		fmt.Printf("[FFmpeg] ffprobe extracted successfully\n")
+		ffmpegLog.Info("ffprobe extracted", "dir", destDir)
 	}
 
 	return nil
@@ -490,7 +489,7 @@ type ConvertAudioResult struct {
 	Error      string `json:"error,omitempty"`
 }
 
-func ConvertAudio(req ConvertAudioRequest) ([]ConvertAudioResult, error) {
+func ConvertAudio(ctx context.Context, req ConvertAudioRequest) ([]ConvertAudioResult, error) {
 	ffmpegPath, err := GetFFmpegPath()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ffmpeg path: %w", err)
@@ -554,17 +553,17 @@ func ConvertAudio(req ConvertAudioRequest) ([]ConvertAudioResult, error) {
 
 			inputMetadata, err = ExtractFullMetadataFromFile(inputFile)
 			if err != nil {
-				fmt.Printf("[FFmpeg] Warning: Failed to extract metadata from %s: %v\n", inputFile, err)
+				ffmpegLog.Warn("failed to extract metadata", "path", inputFile, "error", err)
 			}
 
 			coverArtPath, _ = ExtractCoverArt(inputFile)
 			lyrics, err = ExtractLyrics(inputFile)
 			if err != nil {
-				fmt.Printf("[FFmpeg] Warning: Failed to extract lyrics from %s: %v\n", inputFile, err)
+				ffmpegLog.Warn("failed to extract lyrics", "path", inputFile, "error", err)
 			} else if lyrics != "" {
-				fmt.Printf("[FFmpeg] Lyrics extracted from %s: %d characters\n", inputFile, len(lyrics))
+				ffmpegLog.Debug("extracted lyrics", "path", inputFile, "chars", len(lyrics))
 			} else {
-				fmt.Printf("[FFmpeg] No lyrics found in %s\n", inputFile)
+				ffmpegLog.Debug("no lyrics found", "path", inputFile)
 			}
 
 			inputMetadata.Lyrics = lyrics
@@ -605,15 +604,29 @@ func ConvertAudio(req ConvertAudioRequest) ([]ConvertAudioResult, error) {
 				}
 			}
 
+			// ffmpeg copies the source's REPLAYGAIN_* tags, but Opus players
+			// only apply R128_* gains
+			if req.OutputFormat == "opus" && inputMetadata.ReplayGain != nil {
+				for _, field := range inputMetadata.ReplayGain.fields() {
+					args = append(args, "-metadata", field.name+"=")
+				}
+				for _, field := range inputMetadata.ReplayGain.r128Fields() {
+					args = append(args, "-metadata", field.name+"="+field.value)
+				}
+			}
+
 			args = append(args, outputFile)
 
-			fmt.Printf("[FFmpeg] Converting: %s -> %s\n", inputFile, outputFile)
+			ffmpegLog.Info("converting", "input", inputFile, "output", outputFile)
 
-			cmd := exec.Command(ffmpegPath, args...)
+			cmd := exec.CommandContext(ctx, ffmpegPath, args...)
 
 			setHideWindow(cmd)
+			start := time.Now()
 			output, err := cmd.CombinedOutput()
+			observeFFmpeg("convert", start, err)
 			if err != nil {
+				os.Remove(outputFile)
 				result.Error = fmt.Sprintf("conversion failed: %s - %s", err.Error(), string(output))
 				result.Success = false
 				mu.Lock()
@@ -627,16 +640,16 @@ func ConvertAudio(req ConvertAudioRequest) ([]ConvertAudioResult, error) {
 			}
 
 			if err := EmbedMetadataToConvertedFile(outputFile, inputMetadata, coverArtPath); err != nil {
-				fmt.Printf("[FFmpeg] Warning: Failed to embed metadata: %v\n", err)
+				ffmpegLog.Warn("failed to embed metadata", "path", outputFile, "error", err)
 			} else {
-				fmt.Printf("[FFmpeg] Metadata embedded successfully\n")
+				ffmpegLog.Debug("metadata embedded", "path", outputFile)
 			}
 
 			if lyrics != "" {
 				if err := EmbedLyricsOnlyUniversal(outputFile, lyrics); err != nil {
-					fmt.Printf("[FFmpeg] Warning: Failed to embed lyrics: %v\n", err)
+					ffmpegLog.Warn("failed to embed lyrics", "path", outputFile, "error", err)
 				} else {
-					fmt.Printf("[FFmpeg] Lyrics embedded successfully\n")
+					ffmpegLog.Debug("lyrics embedded", "path", outputFile)
 				}
 			}
 
@@ -645,7 +658,7 @@ func ConvertAudio(req ConvertAudioRequest) ([]ConvertAudioResult, error) {
 			}
 
 			result.Success = true
-			fmt.Printf("[FFmpeg] Successfully converted: %s\n", outputFile)
+			ffmpegLog.Info("converted", "path", outputFile)
 
 			mu.Lock()
 			results[idx] = result
@@ -3,6 +3,7 @@ package backend
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -30,6 +31,8 @@ type AudioMetadata struct {
 	TrackNumber int    `json:"track_number"`
 	DiscNumber  int    `json:"disc_number"`
 	Year        string `json:"year"`
+	ISRC        string `json:"isrc,omitempty"`
+	SpotifyID   string `json:"spotify_id,omitempty"`
 }
 
 type RenamePreview struct {
@@ -81,6 +84,23 @@ func ListDirectory(dirPath string) ([]FileInfo, error) {
 	return result, nil
 }
 
+// IsWithinDir reports whether path is inside dir or one of its subfolders
+func IsWithinDir(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil || rel == "." || filepath.IsAbs(rel) {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func ListAudioFiles(dirPath string) ([]FileInfo, error) {
 	var result []FileInfo
 
@@ -113,6 +133,26 @@ func ListAudioFiles(dirPath string) ([]FileInfo, error) {
 	return result, nil
 }
 
+// VerifyAudioFile checks that a finished download is non-empty and, for FLAC,
+// starts with the fLaC stream marker. Failures wrap ErrCorruptOutput.
+func VerifyAudioFile(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return newServiceError("", ErrCorruptOutput, "output file missing", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return newServiceError("", ErrCorruptOutput, "output file is empty or truncated", nil)
+	}
+
+	if strings.ToLower(filepath.Ext(filePath)) == ".flac" && string(header) != "fLaC" && string(header[:3]) != "ID3" {
+		return newServiceError("", ErrCorruptOutput, "output is not a FLAC file", nil)
+	}
+	return nil
+}
+
 func ReadAudioMetadata(filePath string) (*AudioMetadata, error) {
 	if !fileExists(filePath) {
 		return nil, fmt.Errorf("file does not exist")
@@ -175,6 +215,10 @@ func readFlacMetadata(filePath string) (*AudioMetadata, error) {
 					}
 				case "DATE", "YEAR":
 					metadata.Year = value
+				case "ISRC":
+					metadata.ISRC = value
+				case "SPOTIFY_TRACK_ID":
+					metadata.SpotifyID = value
 				}
 			}
 		}
@@ -221,6 +265,18 @@ func readMp3Metadata(filePath string) (*AudioMetadata, error) {
 		}
 	}
 
+	if frames := tag.GetFrames("TSRC"); len(frames) > 0 {
+		if textFrame, ok := frames[0].(id3v2.TextFrame); ok {
+			metadata.ISRC = textFrame.Text
+		}
+	}
+
+	for _, frame := range tag.GetFrames("TXXX") {
+		if udtf, ok := frame.(id3v2.UserDefinedTextFrame); ok && strings.EqualFold(udtf.Description, "SPOTIFY_TRACK_ID") {
+			metadata.SpotifyID = udtf.Value
+		}
+	}
+
 	return metadata, nil
 }
 
@@ -301,6 +357,10 @@ func readMetadataWithFFprobe(filePath string) (*AudioMetadata, error) {
 			if metadata.Year == "" || len(value) > len(metadata.Year) {
 				metadata.Year = value
 			}
+		case "isrc":
+			metadata.ISRC = value
+		case "spotify_track_id":
+			metadata.SpotifyID = value
 		}
 	}
 
@@ -0,0 +1,27 @@
+package backend
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsWithinDir(t *testing.T) {
+	dir := filepath.Join("music", "Playlist")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(dir, "song.flac"), true},
+		{filepath.Join(dir, "Album", "song.flac"), true},
+		{dir, false},
+		{filepath.Join("music", "Other", "song.flac"), false},
+		{filepath.Join(dir, "..", "song.flac"), false},
+		{filepath.Join("music", "Playlist2", "song.flac"), false},
+		{filepath.Join(dir, "..flac"), true},
+	}
+	for _, tt := range tests {
+		if got := IsWithinDir(dir, tt.path); got != tt.want {
+			t.Errorf("IsWithinDir(%q, %q) = %v, want %v", dir, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	genreBucket   = "ArtistGenres"
+	genreCacheTTL = 30 * 24 * time.Hour
+)
+
+// GenreRule maps every Spotify genre containing one of its Match terms to
+// Genre. Terms match whole words, so "rap" matches "southern rap" but not
+// "trap".
+type GenreRule struct {
+	Genre string   `json:"genre"`
+	Match []string `json:"match"`
+}
+
+// GenreMapping turns Spotify's micro-genres into a smaller vocabulary. Rules
+// are tried in order and the first match wins, so specific rules go first.
+type GenreMapping struct {
+	KeepUnmapped bool        `json:"keep_unmapped"`
+	Rules        []GenreRule `json:"genres"`
+}
+
+var defaultGenreMapping = GenreMapping{
+	Rules: []GenreRule{
+		{Genre: "K-Pop", Match: []string{"k pop", "korean pop"}},
+		{Genre: "J-Pop", Match: []string{"j pop", "japanese pop", "city pop", "anime"}},
+		{Genre: "Hip-Hop", Match: []string{"hip hop", "rap", "trap", "drill", "grime", "boom bap", "phonk"}},
+		{Genre: "R&B", Match: []string{"r&b", "rnb", "soul", "new jack swing", "motown"}},
+		{Genre: "Latin", Match: []string{"latin", "reggaeton", "urbano", "salsa", "bachata", "cumbia", "corrido", "corridos", "mariachi", "sertanejo", "banda"}},
+		{Genre: "Metal", Match: []string{"metal", "metalcore", "deathcore", "djent", "grindcore"}},
+		{Genre: "Punk", Match: []string{"punk", "emo", "screamo"}},
+		{Genre: "Country", Match: []string{"country", "americana", "bluegrass", "honky tonk"}},
+		{Genre: "Jazz", Match: []string{"jazz", "bebop", "swing", "bossa nova"}},
+		{Genre: "Blues", Match: []string{"blues"}},
+		{Genre: "Classical", Match: []string{"classical", "orchestra", "orchestral", "baroque", "opera", "romantic era", "choral"}},
+		{Genre: "Reggae", Match: []string{"reggae", "dancehall", "dub", "ska"}},
+		{Genre: "Soundtrack", Match: []string{"soundtrack", "score", "video game music", "show tunes", "broadway"}},
+		{Genre: "Electronic", Match: []string{"edm", "electronic", "electronica", "electro", "house", "techno", "trance", "dubstep", "drum and bass", "dnb", "uk garage", "ambient", "idm", "synthwave", "downtempo", "breakbeat", "hardstyle", "big room", "lo fi", "chillwave", "future bass"}},
+		{Genre: "Funk", Match: []string{"funk", "disco", "boogie"}},
+		{Genre: "Folk", Match: []string{"folk", "singer songwriter"}},
+		{Genre: "Gospel", Match: []string{"gospel", "worship", "christian", "ccm"}},
+		{Genre: "Rock", Match: []string{"rock", "grunge", "shoegaze", "post rock", "alternative", "new wave"}},
+		{Genre: "Pop", Match: []string{"pop", "boy band", "girl group", "idol"}},
+	},
+}
+
+var (
+	genreLimit       = 0
+	genreMapPath     string
+	genreMapping     *GenreMapping
+	genreSettingLock sync.RWMutex
+
+	// genreLookupFailed holds artists whose lookup failed this run, so a
+	// batch doesn't repeat a failing request for every track
+	genreLookupFailed sync.Map
+)
+
+// SetGenreSettings sets how many GENRE values are written, 0 (the default)
+// to skip the lookup entirely, and the mapping file used. An empty path uses genres.json
+// in the app directory.
+func SetGenreSettings(limit int, mapPath string) {
+	genreSettingLock.Lock()
+	defer genreSettingLock.Unlock()
+	if mapPath != genreMapPath {
+		genreMapping = nil
+	}
+	genreLimit = limit
+	genreMapPath = mapPath
+}
+
+// DefaultGenreMapPath returns where the genre mapping lives when no path is
+// configured
+func DefaultGenreMapPath() (string, error) {
+	dir, err := GetFFmpegDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "genres.json"), nil
+}
+
+// LoadGenreMapping reads a mapping file. A missing file is created with the
+// built-in mapping so there is something to edit.
+func LoadGenreMapping(path string) (*GenreMapping, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultGenreMapPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		mapping := defaultGenreMapping
+		if buf, err := json.MarshalIndent(mapping, "", "  "); err == nil {
+			os.MkdirAll(filepath.Dir(path), 0755)
+			if err := os.WriteFile(path, buf, 0644); err != nil {
+				metadataLog.Debug("could not write default genre mapping", "path", path, "error", err)
+			}
+		}
+		return &mapping, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping GenreMapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("invalid genre mapping %s: %w", path, err)
+	}
+	return &mapping, nil
+}
+
+// Map converts Spotify genres, most relevant first, into at most limit
+// mapped genres without duplicates
+func (m *GenreMapping) Map(genres []string, limit int) []string {
+	var mapped []string
+	seen := make(map[string]bool)
+	for _, genre := range genres {
+		words := " " + normalizeGenre(genre) + " "
+		result := ""
+	rules:
+		for _, rule := range m.Rules {
+			for _, term := range rule.Match {
+				if strings.Contains(words, " "+normalizeGenre(term)+" ") {
+					result = rule.Genre
+					break rules
+				}
+			}
+		}
+		if result == "" && m.KeepUnmapped {
+			result = titleCaseGenre(genre)
+		}
+		if result == "" || seen[strings.ToLower(result)] {
+			continue
+		}
+		seen[strings.ToLower(result)] = true
+		mapped = append(mapped, result)
+		if limit > 0 && len(mapped) == limit {
+			break
+		}
+	}
+	return mapped
+}
+
+func normalizeGenre(genre string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(genre), "-", " ")), " ")
+}
+
+func titleCaseGenre(genre string) string {
+	words := strings.Fields(genre)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// GenresForArtist returns the mapped genres to tag a track by artistID with.
+// Failures are logged and give no genres, a missing GENRE tag is not worth
+// failing a download over.
+func GenresForArtist(ctx context.Context, artistID, appName string) []string {
+	genreSettingLock.RLock()
+	limit, path, mapping := genreLimit, genreMapPath, genreMapping
+	genreSettingLock.RUnlock()
+	if limit <= 0 || artistID == "" {
+		return nil
+	}
+	if _, failed := genreLookupFailed.Load(artistID); failed {
+		return nil
+	}
+
+	if mapping == nil {
+		loaded, err := LoadGenreMapping(path)
+		if err != nil {
+			metadataLog.Warn("genre mapping unavailable", "error", err)
+			return nil
+		}
+		genreSettingLock.Lock()
+		if genreMapPath == path {
+			genreMapping = loaded
+		}
+		genreSettingLock.Unlock()
+		mapping = loaded
+	}
+
+	genres, err := LookupArtistGenres(ctx, artistID, appName)
+	if err != nil {
+		if ctx.Err() == nil {
+			genreLookupFailed.Store(artistID, struct{}{})
+		}
+		metadataLog.Warn("artist genre lookup failed", "artist_id", artistID, "error", err)
+		return nil
+	}
+	return mapping.Map(genres, limit)
+}
+
+type cachedArtistGenres struct {
+	Genres    []string `json:"genres"`
+	FetchedAt int64    `json:"fetched_at"`
+}
+
+// LookupArtistGenres returns Spotify's genres for an artist, cached in the
+// history database for 30 days
+func LookupArtistGenres(ctx context.Context, artistID, appName string) ([]string, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return nil, err
+		}
+	}
+
+	var cached *cachedArtistGenres
+	historyDB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(genreBucket))
+		if b == nil {
+			return nil
+		}
+		if v := b.Get([]byte(artistID)); v != nil {
+			var entry cachedArtistGenres
+			if err := json.Unmarshal(v, &entry); err == nil {
+				cached = &entry
+			}
+		}
+		return nil
+	})
+	if cached != nil && time.Since(time.Unix(cached.FetchedAt, 0)) < genreCacheTTL {
+		return cached.Genres, nil
+	}
+
+	genres, err := NewSpotifyClient().GetArtistGenres(ctx, artistID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = historyDB.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(genreBucket))
+		if err != nil {
+			return err
+		}
+		buf, err := json.Marshal(cachedArtistGenres{Genres: genres, FetchedAt: time.Now().Unix()})
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(artistID), buf)
+	})
+	if err != nil {
+		metadataLog.Debug("could not cache artist genres", "artist_id", artistID, "error", err)
+	}
+	return genres, nil
+}
+
+// GetArtistGenres fetches an artist's genres from the Web API, which the
+// GraphQL artist overview doesn't include
+func (c *SpotifyClient) GetArtistGenres(ctx context.Context, artistID string) ([]string, error) {
+	if c.accessToken == "" {
+		if err := c.getAccessToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.spotify.com/v1/artists/"+artistID, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%w: artist request failed: %w", SpotifyError, statusError("spotify", resp))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var artist struct {
+		Genres []string `json:"genres"`
+	}
+	if err := json.Unmarshal(body, &artist); err != nil {
+		return nil, err
+	}
+	if artist.Genres == nil {
+		artist.Genres = []string{}
+	}
+	return artist.Genres, nil
+}
@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Hook events
+const (
+	HookTrackComplete = "on_track_complete"
+	HookAlbumComplete = "on_album_complete"
+	HookFailure       = "on_failure"
+)
+
+// HookTimeout bounds how long a hook may run before it is killed
+const HookTimeout = 5 * time.Minute
+
+// HookPayload is passed to hook commands as JSON on stdin and as SPOTIFLAC_*
+// environment variables. Track events fill in the track fields, album events
+// the counts.
+type HookPayload struct {
+	Event          string `json:"event"`
+	Status         string `json:"status"`
+	File           string `json:"file,omitempty"`
+	SpotifyID      string `json:"spotify_id,omitempty"`
+	ISRC           string `json:"isrc,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Artist         string `json:"artist,omitempty"`
+	Album          string `json:"album,omitempty"`
+	Service        string `json:"service,omitempty"`
+	Quality        string `json:"quality,omitempty"`
+	CollectionType string `json:"collection_type,omitempty"`
+	CollectionName string `json:"collection_name,omitempty"`
+	Error          string `json:"error,omitempty"`
+	ErrorKind      string `json:"error_kind,omitempty"`
+	Total          int    `json:"total,omitempty"`
+	Completed      int    `json:"completed,omitempty"`
+	Skipped        int    `json:"skipped,omitempty"`
+	Failed         int    `json:"failed,omitempty"`
+}
+
+// Env returns the payload as environment variables. Empty values are still
+// set so hooks can rely on every variable existing.
+func (p HookPayload) Env() []string {
+	vars := []struct{ name, value string }{
+		{"EVENT", p.Event},
+		{"STATUS", p.Status},
+		{"FILE", p.File},
+		{"SPOTIFY_ID", p.SpotifyID},
+		{"ISRC", p.ISRC},
+		{"TITLE", p.Title},
+		{"ARTIST", p.Artist},
+		{"ALBUM", p.Album},
+		{"SERVICE", p.Service},
+		{"QUALITY", p.Quality},
+		{"COLLECTION_TYPE", p.CollectionType},
+		{"COLLECTION_NAME", p.CollectionName},
+		{"ERROR", p.Error},
+		{"ERROR_KIND", p.ErrorKind},
+		{"TOTAL", strconv.Itoa(p.Total)},
+		{"COMPLETED", strconv.Itoa(p.Completed)},
+		{"SKIPPED", strconv.Itoa(p.Skipped)},
+		{"FAILED", strconv.Itoa(p.Failed)},
+	}
+
+	env := make([]string, len(vars))
+	for i, v := range vars {
+		env[i] = "SPOTIFLAC_" + v.name + "=" + v.value
+	}
+	return env
+}
+
+// RunHook runs command through the shell with the payload on stdin and in the
+// environment. The hook's output goes to stderr.
+func RunHook(ctx context.Context, command string, payload HookPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, HookTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), payload.Env()...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook %s failed: %w", payload.Event, err)
+	}
+	return nil
+}
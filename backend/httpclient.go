@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPConfig applies to every HTTP client the backend creates
+type HTTPConfig struct {
+	// Proxy is an http://, https:// or socks5:// URL. Empty uses the
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables.
+	Proxy string
+	// CABundle is a PEM file whose certificates are trusted in addition to
+	// the system roots
+	CABundle string
+	// InsecureTLS lists services ("*" for all) whose certificates are not
+	// verified
+	InsecureTLS []string
+	// Timeouts overrides the default request timeout per service
+	Timeouts map[string]time.Duration
+}
+
+var (
+	httpConfig         HTTPConfig
+	httpTransport      = newTransport(nil, false)
+	httpInsecure       = newTransport(nil, true)
+	httpTransportsLock sync.RWMutex
+)
+
+// SetHTTPConfig configures clients created after the call
+func SetHTTPConfig(config HTTPConfig) error {
+	secure, insecure, err := config.transports()
+	if err != nil {
+		return err
+	}
+
+	httpTransportsLock.Lock()
+	defer httpTransportsLock.Unlock()
+	httpConfig = config
+	httpTransport = secure
+	httpInsecure = insecure
+	return nil
+}
+
+// Validate checks the proxy URL and CA bundle
+func (config HTTPConfig) Validate() error {
+	_, _, err := config.transports()
+	return err
+}
+
+func (config HTTPConfig) transports() (secure, insecure *http.Transport, err error) {
+	proxy := http.ProxyFromEnvironment
+	if config.Proxy != "" {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, nil, fmt.Errorf("invalid proxy: %s", config.Proxy)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, nil, fmt.Errorf("unsupported proxy scheme: %s (use http, https or socks5)", proxyURL.Scheme)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	var roots *x509.CertPool
+	if config.CABundle != "" {
+		pem, err := os.ReadFile(NormalizePath(config.CABundle))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		roots, err = x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificates found in CA bundle: %s", config.CABundle)
+		}
+	}
+
+	secure = newTransport(roots, false)
+	insecure = newTransport(roots, true)
+	secure.Proxy = proxy
+	insecure.Proxy = proxy
+	return secure, insecure, nil
+}
+
+// newTransport returns a pooled transport. All clients share one, so
+// connections to the same host are kept alive and reused across services.
+func newTransport(roots *x509.CertPool, insecure bool) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		TLSClientConfig: &tls.Config{
+			RootCAs:            roots,
+			InsecureSkipVerify: insecure,
+		},
+	}
+}
+
+// newHTTPClient returns a client for service using the shared transport.
+// timeout is the default for the service (0 for none) unless overridden in
+// HTTPConfig.Timeouts.
+func newHTTPClient(service string, timeout time.Duration) *http.Client {
+	httpTransportsLock.RLock()
+	defer httpTransportsLock.RUnlock()
+
+	if override, ok := httpConfig.Timeouts[service]; ok {
+		timeout = override
+	}
+	transport := httpTransport
+	if slices.Contains(httpConfig.InsecureTLS, service) || slices.Contains(httpConfig.InsecureTLS, "*") {
+		transport = httpInsecure
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// ParseHTTPTimeouts parses a comma-separated list of "service=duration"
+// entries, e.g. "tidal=10s,lucida=3m".
+func ParseHTTPTimeouts(spec string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		service, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid timeout: %s (use service=duration)", entry)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid timeout: %s", entry)
+		}
+		timeouts[strings.TrimSpace(service)] = timeout
+	}
+	return timeouts, nil
+}
+
+// httpGet is client.Get bound to ctx, so cancelling ctx aborts the request
+// and any read of the response body.
+func httpGet(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// sleepContext waits for d, returning early with ctx's error on cancellation.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,253 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type IdentifyStatus string
+
+const (
+	IdentifyMatched IdentifyStatus = "matched"
+	IdentifyReview  IdentifyStatus = "review"
+	IdentifySkipped IdentifyStatus = "skipped"
+	IdentifyFailed  IdentifyStatus = "failed"
+)
+
+var (
+	guessSeparatorRe   = regexp.MustCompile(`\s+[-–—]\s+`)
+	guessBracketRe     = regexp.MustCompile(`\[[^\]]*\]|\{[^}]*\}`)
+	guessTrackNumberRe = regexp.MustCompile(`^(?:\d[-.])?(\d{1,3})$`)
+	guessTrackPrefixRe = regexp.MustCompile(`^(?:\d[-.])?(\d{1,3})(?:\s*[.)_]\s*|\s*-\s*)(\S.*)$`)
+	guessPaddedPrefix  = regexp.MustCompile(`^(?:\d[-.])?(\d{2,3})\s+(\S.*)$`)
+	guessYearRe        = regexp.MustCompile(`^\(?\d{4}\)?\s*[-.]?\s+|\s*\(\d{4}\)$`)
+	guessYearOnlyRe    = regexp.MustCompile(`^\d{4}$`)
+	guessDiscDirRe     = regexp.MustCompile(`(?i)^(cd|disc|disk)\s*\d+$`)
+)
+
+// TrackGuess is what a file's name and folders say about the track
+type TrackGuess struct {
+	Title       string `json:"title,omitempty"`
+	Artist      string `json:"artist,omitempty"`
+	Album       string `json:"album,omitempty"`
+	TrackNumber int    `json:"track_number,omitempty"`
+}
+
+// GuessTrackFromPath parses names like "03 - Artist - Title", "Artist - 03 -
+// Title", "03. Title" and "Artist - Title", taking a missing artist and album
+// from an "Artist - Album" folder or from Artist/Album folders
+func GuessTrackFromPath(path string) TrackGuess {
+	var guess TrackGuess
+	name := cleanGuessName(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	parts := splitGuessName(name)
+	if len(parts) == 0 {
+		return guess
+	}
+
+	numberAt := -1
+	for i, part := range parts[:len(parts)-1] {
+		if m := guessTrackNumberRe.FindStringSubmatch(part); m != nil {
+			numberAt = i
+			guess.TrackNumber, _ = strconv.Atoi(m[1])
+			break
+		}
+	}
+	if numberAt >= 0 {
+		before, after := parts[:numberAt], parts[numberAt+1:]
+		if len(before) == 0 && len(after) > 1 {
+			before, after = after[:1], after[1:]
+		}
+		guess.Title = strings.Join(after, " - ")
+		if len(before) > 0 {
+			guess.Artist = before[0]
+		}
+		if len(before) > 1 {
+			guess.Album = before[1]
+		}
+	} else {
+		m := guessPaddedPrefix.FindStringSubmatch(parts[0])
+		if m == nil {
+			m = guessTrackPrefixRe.FindStringSubmatch(parts[0])
+		}
+		if m != nil {
+			guess.TrackNumber, _ = strconv.Atoi(m[1])
+			parts[0] = m[2]
+		}
+		switch len(parts) {
+		case 1:
+			guess.Title = parts[0]
+		case 2:
+			guess.Artist, guess.Title = parts[0], parts[1]
+		default:
+			guess.Artist = parts[0]
+			guess.Album = strings.Join(parts[1:len(parts)-1], " - ")
+			guess.Title = parts[len(parts)-1]
+		}
+	}
+
+	if guess.Artist != "" && guess.Album != "" {
+		return guess
+	}
+	dir := filepath.Dir(path)
+	if guessDiscDirRe.MatchString(filepath.Base(dir)) {
+		dir = filepath.Dir(dir)
+	}
+	if !isGuessFolder(dir) {
+		return guess
+	}
+	folderArtist, folderAlbum := "", cleanGuessName(filepath.Base(dir))
+	if parts := splitGuessName(folderAlbum); len(parts) > 1 && !guessYearOnlyRe.MatchString(parts[0]) {
+		folderArtist, folderAlbum = parts[0], strings.Join(parts[1:], " - ")
+	} else if parent := filepath.Dir(dir); isGuessFolder(parent) {
+		folderArtist = cleanGuessName(filepath.Base(parent))
+	}
+	folderAlbum = strings.TrimSpace(guessYearRe.ReplaceAllString(folderAlbum, ""))
+	if guess.Artist == "" {
+		guess.Artist = folderArtist
+	}
+	if guess.Album == "" {
+		guess.Album = folderAlbum
+	}
+	return guess
+}
+
+func isGuessFolder(dir string) bool {
+	base := filepath.Base(dir)
+	return base != "." && base != string(filepath.Separator) && filepath.VolumeName(dir) != dir
+}
+
+// cleanGuessName turns underscores into spaces and drops bracketed noise
+// like "[FLAC]"
+func cleanGuessName(name string) string {
+	name = strings.ReplaceAll(name, "_", " ")
+	name = guessBracketRe.ReplaceAllString(name, " ")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func splitGuessName(name string) []string {
+	var parts []string
+	for _, part := range guessSeparatorRe.Split(name, -1) {
+		if part = strings.Trim(part, " -"); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// IdentifyResult is the outcome of identifying one file. Files in review
+// keep their scored candidates so they can be matched by hand.
+type IdentifyResult struct {
+	File       string            `json:"file"`
+	Status     IdentifyStatus    `json:"status"`
+	Guess      TrackGuess        `json:"guess"`
+	DurationMS int               `json:"duration_ms,omitempty"`
+	Match      *SearchResult     `json:"match,omitempty"`
+	Confidence float64           `json:"confidence,omitempty"`
+	Candidates []ScoredCandidate `json:"candidates,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+}
+
+// Identifier matches untagged files to Spotify tracks and tags them
+type Identifier struct {
+	MinConfidence   float64
+	MaxQualityCover bool
+	// DryRun only matches files, nothing is written
+	DryRun  bool
+	AppName string
+
+	covers coverCache
+}
+
+// Identify guesses the track from the file's tags, name and folders, searches
+// Spotify for it and, when the best candidate is a confident match, embeds
+// its metadata and cover. Files with a Spotify ID or ISRC are skipped, retag
+// handles those.
+func (id *Identifier) Identify(ctx context.Context, filePath string) *IdentifyResult {
+	result := &IdentifyResult{File: filePath}
+	fail := func(status IdentifyStatus, reason string) *IdentifyResult {
+		result.Status, result.Reason = status, reason
+		return result
+	}
+
+	tags, err := ReadTags(filePath)
+	if err != nil {
+		return fail(IdentifyFailed, err.Error())
+	}
+	if len(tags.Values("SPOTIFY_TRACK_ID")) > 0 || len(tags.Values("ISRC")) > 0 {
+		return fail(IdentifySkipped, "already has a Spotify ID or ISRC, use retag")
+	}
+
+	result.Guess = GuessTrackFromPath(filePath)
+	if title := tags.Values("TITLE"); len(title) > 0 && strings.TrimSpace(title[0]) != "" {
+		result.Guess.Title = strings.TrimSpace(title[0])
+		if artists := tags.Values("ARTIST"); len(artists) > 0 {
+			result.Guess.Artist = strings.Join(artists, ", ")
+		}
+		if album := tags.Values("ALBUM"); len(album) > 0 {
+			result.Guess.Album = album[0]
+		}
+	}
+	guess := result.Guess
+	if guess.Title == "" {
+		return fail(IdentifyFailed, "no title in the tags or file name")
+	}
+
+	if duration, err := GetAudioDuration(filePath); err == nil {
+		result.DurationMS = int(math.Round(duration * 1000))
+	}
+
+	candidates, err := searchTrackMatch(ctx, strings.TrimSpace(guess.Artist+" "+guess.Title), guess.Title, guess.Artist, result.DurationMS)
+	if err != nil {
+		return fail(IdentifyFailed, err.Error())
+	}
+	if err := acceptMatch(candidates, id.MinConfidence); err != nil {
+		result.Candidates = candidates
+		if len(candidates) > 0 {
+			result.Confidence = candidates[0].Score
+		}
+		return fail(IdentifyReview, err.Error())
+	}
+	result.Match, result.Confidence = &candidates[0].SearchResult, candidates[0].Score
+
+	if id.DryRun {
+		result.Status = IdentifyMatched
+		return result
+	}
+	if err := id.embed(ctx, filePath, tags, result.Match.ID); err != nil {
+		return fail(IdentifyFailed, err.Error())
+	}
+	result.Status = IdentifyMatched
+	return result
+}
+
+// embed writes the track's metadata and cover, keeping tags Spotify has no
+// value for. With tag-merge set to fill-missing existing values are kept.
+func (id *Identifier) embed(ctx context.Context, filePath string, tags *FileTags, spotifyID string) error {
+	metadata, coverURL, err := SpotifyTrackMetadata(ctx, spotifyID, id.AppName)
+	if err != nil {
+		return err
+	}
+
+	fillMissing := GetTagMergeMode() == TagMergeFillMissing
+	var edit TagEdit
+	for _, field := range metadata.vorbisFields() {
+		if fillMissing && len(tags.Values(field.name)) > 0 {
+			continue
+		}
+		edit.Set = append(edit.Set, TagField{field.name, field.value})
+	}
+	if coverURL != "" && !(fillMissing && tags.HasCover) {
+		if edit.Cover, err = id.covers.get(ctx, coverURL, id.MaxQualityCover); err != nil {
+			metadataLog.Warn("failed to download cover art", "url", coverURL, "error", err)
+		}
+	}
+	if err := EditTags(filePath, edit); err != nil {
+		return fmt.Errorf("failed to write tags: %w", err)
+	}
+	return nil
+}
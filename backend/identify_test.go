@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGuessTrackFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want TrackGuess
+	}{
+		{
+			path: "/m/Music/Radiohead/OK Computer (1997)/03 - Radiohead - Subterranean Homesick Alien.flac",
+			want: TrackGuess{Title: "Subterranean Homesick Alien", Artist: "Radiohead", Album: "OK Computer", TrackNumber: 3},
+		},
+		{
+			path: "/m/Radiohead - OK Computer/03. Lucky.flac",
+			want: TrackGuess{Title: "Lucky", Artist: "Radiohead", Album: "OK Computer", TrackNumber: 3},
+		},
+		{
+			path: "/m/x/Album/CD2/1-05 Artist - Song Name [FLAC].mp3",
+			want: TrackGuess{Title: "Song Name", Artist: "Artist", Album: "Album", TrackNumber: 5},
+		},
+		{
+			path: "/m/Artist/Album/Artist - 07 - Title (Remix).m4a",
+			want: TrackGuess{Title: "Title (Remix)", Artist: "Artist", Album: "Album", TrackNumber: 7},
+		},
+		{
+			path: "/m/a/b/03_Some_Artist_-_Some_Title.flac",
+			want: TrackGuess{Title: "Some Title", Artist: "Some Artist", Album: "b", TrackNumber: 3},
+		},
+		{
+			path: "Artist - Album - 02 - Title.flac",
+			want: TrackGuess{Title: "Title", Artist: "Artist", Album: "Album", TrackNumber: 2},
+		},
+		{
+			path: "/m/Artist/2019 - Album/05 Title.flac",
+			want: TrackGuess{Title: "Title", Artist: "Artist", Album: "Album", TrackNumber: 5},
+		},
+		{
+			path: "/m/Artist/Album/Disc 1/01 - Title.flac",
+			want: TrackGuess{Title: "Title", Artist: "Artist", Album: "Album", TrackNumber: 1},
+		},
+		{
+			path: "/m/Artist/Album {Deluxe}/9) Title.flac",
+			want: TrackGuess{Title: "Title", Artist: "Artist", Album: "Album", TrackNumber: 9},
+		},
+		{
+			path: "/Artist - Title.mp3",
+			want: TrackGuess{Title: "Title", Artist: "Artist"},
+		},
+		{
+			path: "/x/03 - Title.flac",
+			want: TrackGuess{Title: "Title", Album: "x", TrackNumber: 3},
+		},
+		{
+			path: "/song.flac",
+			want: TrackGuess{Title: "song"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := GuessTrackFromPath(filepath.FromSlash(tt.path)); got != tt.want {
+				t.Errorf("GuessTrackFromPath() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package backend
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type ImportStatus string
+
+const (
+	ImportResolved  ImportStatus = "resolved"
+	ImportAmbiguous ImportStatus = "ambiguous"
+	ImportFailed    ImportStatus = "failed"
+)
+
+const (
+	DefaultImportConfidence = 0.8
+	importAmbiguityMargin   = 0.05
+	importSearchLimit       = 5
+)
+
+var (
+	isrcRe      = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{3}\d{7}$`)
+	spotifyIDRe = regexp.MustCompile(`^[A-Za-z0-9]{22}$`)
+)
+
+type ImportEntry struct {
+	Line       int    `json:"line"`
+	Raw        string `json:"raw"`
+	SpotifyURL string `json:"spotify_url,omitempty"`
+	ISRC       string `json:"isrc,omitempty"`
+	Artist     string `json:"artist,omitempty"`
+	Title      string `json:"title,omitempty"`
+	DurationMS int    `json:"duration_ms,omitempty"`
+}
+
+type ImportTrack struct {
+	SpotifyID string `json:"spotify_id"`
+	Name      string `json:"name"`
+	Artists   string `json:"artists"`
+	Album     string `json:"album,omitempty"`
+	Duration  int    `json:"duration_ms,omitempty"`
+}
+
+type ImportResult struct {
+	Entry      ImportEntry    `json:"entry"`
+	Status     ImportStatus   `json:"status"`
+	Confidence float64        `json:"confidence"`
+	Tracks     []ImportTrack  `json:"tracks,omitempty"`
+	Candidates []SearchResult `json:"candidates,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	Source  string         `json:"source"`
+	Results []ImportResult `json:"results"`
+}
+
+func (r *ImportReport) Tracks() []ImportTrack {
+	var tracks []ImportTrack
+	seen := make(map[string]bool)
+	for _, result := range r.Results {
+		if result.Status != ImportResolved {
+			continue
+		}
+		for _, track := range result.Tracks {
+			if seen[track.SpotifyID] {
+				continue
+			}
+			seen[track.SpotifyID] = true
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks
+}
+
+func (r *ImportReport) Count(status ImportStatus) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func ParseImportFile(path string) ([]ImportEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	head, _ := reader.Peek(4096)
+	firstLine := strings.ToLower(strings.SplitN(string(head), "\n", 2)[0])
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") || looksLikeCSVHeader(firstLine) {
+		return parseImportCSV(reader)
+	}
+	return parseImportText(reader)
+}
+
+// ParseImportLines parses entries passed directly rather than read from a
+// file, using the same rules as a plain text import.
+func ParseImportLines(lines []string) []ImportEntry {
+	entries, _ := parseImportText(strings.NewReader(strings.Join(lines, "\n")))
+	return entries
+}
+
+func looksLikeCSVHeader(line string) bool {
+	if !strings.Contains(line, ",") {
+		return false
+	}
+	return strings.Contains(line, "uri") || strings.Contains(line, "isrc") || strings.Contains(line, "track name")
+}
+
+func parseImportText(r io.Reader) ([]ImportEntry, error) {
+	var entries []ImportEntry
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		entries = append(entries, parseImportLine(lineNum, line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+	return entries, nil
+}
+
+func parseImportLine(lineNum int, line string) ImportEntry {
+	entry := ImportEntry{Line: lineNum, Raw: line}
+
+	switch {
+	case strings.HasPrefix(line, "spotify:") || strings.Contains(line, "spotify.com/"):
+		entry.SpotifyURL = line
+	case isrcRe.MatchString(strings.ToUpper(line)):
+		entry.ISRC = strings.ToUpper(line)
+	case spotifyIDRe.MatchString(line):
+		entry.SpotifyURL = "spotify:track:" + line
+	default:
+		if artist, title, ok := strings.Cut(line, " - "); ok {
+			entry.Artist = strings.TrimSpace(artist)
+			entry.Title = strings.TrimSpace(title)
+		} else {
+			entry.Title = line
+		}
+	}
+
+	return entry
+}
+
+func parseImportCSV(r io.Reader) ([]ImportEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	findColumn := func(names ...string) int {
+		for i, col := range header {
+			col = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff")))
+			for _, name := range names {
+				if col == name {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	uriCol := findColumn("track uri", "spotify uri", "uri", "spotify id", "spotify_id", "url")
+	isrcCol := findColumn("isrc")
+	titleCol := findColumn("track name", "title", "name", "track")
+	artistCol := findColumn("artist name(s)", "artist name", "artists", "artist")
+	durationCol := findColumn("duration (ms)", "track duration (ms)", "duration_ms")
+
+	if uriCol < 0 && isrcCol < 0 && titleCol < 0 {
+		return nil, fmt.Errorf("CSV must have a track URI, ISRC or track name column")
+	}
+
+	field := func(record []string, col int) string {
+		if col < 0 || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+
+	var entries []ImportEntry
+	lineNum := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		lineNum++
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", lineNum, err)
+		}
+
+		entry := ImportEntry{
+			Line:   lineNum,
+			Raw:    strings.Join(record, ","),
+			Title:  field(record, titleCol),
+			Artist: field(record, artistCol),
+		}
+		if ms, err := strconv.Atoi(field(record, durationCol)); err == nil {
+			entry.DurationMS = ms
+		}
+
+		uri := field(record, uriCol)
+		switch {
+		case strings.HasPrefix(uri, "spotify:") || strings.Contains(uri, "spotify.com/"):
+			entry.SpotifyURL = uri
+		case spotifyIDRe.MatchString(uri):
+			entry.SpotifyURL = "spotify:track:" + uri
+		}
+		entry.ISRC = strings.ToUpper(field(record, isrcCol))
+
+		if entry.SpotifyURL == "" && entry.ISRC == "" && entry.Title == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func ResolveImportEntries(ctx context.Context, entries []ImportEntry, minConfidence float64) *ImportReport {
+	if minConfidence <= 0 {
+		minConfidence = DefaultImportConfidence
+	}
+
+	report := &ImportReport{}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			report.Results = append(report.Results, ImportResult{Entry: entry, Status: ImportFailed, Error: ctx.Err().Error()})
+			continue
+		}
+		report.Results = append(report.Results, resolveImportEntry(ctx, entry, minConfidence))
+	}
+	return report
+}
+
+func resolveImportEntry(ctx context.Context, entry ImportEntry, minConfidence float64) ImportResult {
+	result := ImportResult{Entry: entry}
+
+	if entry.SpotifyURL != "" {
+		tracks, err := expandSpotifyURL(ctx, entry.SpotifyURL)
+		if err == nil && len(tracks) > 0 {
+			result.Status = ImportResolved
+			result.Confidence = 1
+			result.Tracks = tracks
+			return result
+		}
+		if entry.ISRC == "" && entry.Title == "" {
+			result.Status = ImportFailed
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Error = "no tracks found"
+			}
+			return result
+		}
+	}
+
+	var query string
+	switch {
+	case entry.ISRC != "":
+		query = "isrc:" + entry.ISRC
+	case entry.Artist != "":
+		query = entry.Artist + " " + entry.Title
+	default:
+		query = entry.Title
+	}
+
+	candidates, err := SearchSpotifyByType(ctx, query, "track", importSearchLimit, 0)
+	if err == nil && len(candidates) == 0 && entry.ISRC != "" && entry.Title != "" {
+		candidates, err = SearchSpotifyByType(ctx, strings.TrimSpace(entry.Artist+" "+entry.Title), "track", importSearchLimit, 0)
+	}
+	if err != nil {
+		result.Status = ImportFailed
+		result.Error = err.Error()
+		return result
+	}
+	if len(candidates) == 0 {
+		result.Status = ImportFailed
+		result.Error = "no search results"
+		return result
+	}
+
+	// An ISRC search without a title to compare against is taken at face value
+	if entry.ISRC != "" && entry.Title == "" {
+		best := candidates[0]
+		result.Status = ImportResolved
+		result.Confidence = 1
+		result.Tracks = []ImportTrack{{SpotifyID: best.ID, Name: best.Name, Artists: best.Artists, Album: best.AlbumName, Duration: best.Duration}}
+		return result
+	}
+
+	return scoreImportCandidates(result, candidates, minConfidence)
+}
+
+// scoreImportCandidates resolves result to the best scoring candidate, or
+// marks it ambiguous or failed when no candidate is a clear match
+func scoreImportCandidates(result ImportResult, candidates []SearchResult, minConfidence float64) ImportResult {
+	if len(candidates) == 0 {
+		result.Status = ImportFailed
+		result.Error = "no search results"
+		return result
+	}
+
+	entry := result.Entry
+	bestIdx, bestScore, runnerUp := 0, -1.0, 0.0
+	for i, candidate := range candidates {
+		score := ScoreTrackMatch(entry.Title, entry.Artist, entry.DurationMS, candidate)
+		if score > bestScore {
+			runnerUp = max(runnerUp, bestScore)
+			bestIdx, bestScore = i, score
+		} else if score > runnerUp {
+			runnerUp = score
+		}
+	}
+	result.Confidence = bestScore
+	best := candidates[bestIdx]
+
+	switch {
+	case bestScore >= minConfidence && bestScore-runnerUp >= importAmbiguityMargin:
+		result.Status = ImportResolved
+		result.Tracks = []ImportTrack{{SpotifyID: best.ID, Name: best.Name, Artists: best.Artists, Album: best.AlbumName, Duration: best.Duration}}
+	case bestScore >= minConfidence*0.75:
+		result.Status = ImportAmbiguous
+		result.Candidates = candidates
+	default:
+		result.Status = ImportFailed
+		result.Error = fmt.Sprintf("best match %q by %s scored %.2f", best.Name, best.Artists, bestScore)
+	}
+
+	return result
+}
+
+type SpotifyCollection struct {
+	Type   string
+	Name   string
+	Tracks []AlbumTrackMetadata
+}
+
+func GetSpotifyCollection(ctx context.Context, spotifyURL string) (*SpotifyCollection, error) {
+	data, err := GetFilteredSpotifyData(ctx, spotifyURL, false, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch payload := data.(type) {
+	case *AlbumResponsePayload:
+		return &SpotifyCollection{Type: "album", Name: payload.AlbumInfo.Name, Tracks: payload.TrackList}, nil
+	case PlaylistResponsePayload:
+		return &SpotifyCollection{Type: "playlist", Name: payload.PlaylistInfo.Owner.Name, Tracks: payload.TrackList}, nil
+	case *ArtistDiscographyPayload:
+		return &SpotifyCollection{Type: "artist", Name: payload.ArtistInfo.Name, Tracks: payload.TrackList}, nil
+	default:
+		return nil, fmt.Errorf("not an album, playlist or artist URL: %s", spotifyURL)
+	}
+}
+
+func IsSpotifyTrackURL(spotifyURL string) bool {
+	parsed, err := parseSpotifyURI(spotifyURL)
+	return err == nil && parsed.Type == "track"
+}
+
+func expandSpotifyURL(ctx context.Context, spotifyURL string) ([]ImportTrack, error) {
+	parsed, err := parseSpotifyURI(spotifyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Type == "track" {
+		if !spotifyIDRe.MatchString(parsed.ID) {
+			return nil, errInvalidSpotifyURL
+		}
+		return []ImportTrack{{SpotifyID: parsed.ID}}, nil
+	}
+
+	collection, err := GetSpotifyCollection(ctx, spotifyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]ImportTrack, 0, len(collection.Tracks))
+	for _, t := range collection.Tracks {
+		if t.SpotifyID == "" {
+			continue
+		}
+		tracks = append(tracks, ImportTrack{SpotifyID: t.SpotifyID, Name: t.Name, Artists: t.Artists, Album: t.AlbumName, Duration: t.DurationMS})
+	}
+	return tracks, nil
+}
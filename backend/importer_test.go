@@ -0,0 +1,116 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScoreImportCandidates(t *testing.T) {
+	song := SearchResult{ID: "a", Name: "Song", Artists: "Artist", Duration: 180000}
+	other := SearchResult{ID: "b", Name: "Completely Different", Artists: "Someone Else", Duration: 240000}
+	tests := []struct {
+		name       string
+		entry      ImportEntry
+		candidates []SearchResult
+		want       ImportStatus
+		wantID     string
+	}{
+		{"clear match", ImportEntry{Artist: "Artist", Title: "Song"}, []SearchResult{other, song}, ImportResolved, "a"},
+		{"identical candidates", ImportEntry{Artist: "Artist", Title: "Song"}, []SearchResult{song, song}, ImportAmbiguous, ""},
+		{"poor match", ImportEntry{Artist: "Nobody", Title: "Nothing Alike"}, []SearchResult{other}, ImportFailed, ""},
+		{"every candidate scores zero", ImportEntry{Title: "!!!"}, []SearchResult{song, other}, ImportFailed, ""},
+		{"bracketed title scores zero", ImportEntry{Title: "(Intro)"}, []SearchResult{song}, ImportFailed, ""},
+		{"no candidates", ImportEntry{Title: "Song"}, nil, ImportFailed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scoreImportCandidates(ImportResult{Entry: tt.entry}, tt.candidates, DefaultImportConfidence)
+			if result.Status != tt.want {
+				t.Fatalf("status = %s, want %s (error %q)", result.Status, tt.want, result.Error)
+			}
+			if tt.wantID != "" && (len(result.Tracks) != 1 || result.Tracks[0].SpotifyID != tt.wantID) {
+				t.Errorf("tracks = %+v, want %s", result.Tracks, tt.wantID)
+			}
+			if result.Status == ImportFailed && result.Error == "" {
+				t.Error("failed result has no error")
+			}
+		})
+	}
+}
+
+func TestParseImportText(t *testing.T) {
+	entries, err := parseImportText(strings.NewReader(strings.Join([]string{
+		"# comment",
+		"",
+		"https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC",
+		"spotify:album:1DFixLWuPkv3KT3TnV35m3",
+		"usum71703861",
+		"4uLU6hMCjMI75M1A2tKUQC",
+		"Artist - Title - Remix",
+		"Just A Title",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ImportEntry{
+		{Line: 3, SpotifyURL: "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"},
+		{Line: 4, SpotifyURL: "spotify:album:1DFixLWuPkv3KT3TnV35m3"},
+		{Line: 5, ISRC: "USUM71703861"},
+		{Line: 6, SpotifyURL: "spotify:track:4uLU6hMCjMI75M1A2tKUQC"},
+		{Line: 7, Artist: "Artist", Title: "Title - Remix"},
+		{Line: 8, Title: "Just A Title"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, entry := range entries {
+		entry.Raw = ""
+		if entry != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+}
+
+func TestParseImportCSV(t *testing.T) {
+	data := "Track URI,Track Name,Artist Name(s),ISRC,Duration (ms)\n" +
+		"spotify:track:4uLU6hMCjMI75M1A2tKUQC,Song,Artist,usum71703861,180000\n" +
+		",,,,\n" +
+		",Other Song,\"A, B\",,not a number\n"
+	entries, err := parseImportCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ImportEntry{
+		{Line: 2, SpotifyURL: "spotify:track:4uLU6hMCjMI75M1A2tKUQC", Title: "Song", Artist: "Artist", ISRC: "USUM71703861", DurationMS: 180000},
+		{Line: 4, Title: "Other Song", Artist: "A, B"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, entry := range entries {
+		entry.Raw = ""
+		if entry != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+
+	if _, err := parseImportCSV(strings.NewReader("foo,bar\n1,2\n")); err == nil {
+		t.Error("CSV without a usable column was accepted")
+	}
+}
+
+func TestParseImportFileDetectsCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt")
+	if err := os.WriteFile(path, []byte("isrc,title\nUSUM71703861,Song\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ParseImportFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ISRC != "USUM71703861" || entries[0].Title != "Song" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
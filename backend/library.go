@@ -0,0 +1,230 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type SkipExistingMode string
+
+const (
+	SkipExistingISRC     SkipExistingMode = "isrc"
+	SkipExistingFilename SkipExistingMode = "filename"
+	SkipExistingNone     SkipExistingMode = "none"
+)
+
+type ExistingFileAction int
+
+const (
+	ExistingSkip ExistingFileAction = iota
+	ExistingRename
+	ExistingOverwrite
+)
+
+type LibraryEntry struct {
+	Path      string `json:"path"`
+	ISRC      string `json:"isrc,omitempty"`
+	SpotifyID string `json:"spotify_id,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Artist    string `json:"artist,omitempty"`
+	IndexedAt int64  `json:"indexed_at"`
+}
+
+const libraryBucket = "LibraryIndex"
+
+func libraryKeys(isrc, spotifyID string) [][]byte {
+	var keys [][]byte
+	if isrc != "" {
+		keys = append(keys, []byte("isrc:"+strings.ToUpper(isrc)))
+	}
+	if spotifyID != "" {
+		keys = append(keys, []byte("spotify:"+spotifyID))
+	}
+	return keys
+}
+
+func resolveOutputPath(path string, action ExistingFileAction) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() == 0 {
+		return path, false
+	}
+
+	switch action {
+	case ExistingOverwrite:
+		return path, false
+	case ExistingRename:
+		ext := filepath.Ext(path)
+		base := strings.TrimSuffix(path, ext)
+		for i := 2; ; i++ {
+			candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+			if !fileExists(candidate) {
+				return candidate, false
+			}
+		}
+	default:
+		return path, true
+	}
+}
+
+func spotifyTrackIDFromURL(spotifyURL string) string {
+	parsed, err := parseSpotifyURI(spotifyURL)
+	if err != nil || parsed.Type != "track" {
+		return ""
+	}
+	return parsed.ID
+}
+
+func IndexLibraryFile(entry LibraryEntry, appName string) error {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return err
+		}
+	}
+
+	keys := libraryKeys(entry.ISRC, entry.SpotifyID)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if abs, err := filepath.Abs(entry.Path); err == nil {
+		entry.Path = abs
+	}
+	entry.IndexedAt = time.Now().Unix()
+
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return historyDB.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(libraryBucket))
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := b.Put(key, buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LookupLibrary finds an indexed file by ISRC or Spotify ID. Entries whose
+// file no longer exists are dropped from the index.
+func LookupLibrary(isrc, spotifyID string, appName string) (*LibraryEntry, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := libraryKeys(isrc, spotifyID)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var found *LibraryEntry
+	var stale [][]byte
+	err := historyDB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(libraryBucket))
+		if b == nil {
+			return nil
+		}
+		for _, key := range keys {
+			v := b.Get(key)
+			if v == nil {
+				continue
+			}
+			var entry LibraryEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				continue
+			}
+			if !fileExists(entry.Path) {
+				stale = append(stale, key)
+				continue
+			}
+			found = &entry
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stale) > 0 {
+		historyDB.Update(func(tx *bolt.Tx) error {
+			b := tx.Bucket([]byte(libraryBucket))
+			if b == nil {
+				return nil
+			}
+			for _, key := range stale {
+				b.Delete(key)
+			}
+			return nil
+		})
+	}
+
+	return found, nil
+}
+
+func ScanLibrary(dir string, appName string) (indexed int, untagged int, err error) {
+	files, err := ListAudioFiles(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, file := range files {
+		meta, err := ReadAudioMetadata(file.Path)
+		if err != nil || (meta.ISRC == "" && meta.SpotifyID == "") {
+			untagged++
+			continue
+		}
+
+		entry := LibraryEntry{
+			Path:      file.Path,
+			ISRC:      meta.ISRC,
+			SpotifyID: meta.SpotifyID,
+			Title:     meta.Title,
+			Artist:    meta.Artist,
+		}
+		if err := IndexLibraryFile(entry, appName); err != nil {
+			return indexed, untagged, err
+		}
+		indexed++
+	}
+
+	return indexed, untagged, nil
+}
+
+func GetLibraryStats(appName string) (int, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return 0, err
+		}
+	}
+
+	paths := make(map[string]bool)
+	err := historyDB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(libraryBucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var entry LibraryEntry
+			if json.Unmarshal(v, &entry) == nil {
+				paths[entry.Path] = true
+			}
+			return nil
+		})
+	})
+
+	return len(paths), err
+}
@@ -0,0 +1,95 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+var (
+	logHandler     atomic.Pointer[slog.Handler]
+	progressOutput atomic.Pointer[io.Writer]
+)
+
+func init() {
+	SetLogHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	SetProgressOutput(os.Stderr)
+}
+
+// SetLogHandler replaces the handler used by all backend loggers, including
+// ones created before the call.
+func SetLogHandler(handler slog.Handler) {
+	logHandler.Store(&handler)
+}
+
+// SetProgressOutput sets where live progress lines ("Downloaded: 3.2 MB") are
+// written. Use io.Discard to hide them.
+func SetProgressOutput(w io.Writer) {
+	progressOutput.Store(&w)
+}
+
+// progressf writes a transient progress line. These are terminal updates
+// rather than log records, so they bypass the log handler.
+func progressf(format string, args ...any) {
+	fmt.Fprintf(*progressOutput.Load(), format, args...)
+}
+
+// newLogger returns a logger tagged with component that always writes to the
+// handler set by the most recent SetLogHandler call.
+func newLogger(component string) *slog.Logger {
+	return slog.New(deferredHandler{}).With("component", component)
+}
+
+type deferredHandler struct {
+	wrap func(slog.Handler) slog.Handler
+}
+
+func (h deferredHandler) current() slog.Handler {
+	handler := *logHandler.Load()
+	if h.wrap != nil {
+		handler = h.wrap(handler)
+	}
+	return handler
+}
+
+func (h deferredHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return (*logHandler.Load()).Enabled(ctx, level)
+}
+
+func (h deferredHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.current().Handle(ctx, record)
+}
+
+func (h deferredHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.chain(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h deferredHandler) WithGroup(name string) slog.Handler {
+	return h.chain(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h deferredHandler) chain(next func(slog.Handler) slog.Handler) slog.Handler {
+	prev := h.wrap
+	return deferredHandler{wrap: func(handler slog.Handler) slog.Handler {
+		if prev != nil {
+			handler = prev(handler)
+		}
+		return next(handler)
+	}}
+}
+
+var (
+	tidalLog    = newLogger("tidal")
+	qobuzLog    = newLogger("qobuz")
+	amazonLog   = newLogger("amazon")
+	songLinkLog = newLogger("songlink")
+	spotifyLog  = newLogger("spotify")
+	lyricsLog   = newLogger("lyrics")
+	metadataLog = newLogger("metadata")
+	ffmpegLog   = newLogger("ffmpeg")
+	analysisLog = newLogger("analysis")
+	retryLog    = newLogger("retry")
+)
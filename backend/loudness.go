@@ -0,0 +1,286 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+
+	mewflac "github.com/mewkiz/flac"
+)
+
+// Loudness is an ITU-R BS.1770-4 measurement of a track or album
+type Loudness struct {
+	// Integrated is the gated loudness in LUFS, -Inf for silence
+	Integrated float64
+	// TruePeak is the highest inter-sample peak over all channels, 1.0 being
+	// full scale
+	TruePeak float64
+
+	// blocks holds the channel-weighted mean square of every 400ms gating
+	// block, so album loudness can gate over the blocks of all tracks
+	blocks []float64
+}
+
+const (
+	absoluteGate = -70.0
+	relativeGate = -10.0
+)
+
+// MeasureLoudness decodes a FLAC file and measures its integrated loudness
+// and true peak
+func MeasureLoudness(filePath string) (*Loudness, error) {
+	if !strings.EqualFold(filepath.Ext(filePath), ".flac") {
+		return nil, fmt.Errorf("loudness can only be measured on FLAC files: %s", filePath)
+	}
+
+	stream, err := mewflac.ParseFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FLAC: %w", err)
+	}
+	defer stream.Close()
+
+	info := stream.Info
+	meter := newLoudnessMeter(int(info.SampleRate), int(info.NChannels))
+	scale := 1 / float64(int64(1)<<(info.BitsPerSample-1))
+	samples := make([]float64, info.NChannels)
+	for {
+		frame, err := stream.ParseNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode FLAC: %w", err)
+		}
+		for i := 0; i < frame.Subframes[0].NSamples; i++ {
+			for ch := range samples {
+				samples[ch] = float64(frame.Subframes[ch].Samples[i]) * scale
+			}
+			meter.add(samples)
+		}
+	}
+	return meter.result(), nil
+}
+
+// AlbumLoudness combines track measurements as if the tracks were played
+// back to back
+func AlbumLoudness(tracks []*Loudness) *Loudness {
+	album := &Loudness{}
+	for _, track := range tracks {
+		album.blocks = append(album.blocks, track.blocks...)
+		album.TruePeak = math.Max(album.TruePeak, track.TruePeak)
+	}
+	album.Integrated = gatedLoudness(album.blocks)
+	return album
+}
+
+func blockLoudness(meanSquare float64) float64 {
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+// gatedLoudness applies the absolute and relative gates of BS.1770-4
+func gatedLoudness(blocks []float64) float64 {
+	mean := func(threshold float64) (float64, int) {
+		var sum float64
+		var n int
+		for _, block := range blocks {
+			if blockLoudness(block) > threshold {
+				sum += block
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, 0
+		}
+		return sum / float64(n), n
+	}
+
+	ungated, n := mean(absoluteGate)
+	if n == 0 {
+		return math.Inf(-1)
+	}
+	gated, n := mean(blockLoudness(ungated) + relativeGate)
+	if n == 0 {
+		return math.Inf(-1)
+	}
+	return blockLoudness(gated)
+}
+
+// biquad is a second order IIR filter in transposed direct form II
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.z1
+	f.z1 = f.b1*x - f.a1*y + f.z2
+	f.z2 = f.b2*x - f.a2*y
+	return y
+}
+
+// kWeighting returns the BS.1770 pre-filter (high shelf) and RLB high-pass,
+// derived for any sample rate the way libebur128 does
+func kWeighting(sampleRate int) (biquad, biquad) {
+	fs := float64(sampleRate)
+
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return shelf, highPass
+}
+
+// truePeakTaps is the number of input samples each interpolated sample is
+// computed from
+const truePeakTaps = 12
+
+// truePeakMeter estimates inter-sample peaks by oversampling to at least
+// 176.4kHz with a windowed-sinc interpolator
+type truePeakMeter struct {
+	factor  int
+	phases  [][]float64
+	history []float64
+	pos     int
+	peak    float64
+}
+
+func newTruePeakMeter(sampleRate int) *truePeakMeter {
+	factor := 1
+	for sampleRate*factor < 176400 {
+		factor *= 2
+	}
+	m := &truePeakMeter{factor: factor, history: make([]float64, truePeakTaps)}
+	if factor == 1 {
+		return m
+	}
+
+	// Each phase is a fractional delay filter
+	length := truePeakTaps * factor
+	center := float64(length-1) / 2
+	m.phases = make([][]float64, factor)
+	for p := range m.phases {
+		m.phases[p] = make([]float64, truePeakTaps)
+		for k := range m.phases[p] {
+			n := float64(k*factor + p)
+			t := (n - center) / float64(factor)
+			sinc := 1.0
+			if t != 0 {
+				sinc = math.Sin(math.Pi*t) / (math.Pi * t)
+			}
+			window := 0.5 - 0.5*math.Cos(2*math.Pi*(n+0.5)/float64(length))
+			m.phases[p][k] = sinc * window
+		}
+		// Unity gain at DC, the window would otherwise lower every peak
+		var sum float64
+		for _, c := range m.phases[p] {
+			sum += c
+		}
+		for k := range m.phases[p] {
+			m.phases[p][k] /= sum
+		}
+	}
+	return m
+}
+
+func (m *truePeakMeter) add(x float64) {
+	m.peak = math.Max(m.peak, math.Abs(x))
+	if m.factor == 1 {
+		return
+	}
+
+	m.history[m.pos] = x
+	m.pos = (m.pos + 1) % truePeakTaps
+	for _, phase := range m.phases {
+		var y float64
+		for k, c := range phase {
+			// phase[k] weights the k-th newest sample
+			y += c * m.history[(m.pos-1-k+2*truePeakTaps)%truePeakTaps]
+		}
+		m.peak = math.Max(m.peak, math.Abs(y))
+	}
+}
+
+// loudnessMeter accumulates K-weighted energy in 100ms steps; four steps
+// make one 400ms gating block with 75% overlap
+type loudnessMeter struct {
+	weights  []float64
+	shelf    []biquad
+	highPass []biquad
+	peaks    []*truePeakMeter
+
+	stepLength int
+	stepFill   int
+	stepSum    float64
+	steps      []float64
+}
+
+func newLoudnessMeter(sampleRate, channels int) *loudnessMeter {
+	m := &loudnessMeter{stepLength: sampleRate / 10}
+	for ch := 0; ch < channels; ch++ {
+		shelf, highPass := kWeighting(sampleRate)
+		m.shelf = append(m.shelf, shelf)
+		m.highPass = append(m.highPass, highPass)
+		m.peaks = append(m.peaks, newTruePeakMeter(sampleRate))
+
+		// 5.1 in FLAC order is L R C LFE Ls Rs; the LFE is not counted and
+		// the surrounds are weighted up by 1.5dB
+		weight := 1.0
+		if channels == 6 {
+			switch ch {
+			case 3:
+				weight = 0
+			case 4, 5:
+				weight = 1.41
+			}
+		}
+		m.weights = append(m.weights, weight)
+	}
+	return m
+}
+
+func (m *loudnessMeter) add(samples []float64) {
+	for ch, x := range samples {
+		m.peaks[ch].add(x)
+		y := m.highPass[ch].process(m.shelf[ch].process(x))
+		m.stepSum += m.weights[ch] * y * y
+	}
+	m.stepFill++
+	if m.stepFill == m.stepLength {
+		m.steps = append(m.steps, m.stepSum)
+		m.stepFill, m.stepSum = 0, 0
+	}
+}
+
+func (m *loudnessMeter) result() *Loudness {
+	l := &Loudness{}
+	for i := 0; i+4 <= len(m.steps); i++ {
+		sum := m.steps[i] + m.steps[i+1] + m.steps[i+2] + m.steps[i+3]
+		l.blocks = append(l.blocks, sum/float64(4*m.stepLength))
+	}
+	for _, peak := range m.peaks {
+		l.TruePeak = math.Max(l.TruePeak, peak.peak)
+	}
+	l.Integrated = gatedLoudness(l.blocks)
+	return l
+}
@@ -0,0 +1,127 @@
+package backend
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// measureSine runs seconds of a sine through the meter on the given channels
+// and returns the measurement
+func measureSine(sampleRate, channels int, active []int, freq, dbfs, phase, seconds float64) *Loudness {
+	meter := newLoudnessMeter(sampleRate, channels)
+	amplitude := math.Pow(10, dbfs/20)
+	samples := make([]float64, channels)
+	for i := 0; i < int(seconds*float64(sampleRate)); i++ {
+		x := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)+phase)
+		for _, ch := range active {
+			samples[ch] = x
+		}
+		meter.add(samples)
+	}
+	return meter.result()
+}
+
+// msForLoudness is the block mean square that measures loudness LUFS
+func msForLoudness(loudness float64) float64 {
+	return math.Pow(10, (loudness+0.691)/10)
+}
+
+func TestMeasureSineLoudness(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampleRate int
+		channels   int
+		active     []int
+		dbfs       float64
+		want       float64
+	}{
+		// BS.1770: a 0 dBFS 997Hz sine on one channel reads -3.01 LKFS
+		{name: "full scale mono", sampleRate: 48000, channels: 1, active: []int{0}, dbfs: 0, want: -3.01},
+		{name: "-23 dBFS stereo", sampleRate: 48000, channels: 2, active: []int{0, 1}, dbfs: -23, want: -23},
+		{name: "-20 dBFS left only", sampleRate: 44100, channels: 2, active: []int{0}, dbfs: -20, want: -23.01},
+		{name: "-18 dBFS stereo at 96kHz", sampleRate: 96000, channels: 2, active: []int{0, 1}, dbfs: -18, want: -18},
+		{name: "LFE is not counted", sampleRate: 48000, channels: 6, active: []int{0, 3}, dbfs: 0, want: -3.01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := measureSine(tt.sampleRate, tt.channels, tt.active, 997, tt.dbfs, 0, 5)
+			if math.Abs(l.Integrated-tt.want) > 0.1 {
+				t.Errorf("integrated = %.2f LUFS, want %.2f", l.Integrated, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeasureSilence(t *testing.T) {
+	l := measureSine(48000, 2, nil, 997, 0, 0, 2)
+	if !math.IsInf(l.Integrated, -1) {
+		t.Errorf("integrated = %v, want -Inf", l.Integrated)
+	}
+	if l.TruePeak != 0 {
+		t.Errorf("true peak = %v, want 0", l.TruePeak)
+	}
+}
+
+func TestTruePeakBetweenSamples(t *testing.T) {
+	// A quarter sample rate sine shifted by 45 degrees never has a sample at
+	// its crest: every sample is ±0.707, the waveform peaks at 1.0
+	l := measureSine(48000, 1, []int{0}, 12000, 0, math.Pi/4, 1)
+	if math.Abs(l.TruePeak-1) > 0.05 {
+		t.Errorf("true peak = %.3f, want about 1.0", l.TruePeak)
+	}
+}
+
+func TestGatedLoudness(t *testing.T) {
+	repeat := func(loudness float64, n int) []float64 {
+		blocks := make([]float64, n)
+		for i := range blocks {
+			blocks[i] = msForLoudness(loudness)
+		}
+		return blocks
+	}
+
+	tests := []struct {
+		name   string
+		blocks []float64
+		want   float64
+	}{
+		{name: "no blocks", blocks: nil, want: math.Inf(-1)},
+		{name: "constant", blocks: repeat(-20, 10), want: -20},
+		{name: "below absolute gate", blocks: repeat(-75, 10), want: math.Inf(-1)},
+		{name: "silence is gated out", blocks: slices.Concat(repeat(-20, 10), repeat(-90, 30)), want: -20},
+		{name: "quiet part below relative gate", blocks: slices.Concat(repeat(-20, 10), repeat(-40, 10)), want: -20},
+		// Both above the relative gate, so the energies are averaged
+		{name: "within relative gate", blocks: slices.Concat(repeat(-20, 10), repeat(-25, 10)), want: blockLoudness((msForLoudness(-20) + msForLoudness(-25)) / 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gatedLoudness(tt.blocks)
+			if math.IsInf(tt.want, -1) {
+				if !math.IsInf(got, -1) {
+					t.Errorf("gatedLoudness = %v, want -Inf", got)
+				}
+				return
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("gatedLoudness = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlbumLoudness(t *testing.T) {
+	loud := &Loudness{TruePeak: 0.9, blocks: []float64{msForLoudness(-14), msForLoudness(-14)}}
+	quiet := &Loudness{TruePeak: 0.5, blocks: []float64{msForLoudness(-16), msForLoudness(-16)}}
+
+	album := AlbumLoudness([]*Loudness{loud, quiet})
+	want := blockLoudness((msForLoudness(-14) + msForLoudness(-16)) / 2)
+	if math.Abs(album.Integrated-want) > 1e-9 {
+		t.Errorf("integrated = %.4f, want %.4f", album.Integrated, want)
+	}
+	if album.TruePeak != 0.9 {
+		t.Errorf("true peak = %v, want 0.9", album.TruePeak)
+	}
+}
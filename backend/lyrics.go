@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -67,11 +68,11 @@ type LyricsClient struct {
 
 func NewLyricsClient() *LyricsClient {
 	return &LyricsClient{
-		httpClient: &http.Client{Timeout: 15 * time.Second},
+		httpClient: newHTTPClient("lyrics", 15*time.Second),
 	}
 }
 
-func (c *LyricsClient) FetchLyricsWithMetadata(trackName, artistName string, duration int) (*LyricsResponse, error) {
+func (c *LyricsClient) FetchLyricsWithMetadata(ctx context.Context, trackName, artistName string, duration int) (*LyricsResponse, error) {
 
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9scmNsaWIubmV0L2FwaS9nZXQ/YXJ0aXN0X25hbWU9")
 	apiURL := fmt.Sprintf("%s%s&track_name=%s",
@@ -83,7 +84,7 @@ func (c *LyricsClient) FetchLyricsWithMetadata(trackName, artistName string, dur
 		apiURL = fmt.Sprintf("%s&duration=%d", apiURL, duration)
 	}
 
-	resp, err := c.httpClient.Get(apiURL)
+	resp, err := httpGet(ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch from LRCLIB: %v", err)
 	}
@@ -165,12 +166,12 @@ func lrcTimestampToMs(timestamp string) int64 {
 	return 0
 }
 
-func (c *LyricsClient) FetchLyricsFromLRCLibSearch(trackName, artistName string) (*LyricsResponse, error) {
+func (c *LyricsClient) FetchLyricsFromLRCLibSearch(ctx context.Context, trackName, artistName string) (*LyricsResponse, error) {
 	query := fmt.Sprintf("%s %s", artistName, trackName)
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9scmNsaWIubmV0L2FwaS9zZWFyY2g/cT0=")
 	apiURL := fmt.Sprintf("%s%s", string(apiBase), url.QueryEscape(query))
 
-	resp, err := c.httpClient.Get(apiURL)
+	resp, err := httpGet(ctx, c.httpClient, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %v", err)
 	}
@@ -224,30 +225,30 @@ func simplifyTrackName(name string) string {
 	return name
 }
 
-func (c *LyricsClient) FetchLyricsAllSources(spotifyID, trackName, artistName string, duration int) (*LyricsResponse, string, error) {
+func (c *LyricsClient) FetchLyricsAllSources(ctx context.Context, spotifyID, trackName, artistName string, duration int) (*LyricsResponse, string, error) {
 
-	resp, err := c.FetchLyricsWithMetadata(trackName, artistName, duration)
+	resp, err := c.FetchLyricsWithMetadata(ctx, trackName, artistName, duration)
 	if err == nil && resp != nil && !resp.Error && len(resp.Lines) > 0 {
 		return resp, "LRCLIB", nil
 	}
-	fmt.Printf("   LRCLIB exact: %v\n", err)
+	lyricsLog.Debug("LRCLIB exact match failed", "error", err)
 
-	resp, err = c.FetchLyricsFromLRCLibSearch(trackName, artistName)
+	resp, err = c.FetchLyricsFromLRCLibSearch(ctx, trackName, artistName)
 	if err == nil && resp != nil && !resp.Error && len(resp.Lines) > 0 {
 		return resp, "LRCLIB Search", nil
 	}
-	fmt.Printf("   LRCLIB search: %v\n", err)
+	lyricsLog.Debug("LRCLIB search failed", "error", err)
 
 	simplifiedTrack := simplifyTrackName(trackName)
 	if simplifiedTrack != trackName {
-		fmt.Printf("   Trying simplified name: %s\n", simplifiedTrack)
+		lyricsLog.Debug("trying simplified track name", "name", simplifiedTrack)
 
-		resp, err = c.FetchLyricsWithMetadata(simplifiedTrack, artistName, duration)
+		resp, err = c.FetchLyricsWithMetadata(ctx, simplifiedTrack, artistName, duration)
 		if err == nil && resp != nil && !resp.Error && len(resp.Lines) > 0 {
 			return resp, "LRCLIB (simplified)", nil
 		}
 
-		resp, err = c.FetchLyricsFromLRCLibSearch(simplifiedTrack, artistName)
+		resp, err = c.FetchLyricsFromLRCLibSearch(ctx, simplifiedTrack, artistName)
 		if err == nil && resp != nil && !resp.Error && len(resp.Lines) > 0 {
 			return resp, "LRCLIB Search (simplified)", nil
 		}
@@ -388,7 +389,7 @@ func findAudioFileForLyrics(dir, trackName, artistName string) string {
 	return ""
 }
 
-func (c *LyricsClient) DownloadLyrics(req LyricsDownloadRequest) (*LyricsDownloadResponse, error) {
+func (c *LyricsClient) DownloadLyrics(ctx context.Context, req LyricsDownloadRequest) (*LyricsDownloadResponse, error) {
 	if req.SpotifyID == "" {
 		return &LyricsDownloadResponse{
 			Success: false,
@@ -451,11 +452,11 @@ func (c *LyricsClient) DownloadLyrics(req LyricsDownloadRequest) (*LyricsDownloa
 		duration, err := GetAudioDuration(audioFile)
 		if err == nil && duration > 0 {
 			audioDuration = int(duration)
-			fmt.Printf("[DownloadLyrics] Found audio file, duration: %d seconds\n", audioDuration)
+			lyricsLog.Debug("found audio file", "path", audioFile, "duration", audioDuration)
 		}
 	}
 
-	lyrics, _, err := c.FetchLyricsAllSources(req.SpotifyID, req.TrackName, req.ArtistName, audioDuration)
+	lyrics, _, err := c.FetchLyricsAllSources(ctx, req.SpotifyID, req.TrackName, req.ArtistName, audioDuration)
 	if err != nil {
 		return &LyricsDownloadResponse{
 			Success: false,
@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	matchBracketRe = regexp.MustCompile(`[\(\[][^\)\]]*[\)\]]`)
+	matchFeatRe    = regexp.MustCompile(`\s+(feat\.?|ft\.?|featuring)\s+.*$`)
+)
+
+func normalizeForMatch(s string) string {
+	s = strings.ToLower(s)
+	s = matchBracketRe.ReplaceAllString(s, " ")
+	s = matchFeatRe.ReplaceAllString(s, "")
+
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func levenshtein(a, b []rune) int {
+	if len(a) == 0 {
+		return len(b)
+	}
+	if len(b) == 0 {
+		return len(a)
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func StringSimilarity(a, b string) float64 {
+	na := []rune(normalizeForMatch(a))
+	nb := []rune(normalizeForMatch(b))
+
+	if len(na) == 0 && len(nb) == 0 {
+		return 1
+	}
+	if len(na) == 0 || len(nb) == 0 {
+		return 0
+	}
+
+	maxLen := max(len(na), len(nb))
+	return 1 - float64(levenshtein(na, nb))/float64(maxLen)
+}
+
+func artistSimilarity(wanted, candidate string) float64 {
+	best := StringSimilarity(wanted, candidate)
+	for _, name := range strings.Split(candidate, ",") {
+		if sim := StringSimilarity(wanted, name); sim > best {
+			best = sim
+		}
+	}
+	return best
+}
+
+func ScoreTrackMatch(title, artist string, durationMS int, candidate SearchResult) float64 {
+	var score float64
+	if artist == "" {
+		score = max(
+			StringSimilarity(title, candidate.Name),
+			StringSimilarity(title, candidate.Artists+" "+candidate.Name),
+		)
+	} else {
+		score = 0.6*StringSimilarity(title, candidate.Name) + 0.4*artistSimilarity(artist, candidate.Artists)
+	}
+
+	if durationMS > 0 && candidate.Duration > 0 {
+		diff := durationMS - candidate.Duration
+		if diff < 0 {
+			diff = -diff
+		}
+		switch {
+		case diff <= 3000:
+		case diff <= 10000:
+			score *= 0.9
+		default:
+			score *= 0.6
+		}
+	}
+
+	return score
+}
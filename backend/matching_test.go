@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScoreTrackMatch(t *testing.T) {
+	candidate := SearchResult{Name: "Anti-Hero", Artists: "Taylor Swift", Duration: 200000}
+	tests := []struct {
+		name       string
+		title      string
+		artist     string
+		durationMS int
+		want       float64
+	}{
+		{"exact", "Anti-Hero", "Taylor Swift", 200000, 1},
+		{"case and punctuation", "anti hero", "taylor swift", 0, 1},
+		{"bracketed suffix ignored", "Anti-Hero (Live)", "Taylor Swift", 0, 1},
+		{"no artist", "Anti-Hero", "", 0, 1},
+		{"duration within 3s", "Anti-Hero", "Taylor Swift", 202500, 1},
+		{"duration within 10s", "Anti-Hero", "Taylor Swift", 208000, 0.9},
+		{"duration far off", "Anti-Hero", "Taylor Swift", 300000, 0.6},
+		{"title normalizes to empty", "!!!", "", 0, 0},
+		{"bracketed title only", "(Intro)", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScoreTrackMatch(tt.title, tt.artist, tt.durationMS, candidate)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ScoreTrackMatch(%q, %q, %d) = %.3f, want %.3f", tt.title, tt.artist, tt.durationMS, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Hello", "hello", 1},
+		{"", "", 1},
+		{"abc", "", 0},
+		{"Song feat. Someone", "Song", 1},
+		{"abcd", "abce", 0.75},
+	}
+	for _, tt := range tests {
+		if got := StringSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("StringSimilarity(%q, %q) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,6 +9,7 @@ import (
 	pathfilepath "path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	id3v2 "github.com/bogem/id3v2/v2"
 	"github.com/go-flac/flacpicture"
@@ -31,63 +33,201 @@ type Metadata struct {
 	Publisher   string
 	Lyrics      string
 	Description string
+	ISRC        string
+	SpotifyID   string
+
+	UPC            string
+	Label          string
+	Explicit       bool
+	SpotifyAlbumID string
+	SourceService  string
+	SourceTrackID  string
+
+	// Individual values for the multi-value fields. Artist and AlbumArtist
+	// stay the joined display strings.
+	Artists      []string
+	AlbumArtists []string
+	Genres       []string
+
+	Credits Credits
+
+	// ReplayGain is nil when the file hasn't been measured
+	ReplayGain *ReplayGain
 }
 
-func EmbedMetadata(filepath string, metadata Metadata, coverPath string) error {
-	f, err := flac.ParseFile(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to parse FLAC file: %w", err)
-	}
+var (
+	multiValueSeparator     = ", "
+	multiValueSeparatorLock sync.RWMutex
+)
 
-	var cmtIdx = -1
-	for idx, block := range f.Meta {
-		if block.Type == flac.VorbisComment {
-			cmtIdx = idx
-			break
-		}
-	}
+// SetMultiValueSeparator sets the separator JoinMultiValue puts between values
+func SetMultiValueSeparator(separator string) {
+	multiValueSeparatorLock.Lock()
+	defer multiValueSeparatorLock.Unlock()
+	multiValueSeparator = separator
+}
 
-	cmt := flacvorbis.New()
+// JoinMultiValue returns the display string for several artists or genres,
+// as used in filenames and single-value tags
+func JoinMultiValue(values []string) string {
+	multiValueSeparatorLock.RLock()
+	defer multiValueSeparatorLock.RUnlock()
+	return strings.Join(values, multiValueSeparator)
+}
+
+// ReleaseTags are tags the caller knows before a download that the download
+// arguments don't carry. Downloaders merge them into the Metadata they embed.
+type ReleaseTags struct {
+	SpotifyAlbumID string
+	Explicit       bool
+	Artists        []string
+	AlbumArtists   []string
+	Genres         []string
+	Credits        Credits
+}
 
-	if metadata.Title != "" {
-		_ = cmt.Add(flacvorbis.FIELD_TITLE, metadata.Title)
+// apply fills the release tags into m without overwriting values the source
+// service already provided
+func (r ReleaseTags) apply(m *Metadata) {
+	if m.SpotifyAlbumID == "" {
+		m.SpotifyAlbumID = r.SpotifyAlbumID
 	}
-	if metadata.Artist != "" {
-		_ = cmt.Add(flacvorbis.FIELD_ARTIST, metadata.Artist)
+	m.Explicit = m.Explicit || r.Explicit
+	if len(m.Artists) == 0 {
+		m.Artists = r.Artists
 	}
-	if metadata.Album != "" {
-		_ = cmt.Add(flacvorbis.FIELD_ALBUM, metadata.Album)
+	if len(m.AlbumArtists) == 0 {
+		m.AlbumArtists = r.AlbumArtists
 	}
-	if metadata.AlbumArtist != "" {
-		_ = cmt.Add("ALBUMARTIST", metadata.AlbumArtist)
+	if len(m.Genres) == 0 {
+		m.Genres = r.Genres
 	}
-	if metadata.Date != "" {
-		_ = cmt.Add(flacvorbis.FIELD_DATE, metadata.Date)
+	if m.Credits.IsEmpty() {
+		m.Credits = r.Credits
 	}
-	if metadata.TrackNumber > 0 {
-		_ = cmt.Add(flacvorbis.FIELD_TRACKNUMBER, strconv.Itoa(metadata.TrackNumber))
+}
+
+type tagField struct {
+	name, value string
+}
+
+// extraFields returns the identifier tags that have no standard frame in
+// ID3v2 or MP4, under the names used for Vorbis comments and TXXX frames
+func (m Metadata) extraFields() []tagField {
+	var fields []tagField
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, tagField{name, value})
+		}
+	}
+
+	add("BARCODE", m.UPC)
+	add("UPC", m.UPC)
+	if m.Explicit {
+		add("ITUNESADVISORY", "1")
 	}
-	if metadata.TotalTracks > 0 {
-		_ = cmt.Add("TOTALTRACKS", strconv.Itoa(metadata.TotalTracks))
+	add("SPOTIFY_TRACK_ID", m.SpotifyID)
+	add("SPOTIFY_ALBUM_ID", m.SpotifyAlbumID)
+	add("SOURCE_SERVICE", m.SourceService)
+	add("SOURCE_TRACK_ID", m.SourceTrackID)
+	return fields
+}
+
+// label returns the record label, falling back to the publisher
+func (m Metadata) label() string {
+	if m.Label != "" {
+		return m.Label
 	}
-	if metadata.DiscNumber > 0 {
-		_ = cmt.Add("DISCNUMBER", strconv.Itoa(metadata.DiscNumber))
+	return m.Publisher
+}
+
+func EmbedMetadata(filepath string, metadata Metadata, coverPath string) error {
+	f, err := flac.ParseFile(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to parse FLAC file: %w", err)
 	}
-	if metadata.TotalDiscs > 0 {
-		_ = cmt.Add("TOTALDISCS", strconv.Itoa(metadata.TotalDiscs))
+
+	mode := GetTagMergeMode()
+	mergeVorbisComment(f, metadata.vorbisFields(), mode)
+
+	if coverPath != "" && fileExists(coverPath) && !(mode == TagMergeFillMissing && flacHasPicture(f)) {
+		if err := embedCoverArt(f, coverPath); err != nil {
+			metadataLog.Warn("failed to embed cover art", "path", filepath, "error", err)
+		}
+	}
+
+	if err := f.Save(filepath); err != nil {
+		return fmt.Errorf("failed to save FLAC file: %w", err)
+	}
+
+	return nil
+}
+
+// vorbisFields returns the Vorbis comments SpotiFLAC manages for metadata
+func (m Metadata) vorbisFields() []tagField {
+	var fields []tagField
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, tagField{name, value})
+		}
 	}
-	if metadata.Copyright != "" {
-		_ = cmt.Add("COPYRIGHT", metadata.Copyright)
+	addNumber := func(name string, value int) {
+		if value > 0 {
+			add(name, strconv.Itoa(value))
+		}
 	}
-	if metadata.Publisher != "" {
-		_ = cmt.Add("PUBLISHER", metadata.Publisher)
+
+	addAll := func(name string, values []string, joined string) {
+		if len(values) == 0 {
+			add(name, joined)
+		}
+		for _, value := range values {
+			add(name, value)
+		}
 	}
-	if metadata.Description != "" {
-		_ = cmt.Add("DESCRIPTION", metadata.Description)
+
+	add(flacvorbis.FIELD_TITLE, m.Title)
+	addAll(flacvorbis.FIELD_ARTIST, m.Artists, m.Artist)
+	add(flacvorbis.FIELD_ALBUM, m.Album)
+	addAll("ALBUMARTIST", m.AlbumArtists, m.AlbumArtist)
+	addAll(flacvorbis.FIELD_GENRE, m.Genres, "")
+	add(flacvorbis.FIELD_DATE, m.Date)
+	addNumber(flacvorbis.FIELD_TRACKNUMBER, m.TrackNumber)
+	addNumber("TOTALTRACKS", m.TotalTracks)
+	addNumber("DISCNUMBER", m.DiscNumber)
+	addNumber("TOTALDISCS", m.TotalDiscs)
+	add("COPYRIGHT", m.Copyright)
+	add("PUBLISHER", m.Publisher)
+	add("DESCRIPTION", m.Description)
+	add("ISRC", m.ISRC)
+	add("LABEL", m.label())
+	fields = append(fields, m.Credits.fields()...)
+	fields = append(fields, m.extraFields()...)
+	if m.ReplayGain != nil {
+		fields = append(fields, m.ReplayGain.fields()...)
+	}
+	add("LYRICS", m.Lyrics)
+	return fields
+}
+
+// mergeVorbisComment replaces f's VORBIS_COMMENT block with its existing
+// comments merged with managed, or adds the block if there is none
+func mergeVorbisComment(f *flac.File, managed []tagField, mode TagMergeMode) {
+	cmtIdx := -1
+	var existing []tagField
+	for idx, block := range f.Meta {
+		if block.Type == flac.VorbisComment {
+			cmtIdx = idx
+			if cmt, err := flacvorbis.ParseFromMetaDataBlock(*block); err == nil {
+				existing = parseVorbisFields(cmt.Comments)
+			}
+			break
+		}
 	}
 
-	if metadata.Lyrics != "" {
-		_ = cmt.Add("LYRICS", metadata.Lyrics)
+	cmt := flacvorbis.New()
+	for _, field := range mergeTagFields(existing, managed, mode) {
+		_ = cmt.Add(field.name, field.value)
 	}
 
 	cmtBlock := cmt.Marshal()
@@ -96,18 +236,15 @@ func EmbedMetadata(filepath string, metadata Metadata, coverPath string) error {
 	} else {
 		f.Meta[cmtIdx] = &cmtBlock
 	}
+}
 
-	if coverPath != "" && fileExists(coverPath) {
-		if err := embedCoverArt(f, coverPath); err != nil {
-			fmt.Printf("Warning: Failed to embed cover art: %v\n", err)
+func flacHasPicture(f *flac.File) bool {
+	for _, block := range f.Meta {
+		if block.Type == flac.Picture {
+			return true
 		}
 	}
-
-	if err := f.Save(filepath); err != nil {
-		return fmt.Errorf("failed to save FLAC file: %w", err)
-	}
-
-	return nil
+	return false
 }
 
 func embedCoverArt(f *flac.File, coverPath string) error {
@@ -116,11 +253,16 @@ func embedCoverArt(f *flac.File, coverPath string) error {
 		return fmt.Errorf("failed to read cover image: %w", err)
 	}
 
+	return setFLACPicture(f, imgData)
+}
+
+// setFLACPicture replaces f's pictures with image as the front cover
+func setFLACPicture(f *flac.File, imgData []byte) error {
 	picture, err := flacpicture.NewFromImageData(
 		flacpicture.PictureTypeFrontCover,
 		"Cover",
 		imgData,
-		"image/jpeg",
+		coverMIMEType(imgData),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create picture block: %w", err)
@@ -139,6 +281,15 @@ func embedCoverArt(f *flac.File, coverPath string) error {
 	return nil
 }
 
+// coverMIMEType tells PNG covers from JPEG ones, the only two formats
+// players reliably show
+func coverMIMEType(image []byte) string {
+	if bytes.HasPrefix(image, []byte("\x89PNG")) {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -164,41 +315,17 @@ func EmbedLyricsOnly(filepath string, lyrics string) error {
 		return fmt.Errorf("failed to parse FLAC file: %w", err)
 	}
 
-	var cmtIdx = -1
-	var existingCmt *flacvorbis.MetaDataBlockVorbisComment
-	for idx, block := range f.Meta {
-		if block.Type == flac.VorbisComment {
-			cmtIdx = idx
-			existingCmt, err = flacvorbis.ParseFromMetaDataBlock(*block)
-			if err != nil {
-				existingCmt = nil
-			}
-			break
-		}
+	// Only the lyrics fields are managed here, so replace-all still keeps
+	// the other tags
+	mode := GetTagMergeMode()
+	if mode == TagMergeReplace {
+		mode = TagMergeOverwrite
 	}
-
-	cmt := flacvorbis.New()
-
-	if existingCmt != nil {
-		for _, comment := range existingCmt.Comments {
-			parts := strings.SplitN(comment, "=", 2)
-			if len(parts) == 2 {
-				fieldName := strings.ToUpper(parts[0])
-				if fieldName != "LYRICS" && fieldName != "UNSYNCEDLYRICS" && fieldName != "SYNCEDLYRICS" {
-					_ = cmt.Add(parts[0], parts[1])
-				}
-			}
-		}
-	}
-
-	_ = cmt.Add("LYRICS", lyrics)
-
-	cmtBlock := cmt.Marshal()
-	if cmtIdx < 0 {
-		f.Meta = append(f.Meta, &cmtBlock)
-	} else {
-		f.Meta[cmtIdx] = &cmtBlock
+	managed := []tagField{{"LYRICS", lyrics}}
+	if mode == TagMergeOverwrite {
+		managed = append(managed, tagField{"UNSYNCEDLYRICS", ""}, tagField{"SYNCEDLYRICS", ""})
 	}
+	mergeVorbisComment(f, managed, mode)
 
 	if err := f.Save(filepath); err != nil {
 		return fmt.Errorf("failed to save FLAC file: %w", err)
@@ -284,7 +411,26 @@ func extractCoverFromM4AOrFlac(filePath string) (string, error) {
 		return "", fmt.Errorf("no cover art found")
 	}
 
-	return "", nil
+	tags, err := readMP4Tags(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read M4A tags: %w", err)
+	}
+	if tags.cover == nil {
+		return "", fmt.Errorf("no cover art found")
+	}
+
+	tmpFile, err := os.CreateTemp("", "cover-*.jpg")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(tags.cover); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to write cover art: %w", err)
+	}
+
+	return tmpFile.Name(), nil
 }
 
 func ExtractLyrics(filePath string) (string, error) {
@@ -296,8 +442,11 @@ func ExtractLyrics(filePath string) (string, error) {
 	case ".flac":
 		return extractLyricsFromFlac(filePath)
 	case ".m4a":
-
-		return "", nil
+		tags, err := readMP4Tags(filePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read M4A tags: %w", err)
+		}
+		return strings.Join(tags.text["\xa9lyr"], "\n"), nil
 	default:
 		return "", fmt.Errorf("unsupported file format: %s", ext)
 	}
@@ -312,22 +461,22 @@ func extractLyricsFromMp3(filePath string) (string, error) {
 
 	usltFrames := tag.GetFrames(tag.CommonID("Unsynchronised lyrics/text transcription"))
 	if len(usltFrames) == 0 {
-		fmt.Printf("[ExtractLyrics] No USLT frames found in MP3: %s\n", filePath)
+		metadataLog.Debug("no USLT frames found", "path", filePath)
 		return "", nil
 	}
 
 	uslt, ok := usltFrames[0].(id3v2.UnsynchronisedLyricsFrame)
 	if !ok {
-		fmt.Printf("[ExtractLyrics] USLT frame type assertion failed in MP3: %s\n", filePath)
+		metadataLog.Debug("USLT frame type assertion failed", "path", filePath)
 		return "", nil
 	}
 
 	if uslt.Lyrics == "" {
-		fmt.Printf("[ExtractLyrics] USLT frame has empty lyrics in MP3: %s\n", filePath)
+		metadataLog.Debug("USLT frame has empty lyrics", "path", filePath)
 		return "", nil
 	}
 
-	fmt.Printf("[ExtractLyrics] Successfully extracted lyrics from MP3: %s (%d characters)\n", filePath, len(uslt.Lyrics))
+	metadataLog.Debug("extracted lyrics", "path", filePath, "chars", len(uslt.Lyrics))
 	return uslt.Lyrics, nil
 }
 
@@ -350,7 +499,7 @@ func extractLyricsFromFlac(filePath string) (string, error) {
 					fieldName := strings.ToUpper(parts[0])
 					if fieldName == "LYRICS" || fieldName == "UNSYNCEDLYRICS" {
 						lyrics := parts[1]
-						fmt.Printf("[ExtractLyrics] Successfully extracted lyrics from FLAC: %s (%d characters)\n", filePath, len(lyrics))
+						metadataLog.Debug("extracted lyrics", "path", filePath, "chars", len(lyrics))
 						return lyrics, nil
 					}
 				}
@@ -358,7 +507,7 @@ func extractLyricsFromFlac(filePath string) (string, error) {
 		}
 	}
 
-	fmt.Printf("[ExtractLyrics] No lyrics found in FLAC: %s\n", filePath)
+	metadataLog.Debug("no lyrics found", "path", filePath)
 	return "", nil
 }
 
@@ -373,13 +522,33 @@ func EmbedCoverArtOnly(filePath string, coverPath string) error {
 	case ".mp3":
 		return embedCoverToMp3(filePath, coverPath)
 	case ".m4a":
-
-		return nil
+		return embedCoverToM4A(filePath, coverPath)
 	default:
 		return fmt.Errorf("unsupported file format: %s", ext)
 	}
 }
 
+func embedCoverToM4A(filePath string, coverPath string) error {
+	if GetTagMergeMode() == TagMergeFillMissing {
+		existing, err := readMP4Tags(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read M4A tags: %w", err)
+		}
+		if existing.atoms["covr"] {
+			return nil
+		}
+	}
+
+	artwork, err := os.ReadFile(coverPath)
+	if err != nil {
+		return fmt.Errorf("failed to read cover art: %w", err)
+	}
+	if err := writeMP4Items(filePath, mp4ItemUpdate{cover: artwork}); err != nil {
+		return fmt.Errorf("failed to write MP4 items: %w", err)
+	}
+	return nil
+}
+
 func embedCoverToMp3(filePath string, coverPath string) error {
 	tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
 	if err != nil {
@@ -387,6 +556,9 @@ func embedCoverToMp3(filePath string, coverPath string) error {
 	}
 	defer tag.Close()
 
+	if GetTagMergeMode() == TagMergeFillMissing && tag.GetLastFrame(tag.CommonID("Attached picture")) != nil {
+		return nil
+	}
 	tag.DeleteFrames(tag.CommonID("Attached picture"))
 
 	artwork, err := os.ReadFile(coverPath)
@@ -417,7 +589,7 @@ func EmbedLyricsOnlyMP3(filepath string, lyrics string) error {
 
 	validatedLyrics, err := validateLyricsDuration(lyrics, filepath)
 	if err != nil {
-		fmt.Printf("[EmbedLyricsOnlyMP3] Warning: Failed to validate lyrics duration: %v, using original lyrics\n", err)
+		metadataLog.Warn("failed to validate lyrics duration, using original lyrics", "path", filepath, "error", err)
 		validatedLyrics = lyrics
 	}
 	lyrics = validatedLyrics
@@ -428,7 +600,11 @@ func EmbedLyricsOnlyMP3(filepath string, lyrics string) error {
 	}
 	defer tag.Close()
 
-	tag.DeleteFrames(tag.CommonID("Unsynchronised lyrics/text transcription"))
+	lyricsID := tag.CommonID("Unsynchronised lyrics/text transcription")
+	if GetTagMergeMode() == TagMergeFillMissing && tag.GetLastFrame(lyricsID) != nil {
+		return nil
+	}
+	tag.DeleteFrames(lyricsID)
 
 	usltFrame := id3v2.UnsynchronisedLyricsFrame{
 		Encoding:          id3v2.EncodingUTF8,
@@ -449,53 +625,24 @@ func embedLyricsToM4A(filepath string, lyrics string) error {
 
 	validatedLyrics, err := validateLyricsDuration(lyrics, filepath)
 	if err != nil {
-		fmt.Printf("[embedLyricsToM4A] Warning: Failed to validate lyrics duration: %v, using original lyrics\n", err)
+		metadataLog.Warn("failed to validate lyrics duration, using original lyrics", "path", filepath, "error", err)
 		validatedLyrics = lyrics
 	}
 	lyrics = validatedLyrics
 
-	ffmpegPath, err := GetFFmpegPath()
+	existing, err := readMP4Tags(filepath)
 	if err != nil {
-		return fmt.Errorf("ffmpeg not found: %w", err)
+		return fmt.Errorf("failed to read M4A tags: %w", err)
 	}
-
-	if err := ValidateExecutable(ffmpegPath); err != nil {
-		return fmt.Errorf("invalid ffmpeg executable: %w", err)
-	}
-
-	tmpOutputFile := strings.TrimSuffix(filepath, pathfilepath.Ext(filepath)) + ".tmp" + pathfilepath.Ext(filepath)
-	defer func() {
-
-		if _, err := os.Stat(tmpOutputFile); err == nil {
-			os.Remove(tmpOutputFile)
-		}
-	}()
-
-	cmd := exec.Command(ffmpegPath,
-		"-i", filepath,
-		"-map", "0",
-		"-map_metadata", "0",
-		"-metadata", "lyrics-eng="+lyrics,
-		"-metadata", "lyrics="+lyrics,
-		"-codec", "copy",
-		"-f", "ipod",
-		"-y",
-		tmpOutputFile,
-	)
-
-	setHideWindow(cmd)
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		fmt.Printf("[FFmpeg] Error embedding lyrics to M4A: %s\n", string(output))
-		return fmt.Errorf("ffmpeg failed to embed lyrics: %s - %w", string(output), err)
+	if GetTagMergeMode() == TagMergeFillMissing && existing.atoms["\xa9lyr"] {
+		return nil
 	}
 
-	if err := os.Rename(tmpOutputFile, filepath); err != nil {
-		return fmt.Errorf("failed to replace original file: %w", err)
+	if err := writeMP4Items(filepath, mp4ItemUpdate{text: map[string][]string{"\xa9lyr": {lyrics}}}); err != nil {
+		return fmt.Errorf("failed to write lyrics: %w", err)
 	}
 
-	fmt.Printf("[FFmpeg] Lyrics embedded to M4A successfully: %d characters\n", len(lyrics))
+	metadataLog.Debug("embedded lyrics", "path", filepath, "chars", len(lyrics))
 	return nil
 }
 
@@ -611,13 +758,13 @@ func validateLyricsDuration(lyrics string, filepath string) (string, error) {
 	duration, err := GetAudioDuration(filepath)
 	if err != nil {
 
-		fmt.Printf("[ValidateLyrics] Warning: Could not get audio duration: %v, skipping validation\n", err)
+		metadataLog.Warn("could not get audio duration, skipping lyrics validation", "path", filepath, "error", err)
 		return lyrics, nil
 	}
 
 	if duration <= 0 {
 
-		fmt.Printf("[ValidateLyrics] Warning: Invalid duration (%f seconds), skipping validation\n", duration)
+		metadataLog.Warn("invalid audio duration, skipping lyrics validation", "path", filepath, "duration", duration)
 		return lyrics, nil
 	}
 
@@ -651,7 +798,7 @@ func validateLyricsDuration(lyrics string, filepath string) (string, error) {
 					validLines = append(validLines, line)
 				} else {
 
-					fmt.Printf("[ValidateLyrics] Filtered out line with timestamp %s (exceeds duration %d ms): %s\n", timestampStr, durationMs, trimmedLine)
+					metadataLog.Debug("filtered out lyrics line past track end", "timestamp", timestampStr, "duration_ms", durationMs, "line", trimmedLine)
 				}
 			} else {
 
@@ -732,11 +879,15 @@ func ExtractFullMetadataFromFile(filePath string) (Metadata, error) {
 		case "title":
 			metadata.Title = value
 		case "artist":
-			metadata.Artist = value
+			metadata.Artists = splitMultiValue(value)
+			metadata.Artist = JoinMultiValue(metadata.Artists)
 		case "album":
 			metadata.Album = value
 		case "album_artist", "albumartist":
-			metadata.AlbumArtist = value
+			metadata.AlbumArtists = splitMultiValue(value)
+			metadata.AlbumArtist = JoinMultiValue(metadata.AlbumArtists)
+		case "genre":
+			metadata.Genres = splitMultiValue(value)
 		case "date", "year":
 			if metadata.Date == "" || len(value) > len(metadata.Date) {
 				metadata.Date = value
@@ -769,8 +920,42 @@ func ExtractFullMetadataFromFile(filePath string) (Metadata, error) {
 			}
 		case "copyright", "tcop":
 			metadata.Copyright = value
-		case "publisher", "tpub", "label":
+		case "publisher", "tpub":
 			metadata.Publisher = value
+		case "label":
+			metadata.Label = value
+		case "isrc", "tsrc":
+			metadata.ISRC = value
+		case "barcode", "upc":
+			metadata.UPC = value
+		case "itunesadvisory", "rating":
+			metadata.Explicit = value == "1"
+		case "spotify_track_id":
+			metadata.SpotifyID = value
+		case "spotify_album_id":
+			metadata.SpotifyAlbumID = value
+		case "source_service":
+			metadata.SourceService = value
+		case "source_track_id":
+			metadata.SourceTrackID = value
+		case "replaygain_track_gain", "replaygain_track_peak", "replaygain_album_gain", "replaygain_album_peak":
+			metadata.setReplayGainTag(key, value)
+		case "composer":
+			metadata.Credits.Composers = splitMultiValue(value)
+		case "lyricist":
+			metadata.Credits.Lyricists = splitMultiValue(value)
+		case "writer":
+			metadata.Credits.Writers = splitMultiValue(value)
+		case "producer":
+			metadata.Credits.Producers = splitMultiValue(value)
+		case "engineer":
+			metadata.Credits.Engineers = splitMultiValue(value)
+		case "mixer":
+			metadata.Credits.Mixers = splitMultiValue(value)
+		case "performer":
+			for _, performer := range splitMultiValue(value) {
+				metadata.Credits.Performers = append(metadata.Credits.Performers, parsePerformer(performer))
+			}
 		case "url":
 			metadata.URL = value
 		case "description", "comment":
@@ -783,6 +968,18 @@ func ExtractFullMetadataFromFile(filePath string) (Metadata, error) {
 	return metadata, nil
 }
 
+// splitMultiValue splits a tag value that ffprobe joined from repeated
+// fields or null-separated ID3v2.4 frames
+func splitMultiValue(value string) []string {
+	var values []string
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == 0 }) {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
 func EmbedMetadataToConvertedFile(filePath string, metadata Metadata, coverPath string) error {
 	ext := strings.ToLower(pathfilepath.Ext(filePath))
 
@@ -806,59 +1003,101 @@ func embedMetadataToMP3(filePath string, metadata Metadata, coverPath string) er
 	}
 	defer tag.Close()
 
-	tag.DeleteFrames("TXXX")
-
-	if metadata.Title != "" {
-		tag.SetTitle(metadata.Title)
+	mode := GetTagMergeMode()
+	if mode == TagMergeReplace {
+		tag.DeleteAllFrames()
 	}
-	if metadata.Artist != "" {
-		tag.SetArtist(metadata.Artist)
-	}
-	if metadata.Album != "" {
-		tag.SetAlbum(metadata.Album)
+
+	setText := func(id, value string) {
+		if value == "" || (mode == TagMergeFillMissing && tag.GetLastFrame(id) != nil) {
+			return
+		}
+		tag.DeleteFrames(id)
+		tag.AddTextFrame(id, id3v2.EncodingUTF8, value)
+	}
+
+	// ID3v2.4 separates multiple values with a null byte, v2.3 with "/"
+	multiValue := func(values []string, joined string) string {
+		switch {
+		case len(values) == 0:
+			return joined
+		case len(values) == 1:
+			return values[0]
+		case tag.Version() >= 4:
+			return strings.Join(values, "\x00")
+		default:
+			return strings.Join(values, "/")
+		}
 	}
+
+	setText(tag.CommonID("Title/Songname/Content description"), metadata.Title)
+	setText(tag.CommonID("Lead artist/Lead performer/Soloist/Performing group"), multiValue(metadata.Artists, metadata.Artist))
+	setText(tag.CommonID("Album/Movie/Show title"), metadata.Album)
+	setText(tag.CommonID("Content type"), multiValue(metadata.Genres, ""))
 	if metadata.Date != "" {
 		year := metadata.Date
 		if len(year) >= 4 {
 			year = year[:4]
 		}
-		tag.SetYear(year)
-	}
-
-	if metadata.AlbumArtist != "" {
-		tag.DeleteFrames("TPE2")
-		tag.AddTextFrame("TPE2", id3v2.EncodingUTF8, metadata.AlbumArtist)
+		setText(tag.CommonID("Year"), year)
 	}
+	setText("TPE2", multiValue(metadata.AlbumArtists, metadata.AlbumArtist))
 
 	if metadata.TrackNumber > 0 {
-		tag.DeleteFrames(tag.CommonID("Track number/Position in set"))
 		trackStr := strconv.Itoa(metadata.TrackNumber)
 		if metadata.TotalTracks > 0 {
 			trackStr = fmt.Sprintf("%d/%d", metadata.TrackNumber, metadata.TotalTracks)
 		}
-		tag.AddTextFrame(tag.CommonID("Track number/Position in set"), id3v2.EncodingUTF8, trackStr)
+		setText(tag.CommonID("Track number/Position in set"), trackStr)
 	}
 
 	if metadata.DiscNumber > 0 {
-		tag.DeleteFrames(tag.CommonID("Part of a set"))
 		discStr := strconv.Itoa(metadata.DiscNumber)
 		if metadata.TotalDiscs > 0 {
 			discStr = fmt.Sprintf("%d/%d", metadata.DiscNumber, metadata.TotalDiscs)
 		}
-		tag.AddTextFrame(tag.CommonID("Part of a set"), id3v2.EncodingUTF8, discStr)
+		setText(tag.CommonID("Part of a set"), discStr)
 	}
 
-	if metadata.Copyright != "" {
-		tag.DeleteFrames("TCOP")
-		tag.AddTextFrame("TCOP", id3v2.EncodingUTF8, metadata.Copyright)
+	setText("TCOP", metadata.Copyright)
+	setText("TPUB", metadata.label())
+	setText("TSRC", metadata.ISRC)
+
+	credits := metadata.Credits
+	setText("TCOM", multiValue(credits.Composers, ""))
+	setText("TEXT", multiValue(credits.Lyricists, ""))
+	// Role/name pairs; v2.3 only has IPLS, which holds both kinds
+	if tag.Version() >= 4 {
+		setText("TIPL", strings.Join(credits.involvedPeople(), "\x00"))
+		setText("TMCL", strings.Join(credits.musicians(), "\x00"))
+	} else {
+		setText("IPLS", strings.Join(append(credits.involvedPeople(), credits.musicians()...), "\x00"))
 	}
 
-	if metadata.Publisher != "" {
-		tag.DeleteFrames("TPUB")
-		tag.AddTextFrame("TPUB", id3v2.EncodingUTF8, metadata.Publisher)
+	var existing []tagField
+	for _, frame := range tag.GetFrames("TXXX") {
+		if udtf, ok := frame.(id3v2.UserDefinedTextFrame); ok {
+			existing = append(existing, tagField{udtf.Description, udtf.Value})
+		}
+	}
+	tag.DeleteFrames("TXXX")
+	managed := metadata.extraFields()
+	for _, writer := range credits.Writers {
+		managed = append(managed, tagField{"WRITER", writer})
+	}
+	if metadata.ReplayGain != nil {
+		managed = append(managed, metadata.ReplayGain.fields()...)
+	}
+	for _, field := range mergeTagFields(existing, managed, mode) {
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    id3v2.EncodingUTF8,
+			Description: field.name,
+			Value:       field.value,
+		})
 	}
 
-	if coverPath != "" && fileExists(coverPath) {
+	hasPicture := tag.GetLastFrame(tag.CommonID("Attached picture")) != nil
+	if coverPath != "" && fileExists(coverPath) && !(mode == TagMergeFillMissing && hasPicture) {
 
 		tag.DeleteFrames(tag.CommonID("Attached picture"))
 
@@ -873,7 +1112,7 @@ func embedMetadataToMP3(filePath string, metadata Metadata, coverPath string) er
 			}
 			tag.AddAttachedPicture(pic)
 		} else {
-			fmt.Printf("[EmbedMetadataToMP3] Warning: Failed to read cover art file: %v\n", err)
+			metadataLog.Warn("failed to read cover art file", "path", coverPath, "error", err)
 		}
 	}
 
@@ -885,83 +1124,57 @@ func embedMetadataToMP3(filePath string, metadata Metadata, coverPath string) er
 }
 
 func embedMetadataToM4A(filePath string, metadata Metadata, coverPath string) error {
-	ffmpegPath, err := GetFFmpegPath()
+	mode := GetTagMergeMode()
+	existing, err := readMP4Tags(filePath)
 	if err != nil {
-		return fmt.Errorf("ffmpeg not found: %w", err)
-	}
-
-	if err := ValidateExecutable(ffmpegPath); err != nil {
-		return fmt.Errorf("invalid ffmpeg executable: %w", err)
-	}
-
-	args := []string{
-		"-i", filePath,
-		"-y",
+		return fmt.Errorf("failed to read M4A tags: %w", err)
 	}
-
-	if coverPath != "" && fileExists(coverPath) {
-		args = append(args, "-i", coverPath)
-		args = append(args, "-map", "0:a", "-map", "1", "-c:a", "copy", "-c:v", "copy", "-disposition:v:0", "attached_pic")
-	} else {
-		args = append(args, "-map", "0", "-codec", "copy")
+	keep := func(atom string) bool {
+		return mode == TagMergeFillMissing && existing.atoms[atom]
 	}
 
-	if metadata.Title != "" {
-		args = append(args, "-metadata", "title="+metadata.Title)
-	}
-	if metadata.Artist != "" {
-		args = append(args, "-metadata", "artist="+metadata.Artist)
-	}
-	if metadata.Album != "" {
-		args = append(args, "-metadata", "album="+metadata.Album)
-	}
-	if metadata.AlbumArtist != "" {
-		args = append(args, "-metadata", "album_artist="+metadata.AlbumArtist)
-	}
-	if metadata.Date != "" {
-		args = append(args, "-metadata", "date="+metadata.Date)
+	update := mp4ItemUpdate{
+		text:     make(map[string][]string),
+		numbers:  make(map[string][2]int),
+		replace:  mode == TagMergeReplace,
+		explicit: metadata.Explicit && !keep("rtng"),
 	}
-	if metadata.TrackNumber > 0 {
-		trackStr := strconv.Itoa(metadata.TrackNumber)
-		if metadata.TotalTracks > 0 {
-			trackStr = fmt.Sprintf("%d/%d", metadata.TrackNumber, metadata.TotalTracks)
+	// Fields with a standard ilst item are written as one, with one data
+	// atom per value; the rest become freeform atoms
+	var fields []tagField
+	for _, field := range metadata.vorbisFields() {
+		if _, _, ok := numberPair(field.name); ok || field.name == "ITUNESADVISORY" {
+			continue
 		}
-		args = append(args, "-metadata", "track="+trackStr)
-	}
-	if metadata.DiscNumber > 0 {
-		discStr := strconv.Itoa(metadata.DiscNumber)
-		if metadata.TotalDiscs > 0 {
-			discStr = fmt.Sprintf("%d/%d", metadata.DiscNumber, metadata.TotalDiscs)
+		switch atom := mp4AtomFor(field.name); {
+		case atom == "":
+			fields = append(fields, field)
+		case !keep(atom):
+			update.text[atom] = append(update.text[atom], field.value)
 		}
-		args = append(args, "-metadata", "disk="+discStr)
 	}
-	if metadata.Copyright != "" {
-		args = append(args, "-metadata", "copyright="+metadata.Copyright)
+	for atom, pair := range map[string][2]int{
+		"trkn": {metadata.TrackNumber, metadata.TotalTracks},
+		"disk": {metadata.DiscNumber, metadata.TotalDiscs},
+	} {
+		if pair[0] > 0 && !keep(atom) {
+			update.numbers[atom] = pair
+		}
 	}
-	if metadata.Publisher != "" {
-		args = append(args, "-metadata", "publisher="+metadata.Publisher)
+	if mode == TagMergeReplace {
+		update.freeform = fields
+	} else {
+		update.freeform = mergeTagFields(existing.freeform, fields, mode)
 	}
 
-	tmpOutputFile := strings.TrimSuffix(filePath, pathfilepath.Ext(filePath)) + ".tmp" + pathfilepath.Ext(filePath)
-	defer func() {
-		if _, err := os.Stat(tmpOutputFile); err == nil {
-			os.Remove(tmpOutputFile)
+	if coverPath != "" && fileExists(coverPath) && !keep("covr") {
+		if update.cover, err = os.ReadFile(coverPath); err != nil {
+			metadataLog.Warn("failed to read cover art file", "path", coverPath, "error", err)
 		}
-	}()
-
-	args = append(args, "-f", "ipod", tmpOutputFile)
-
-	cmd := exec.Command(ffmpegPath, args...)
-	setHideWindow(cmd)
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("ffmpeg failed to embed metadata: %s - %w", string(output), err)
 	}
 
-	if err := os.Rename(tmpOutputFile, filePath); err != nil {
-		return fmt.Errorf("failed to replace original file: %w", err)
+	if err := writeMP4Items(filePath, update); err != nil {
+		return fmt.Errorf("failed to write MP4 items: %w", err)
 	}
-
 	return nil
 }
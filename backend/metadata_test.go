@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmbedCoverArtOnlyM4A(t *testing.T) {
+	oldCover := []byte("\xFF\xD8\xFFold cover")
+	newCover := []byte("\xFF\xD8\xFFnew cover")
+
+	tests := []struct {
+		name      string
+		mode      TagMergeMode
+		existing  []byte
+		wantCover []byte
+	}{
+		{name: "added", mode: TagMergeOverwrite, wantCover: newCover},
+		{name: "replaced", mode: TagMergeOverwrite, existing: oldCover, wantCover: newCover},
+		{name: "fill-missing adds", mode: TagMergeFillMissing, wantCover: newCover},
+		{name: "fill-missing keeps existing", mode: TagMergeFillMissing, existing: oldCover, wantCover: oldCover},
+	}
+
+	defer SetTagMergeMode(GetTagMergeMode())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTagMergeMode(tt.mode)
+			dir := t.TempDir()
+			path := filepath.Join(dir, "track.m4a")
+			var items [][]byte
+			if tt.existing != nil {
+				items = append(items, mp4CoverItem(tt.existing))
+			}
+			if err := os.WriteFile(path, buildTestMP4(false, items...), 0644); err != nil {
+				t.Fatal(err)
+			}
+			coverPath := filepath.Join(dir, "cover.jpg")
+			if err := os.WriteFile(coverPath, newCover, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := EmbedCoverArtOnly(path, coverPath); err != nil {
+				t.Fatalf("EmbedCoverArtOnly: %v", err)
+			}
+			tags, err := readMP4Tags(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(tags.cover, tt.wantCover) {
+				t.Errorf("cover = %q, want %q", tags.cover, tt.wantCover)
+			}
+		})
+	}
+}
+
+func TestEmbedMetadataToM4AReplacesFreeformInAnyCase(t *testing.T) {
+	defer SetTagMergeMode(GetTagMergeMode())
+	SetTagMergeMode(TagMergeOverwrite)
+
+	path := filepath.Join(t.TempDir(), "track.m4a")
+	existing := buildTestMP4(false, freeformAtom("isrc", "OLD000000001"), freeformAtom("Label", "Old Label"), freeformAtom("MOOD", "Calm"))
+	if err := os.WriteFile(path, existing, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := embedMetadataToM4A(path, Metadata{Title: "Title", ISRC: "NEW000000001", Label: "New Label"}, ""); err != nil {
+		t.Fatalf("embedMetadataToM4A: %v", err)
+	}
+	tags, err := readMP4Tags(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	for _, field := range tags.freeform {
+		counts[strings.ToUpper(field.name)]++
+		if strings.EqualFold(field.name, "ISRC") && field.value != "NEW000000001" {
+			t.Errorf("ISRC = %q, want NEW000000001", field.value)
+		}
+	}
+	for _, name := range []string{"ISRC", "LABEL", "MOOD"} {
+		if counts[name] != 1 {
+			t.Errorf("%d %s atoms, want 1 (%v)", counts[name], name, tags.freeform)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics are collected in memory by the download code paths and written in
+// the Prometheus text exposition format by WriteMetrics.
+
+type metricVec struct {
+	name    string
+	help    string
+	kind    string // counter or histogram
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*metricSeries
+}
+
+type metricSeries struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+func newCounter(name, help string, labels ...string) *metricVec {
+	m := &metricVec{name: name, help: help, kind: "counter", labels: labels, series: make(map[string]*metricSeries)}
+	if len(labels) == 0 {
+		// Unlabelled counters are reported as 0 before the first increment
+		m.get(nil)
+	}
+	return m
+}
+
+func newHistogram(name, help string, buckets []float64, labels ...string) *metricVec {
+	return &metricVec{name: name, help: help, kind: "histogram", labels: labels, buckets: buckets, series: make(map[string]*metricSeries)}
+}
+
+func (m *metricVec) get(labelValues []string) *metricSeries {
+	key := strings.Join(labelValues, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &metricSeries{labelValues: labelValues, counts: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+	return s
+}
+
+func (m *metricVec) add(v float64, labelValues ...string) {
+	m.mu.Lock()
+	m.get(labelValues).value += v
+	m.mu.Unlock()
+}
+
+func (m *metricVec) observe(v float64, labelValues ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.get(labelValues)
+	for i, bound := range m.buckets {
+		if v <= bound {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (m *metricVec) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := m.series[key]
+		labels := formatLabels(m.labels, s.labelValues)
+		if m.kind == "counter" {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, labels, formatFloat(s.value))
+			continue
+		}
+		bucketLabels := slices.Concat(m.labels, []string{"le"})
+		for i, bound := range m.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, formatLabels(bucketLabels, slices.Concat(s.labelValues, []string{formatFloat(bound)})), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, formatLabels(bucketLabels, slices.Concat(s.labelValues, []string{"+Inf"})), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", m.name, labels, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", m.name, labels, s.count)
+	}
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(values[i])
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	downloadsTotal = newCounter("spotiflac_downloads_total",
+		"Track downloads by service and outcome.", "service", "status")
+	downloadedBytes = newCounter("spotiflac_downloaded_bytes_total",
+		"Bytes written by downloads.")
+	mirrorRequests = newCounter("spotiflac_mirror_requests_total",
+		"Requests to download mirrors by result.", "service", "mirror", "result")
+	mirrorDuration = newHistogram("spotiflac_mirror_request_duration_seconds",
+		"Latency of download mirror requests.",
+		[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}, "service", "mirror")
+	songLinkWaits = newCounter("spotiflac_songlink_rate_limit_waits_total",
+		"Times a song.link request waited for the rate limit.")
+	songLinkWaitSeconds = newCounter("spotiflac_songlink_rate_limit_wait_seconds_total",
+		"Time spent waiting for the song.link rate limit.")
+	ffmpegDuration = newHistogram("spotiflac_ffmpeg_duration_seconds",
+		"Duration of ffmpeg conversions.",
+		[]float64{0.5, 1, 2.5, 5, 10, 30, 60, 120}, "operation", "result")
+
+	allMetrics = []*metricVec{downloadsTotal, downloadedBytes, mirrorRequests, mirrorDuration, songLinkWaits, songLinkWaitSeconds, ffmpegDuration}
+)
+
+// RecordDownload counts one finished download, once its retries are over
+func RecordDownload(service string, status DownloadStatus) {
+	downloadsTotal.add(1, service, string(status))
+}
+
+// observeMirror records the latency and result of a request to a mirror. The
+// mirror is labelled by host.
+func observeMirror(service, mirrorURL string, start time.Time, err error) {
+	mirror := mirrorURL
+	if u, parseErr := url.Parse(mirrorURL); parseErr == nil && u.Host != "" {
+		mirror = u.Host
+	}
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	mirrorRequests.add(1, service, mirror, result)
+	mirrorDuration.observe(time.Since(start).Seconds(), service, mirror)
+}
+
+func observeSongLinkWait(wait time.Duration) {
+	songLinkWaits.add(1)
+	songLinkWaitSeconds.add(wait.Seconds())
+}
+
+func observeFFmpeg(operation string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	ffmpegDuration.observe(time.Since(start).Seconds(), operation, result)
+}
+
+// WriteMetrics writes all metrics, plus the current queue depth, in the
+// Prometheus text format.
+func WriteMetrics(w io.Writer, appName string) {
+	for _, m := range allMetrics {
+		m.write(w)
+	}
+
+	sessionCounts := make(map[DownloadStatus]int)
+	downloadQueueLock.RLock()
+	for _, item := range downloadQueue {
+		sessionCounts[item.Status]++
+	}
+	downloadQueueLock.RUnlock()
+	writeStatusGauge(w, "spotiflac_session_queue_items", "Items in the current download session by status.", sessionCounts)
+
+	pendingCounts := make(map[DownloadStatus]int)
+	if tracks, err := GetPendingTracks(appName); err == nil {
+		for _, track := range tracks {
+			pendingCounts[track.Status]++
+		}
+	}
+	writeStatusGauge(w, "spotiflac_pending_tracks", "Tracks in the persistent download queue by status.", pendingCounts)
+}
+
+func writeStatusGauge(w io.Writer, name, help string, counts map[DownloadStatus]int) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	for _, status := range []DownloadStatus{StatusQueued, StatusDownloading, StatusCompleted, StatusFailed, StatusSkipped, StatusInterrupted} {
+		fmt.Fprintf(w, "%s{status=\"%s\"} %d\n", name, status, counts[status])
+	}
+}
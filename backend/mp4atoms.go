@@ -0,0 +1,458 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// mp4Box is a parsed MP4 box. start and end cover the whole box, body is
+// where its payload begins.
+type mp4Box struct {
+	typ        string
+	start, end int
+	body       int
+}
+
+func parseMP4Boxes(data []byte, start, end int) ([]mp4Box, error) {
+	var boxes []mp4Box
+	for pos := start; pos < end; {
+		if end-pos < 8 {
+			return nil, fmt.Errorf("truncated box header at offset %d", pos)
+		}
+		size := int(binary.BigEndian.Uint32(data[pos:]))
+		box := mp4Box{typ: string(data[pos+4 : pos+8]), start: pos, body: pos + 8}
+		switch size {
+		case 0:
+			size = end - pos
+		case 1:
+			if end-pos < 16 {
+				return nil, fmt.Errorf("truncated box header at offset %d", pos)
+			}
+			size = int(binary.BigEndian.Uint64(data[pos+8:]))
+			box.body = pos + 16
+		}
+		if size < box.body-pos || pos+size > end {
+			return nil, fmt.Errorf("invalid size for box %q at offset %d", box.typ, pos)
+		}
+		box.end = pos + size
+		boxes = append(boxes, box)
+		pos = box.end
+	}
+	return boxes, nil
+}
+
+func findMP4Box(boxes []mp4Box, typ string) (mp4Box, bool) {
+	for _, box := range boxes {
+		if box.typ == typ {
+			return box, true
+		}
+	}
+	return mp4Box{}, false
+}
+
+func makeMP4Box(typ string, payload ...[]byte) []byte {
+	body := slices.Concat(payload...)
+	box := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(box, uint32(8+len(body)))
+	copy(box[4:], typ)
+	return append(box, body...)
+}
+
+// setMP4Child returns box with its first child of type typ replaced by
+// child, or child appended when there is none. A nil child removes it.
+// skip is the number of payload bytes before the children, 4 for meta.
+func setMP4Child(box []byte, skip int, typ string, child []byte) ([]byte, error) {
+	outer, err := parseMP4Boxes(box, 0, len(box))
+	if err != nil || len(outer) != 1 {
+		return nil, fmt.Errorf("invalid %s box", typ)
+	}
+	bodyStart := outer[0].body + skip
+	children, err := parseMP4Boxes(box, bodyStart, len(box))
+	if err != nil {
+		return nil, err
+	}
+
+	payload := []byte{}
+	payload = append(payload, box[outer[0].body:bodyStart]...)
+	replaced := false
+	for _, c := range children {
+		if c.typ == typ && !replaced {
+			payload = append(payload, child...)
+			replaced = true
+			continue
+		}
+		payload = append(payload, box[c.start:c.end]...)
+	}
+	if !replaced {
+		payload = append(payload, child...)
+	}
+	return makeMP4Box(outer[0].typ, payload), nil
+}
+
+// mp4Child returns the bytes of the first child of type typ, or nil
+func mp4Child(box []byte, skip int, typ string) []byte {
+	outer, err := parseMP4Boxes(box, 0, len(box))
+	if err != nil || len(outer) != 1 {
+		return nil
+	}
+	children, err := parseMP4Boxes(box, outer[0].body+skip, len(box))
+	if err != nil {
+		return nil
+	}
+	if c, ok := findMP4Box(children, typ); ok {
+		return box[c.start:c.end]
+	}
+	return nil
+}
+
+// freeformAtom builds an iTunes "----:com.apple.iTunes:<name>" text atom
+func freeformAtom(name, value string) []byte {
+	return makeMP4Box("----",
+		makeMP4Box("mean", []byte{0, 0, 0, 0}, []byte("com.apple.iTunes")),
+		makeMP4Box("name", []byte{0, 0, 0, 0}, []byte(name)),
+		makeMP4Box("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte(value)),
+	)
+}
+
+// freeformName returns the name of a "----" atom
+func freeformName(atom []byte) string {
+	name := mp4Child(atom, 0, "name")
+	if len(name) < 12 {
+		return ""
+	}
+	return string(name[12:])
+}
+
+// mp4Tags describes the items already in an M4A file's ilst
+type mp4Tags struct {
+	atoms    map[string]bool
+	freeform []tagField
+	// text holds the values of the other UTF-8 items, numbers the number and
+	// total of trkn and disk
+	text    map[string][]string
+	numbers map[string][2]int
+	cover   []byte
+}
+
+// readMP4Tags reads the ilst item types and the text freeform atoms without
+// loading the audio data
+func readMP4Tags(filePath string) (mp4Tags, error) {
+	tags := mp4Tags{atoms: make(map[string]bool), text: make(map[string][]string), numbers: make(map[string][2]int)}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return tags, err
+	}
+	defer file.Close()
+
+	var moov []byte
+	header := make([]byte, 16)
+	for pos := int64(0); moov == nil; {
+		if _, err := file.ReadAt(header[:8], pos); err != nil {
+			if err == io.EOF {
+				return tags, fmt.Errorf("no moov box")
+			}
+			return tags, err
+		}
+		size := int64(binary.BigEndian.Uint32(header))
+		if size == 1 {
+			if _, err := file.ReadAt(header[8:16], pos+8); err != nil {
+				return tags, err
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:]))
+		}
+		if string(header[4:8]) == "moov" {
+			if size < 8 || size > 256<<20 {
+				return tags, fmt.Errorf("invalid moov box size %d", size)
+			}
+			moov = make([]byte, size)
+			if _, err := file.ReadAt(moov, pos); err != nil {
+				return tags, err
+			}
+			break
+		}
+		if size < 8 {
+			return tags, fmt.Errorf("no moov box")
+		}
+		pos += size
+	}
+
+	ilst := mp4Child(mp4Child(mp4Child(moov, 0, "udta"), 0, "meta"), 4, "ilst")
+	if ilst == nil {
+		return tags, nil
+	}
+	items, err := parseMP4Boxes(ilst, 8, len(ilst))
+	if err != nil {
+		return tags, err
+	}
+	for _, item := range items {
+		tags.atoms[item.typ] = true
+		atom := ilst[item.start:item.end]
+		if item.typ == "----" {
+			data := mp4Child(atom, 0, "data")
+			if name := freeformName(atom); name != "" && len(data) >= 16 && binary.BigEndian.Uint32(data[8:]) == 1 {
+				tags.freeform = append(tags.freeform, tagField{name, string(data[16:])})
+			}
+			continue
+		}
+
+		children, err := parseMP4Boxes(atom, 8, len(atom))
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			if child.typ != "data" || child.end-child.body < 8 {
+				continue
+			}
+			dataType := binary.BigEndian.Uint32(atom[child.body:])
+			payload := atom[child.body+8 : child.end]
+			switch {
+			case item.typ == "covr":
+				if tags.cover == nil {
+					tags.cover = payload
+				}
+			case (item.typ == "trkn" || item.typ == "disk") && len(payload) >= 6:
+				tags.numbers[item.typ] = [2]int{
+					int(binary.BigEndian.Uint16(payload[2:])),
+					int(binary.BigEndian.Uint16(payload[4:])),
+				}
+			case dataType == 1:
+				tags.text[item.typ] = append(tags.text[item.typ], string(payload))
+			}
+		}
+	}
+	return tags, nil
+}
+
+// mp4NumberItem builds a trkn or disk item. Only trkn has the two trailing
+// padding bytes.
+func mp4NumberItem(typ string, number, total int) []byte {
+	payload := make([]byte, 6, 8)
+	binary.BigEndian.PutUint16(payload[2:], uint16(number))
+	binary.BigEndian.PutUint16(payload[4:], uint16(total))
+	if typ == "trkn" {
+		payload = append(payload, 0, 0)
+	}
+	return makeMP4Box(typ, makeMP4Box("data", make([]byte, 8), payload))
+}
+
+// mp4CoverItem builds a covr item, typed as PNG or JPEG
+func mp4CoverItem(image []byte) []byte {
+	dataType := byte(13)
+	if coverMIMEType(image) == "image/png" {
+		dataType = 14
+	}
+	return makeMP4Box("covr", makeMP4Box("data", []byte{0, 0, 0, dataType, 0, 0, 0, 0}, image))
+}
+
+// mp4ItemUpdate is a set of changes to an M4A file's ilst
+type mp4ItemUpdate struct {
+	// freeform replaces "----" atoms of the same names, in any case
+	freeform []tagField
+	// clear removes "----" atoms without writing new ones
+	clear []string
+	// text replaces whole items with one UTF-8 data atom per value. An item
+	// without values is removed.
+	text map[string][]string
+	// numbers replaces trkn and disk items; a zero number removes the item
+	numbers map[string][2]int
+	// cover replaces the cover art when set
+	cover []byte
+	// explicit sets the iTunes advisory rating
+	explicit bool
+	// replace drops every item the update doesn't write
+	replace bool
+}
+
+// writeMP4Items applies update to the file's ilst, creating the udta, meta
+// and ilst boxes when they are missing
+func writeMP4Items(filePath string, update mp4ItemUpdate) error {
+	fields, explicit := update.freeform, update.explicit
+	if len(fields) == 0 && len(update.clear) == 0 && len(update.text) == 0 && len(update.numbers) == 0 && update.cover == nil && !explicit && !update.replace {
+		return nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	top, err := parseMP4Boxes(data, 0, len(data))
+	if err != nil {
+		return err
+	}
+	moovBox, ok := findMP4Box(top, "moov")
+	if !ok {
+		return fmt.Errorf("no moov box")
+	}
+	moov := data[moovBox.start:moovBox.end]
+
+	udta := mp4Child(moov, 0, "udta")
+	if udta == nil {
+		udta = makeMP4Box("udta")
+	}
+	meta := mp4Child(udta, 0, "meta")
+	if meta == nil {
+		hdlr := makeMP4Box("hdlr", make([]byte, 8), []byte("mdirappl"), make([]byte, 9))
+		meta = makeMP4Box("meta", make([]byte, 4), hdlr)
+	}
+	ilst := mp4Child(meta, 4, "ilst")
+	if ilst == nil {
+		ilst = makeMP4Box("ilst")
+	}
+
+	// Freeform names are matched case-insensitively, like Vorbis comments
+	names := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		names[strings.ToLower(field.name)] = true
+	}
+	for _, name := range update.clear {
+		names[strings.ToLower(name)] = true
+	}
+	items, err := parseMP4Boxes(ilst, 8, len(ilst))
+	if err != nil {
+		return err
+	}
+	if update.replace {
+		items = nil
+	}
+	var kept [][]byte
+	for _, item := range items {
+		atom := ilst[item.start:item.end]
+		if item.typ == "----" && names[strings.ToLower(freeformName(atom))] {
+			continue
+		}
+		if item.typ == "rtng" && explicit {
+			continue
+		}
+		if _, ok := update.text[item.typ]; ok {
+			continue
+		}
+		if _, ok := update.numbers[item.typ]; ok {
+			continue
+		}
+		if item.typ == "covr" && update.cover != nil {
+			continue
+		}
+		kept = append(kept, atom)
+	}
+	for _, typ := range slices.Sorted(maps.Keys(update.text)) {
+		var data [][]byte
+		for _, value := range update.text[typ] {
+			data = append(data, makeMP4Box("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte(value)))
+		}
+		if len(data) > 0 {
+			kept = append(kept, makeMP4Box(typ, data...))
+		}
+	}
+	for _, typ := range slices.Sorted(maps.Keys(update.numbers)) {
+		if pair := update.numbers[typ]; pair[0] > 0 {
+			kept = append(kept, mp4NumberItem(typ, pair[0], pair[1]))
+		}
+	}
+	if update.cover != nil {
+		kept = append(kept, mp4CoverItem(update.cover))
+	}
+	for _, field := range fields {
+		kept = append(kept, freeformAtom(field.name, field.value))
+	}
+	if explicit {
+		kept = append(kept, makeMP4Box("rtng", makeMP4Box("data", []byte{0, 0, 0, 21, 0, 0, 0, 0, 1})))
+	}
+	ilst = makeMP4Box("ilst", kept...)
+
+	if meta, err = setMP4Child(meta, 4, "ilst", ilst); err != nil {
+		return err
+	}
+	if udta, err = setMP4Child(udta, 0, "meta", meta); err != nil {
+		return err
+	}
+	newMoov, err := setMP4Child(moov, 0, "udta", udta)
+	if err != nil {
+		return err
+	}
+
+	// Chunk offsets point into mdat, which moves when moov comes first
+	if delta := len(newMoov) - len(moov); delta != 0 {
+		for _, box := range top {
+			if box.typ == "mdat" && box.start > moovBox.start {
+				if err := shiftMP4ChunkOffsets(newMoov, delta); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data) + len(newMoov) - len(moov))
+	out.Write(data[:moovBox.start])
+	out.Write(newMoov)
+	out.Write(data[moovBox.end:])
+
+	tmpFile := filePath + ".tags.tmp"
+	if err := os.WriteFile(tmpFile, out.Bytes(), info.Mode().Perm()); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	if err := os.Rename(tmpFile, filePath); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	return nil
+}
+
+// shiftMP4ChunkOffsets adds delta to every stco/co64 entry in moov, in place
+func shiftMP4ChunkOffsets(moov []byte, delta int) error {
+	var walk func(start, end int, path []string) error
+	walk = func(start, end int, path []string) error {
+		boxes, err := parseMP4Boxes(moov, start, end)
+		if err != nil {
+			return err
+		}
+		for _, box := range boxes {
+			switch {
+			case len(path) < 4 && box.typ == []string{"trak", "mdia", "minf", "stbl"}[len(path)]:
+				if err := walk(box.body, box.end, append(path, box.typ)); err != nil {
+					return err
+				}
+			case len(path) == 4 && (box.typ == "stco" || box.typ == "co64"):
+				width := 4
+				if box.typ == "co64" {
+					width = 8
+				}
+				if box.end-box.body < 8 {
+					return fmt.Errorf("truncated %s box", box.typ)
+				}
+				count := int(binary.BigEndian.Uint32(moov[box.body+4:]))
+				if box.body+8+count*width > box.end {
+					return fmt.Errorf("truncated %s box", box.typ)
+				}
+				for i := 0; i < count; i++ {
+					pos := box.body + 8 + i*width
+					if width == 4 {
+						binary.BigEndian.PutUint32(moov[pos:], uint32(int(binary.BigEndian.Uint32(moov[pos:]))+delta))
+					} else {
+						binary.BigEndian.PutUint64(moov[pos:], uint64(int64(binary.BigEndian.Uint64(moov[pos:]))+int64(delta)))
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	outer, err := parseMP4Boxes(moov, 0, len(moov))
+	if err != nil || len(outer) != 1 {
+		return fmt.Errorf("invalid moov box")
+	}
+	return walk(outer[0].body, len(moov), nil)
+}
@@ -0,0 +1,174 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testMP4Chunks = [][]byte{[]byte("CHUNK-A0"), []byte("CHUNK-B1"), []byte("CHUNK-C2")}
+
+// buildTestMP4 builds a file with one stco and one co64 track whose chunk
+// offsets point at testMP4Chunks in mdat
+func buildTestMP4(mdatFirst bool, items ...[]byte) []byte {
+	ftyp := makeMP4Box("ftyp", []byte("M4A \x00\x00\x00\x00M4A "))
+	mdat := makeMP4Box("mdat", bytes.Join(testMP4Chunks, nil))
+
+	buildMoov := func(mdatStart int) []byte {
+		offset := func(i int) int { return mdatStart + 8 + i*len(testMP4Chunks[0]) }
+		stco := []byte{0, 0, 0, 0, 0, 0, 0, 2}
+		stco = binary.BigEndian.AppendUint32(stco, uint32(offset(0)))
+		stco = binary.BigEndian.AppendUint32(stco, uint32(offset(2)))
+		co64 := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+		co64 = binary.BigEndian.AppendUint64(co64, uint64(offset(1)))
+
+		trak := func(table []byte) []byte {
+			return makeMP4Box("trak", makeMP4Box("mdia", makeMP4Box("minf", makeMP4Box("stbl", table))))
+		}
+		hdlr := makeMP4Box("hdlr", make([]byte, 8), []byte("mdirappl"), make([]byte, 9))
+		udta := makeMP4Box("udta", makeMP4Box("meta", make([]byte, 4), hdlr, makeMP4Box("ilst", items...)))
+		return makeMP4Box("moov", trak(makeMP4Box("stco", stco)), trak(makeMP4Box("co64", co64)), udta)
+	}
+
+	if mdatFirst {
+		return bytes.Join([][]byte{ftyp, mdat, buildMoov(len(ftyp))}, nil)
+	}
+	moov := buildMoov(0)
+	return bytes.Join([][]byte{ftyp, buildMoov(len(ftyp) + len(moov)), mdat}, nil)
+}
+
+// mp4ChunksAt returns the bytes each stco and co64 entry points at, in order
+func mp4ChunksAt(t *testing.T, data []byte) []string {
+	t.Helper()
+	top, err := parseMP4Boxes(data, 0, len(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	moovBox, ok := findMP4Box(top, "moov")
+	if !ok {
+		t.Fatal("no moov box")
+	}
+	moov := data[moovBox.start:moovBox.end]
+	traks, err := parseMP4Boxes(moov, 8, len(moov))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size := len(testMP4Chunks[0])
+	var chunks []string
+	at := func(pos int) string {
+		if pos < 0 || pos+size > len(data) {
+			return "out of range"
+		}
+		return string(data[pos : pos+size])
+	}
+	for _, trak := range traks {
+		if trak.typ != "trak" {
+			continue
+		}
+		stbl := mp4Child(mp4Child(mp4Child(moov[trak.start:trak.end], 0, "mdia"), 0, "minf"), 0, "stbl")
+		if table := mp4Child(stbl, 0, "stco"); table != nil {
+			for i := 0; i < int(binary.BigEndian.Uint32(table[12:])); i++ {
+				chunks = append(chunks, at(int(binary.BigEndian.Uint32(table[16+i*4:]))))
+			}
+		}
+		if table := mp4Child(stbl, 0, "co64"); table != nil {
+			for i := 0; i < int(binary.BigEndian.Uint32(table[12:])); i++ {
+				chunks = append(chunks, at(int(binary.BigEndian.Uint64(table[16+i*8:]))))
+			}
+		}
+	}
+	return chunks
+}
+
+func TestWriteMP4ItemsShiftsChunkOffsets(t *testing.T) {
+	large := makeMP4Box("\xa9cmt", makeMP4Box("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte(strings.Repeat("x", 500))))
+	want := []string{"CHUNK-A0", "CHUNK-C2", "CHUNK-B1"}
+
+	tests := []struct {
+		name      string
+		mdatFirst bool
+		items     [][]byte
+		update    mp4ItemUpdate
+		wantText  map[string][]string
+	}{
+		{
+			name:     "ilst grows",
+			update:   mp4ItemUpdate{text: map[string][]string{"\xa9nam": {strings.Repeat("Title ", 100)}}},
+			wantText: map[string][]string{"\xa9nam": {strings.Repeat("Title ", 100)}},
+		},
+		{
+			name:     "ilst shrinks",
+			items:    [][]byte{large},
+			update:   mp4ItemUpdate{text: map[string][]string{"\xa9nam": {"T"}}, replace: true},
+			wantText: map[string][]string{"\xa9nam": {"T"}},
+		},
+		{
+			name:      "mdat before moov",
+			mdatFirst: true,
+			update:    mp4ItemUpdate{text: map[string][]string{"\xa9ART": {"Artist"}}},
+			wantText:  map[string][]string{"\xa9ART": {"Artist"}},
+		},
+		{
+			name:     "cover added",
+			update:   mp4ItemUpdate{cover: bytes.Repeat([]byte{0xFF, 0xD8, 0xFF}, 4000)},
+			wantText: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "track.m4a")
+			original := buildTestMP4(tt.mdatFirst, tt.items...)
+			if got := mp4ChunksAt(t, original); !slices.Equal(got, want) {
+				t.Fatalf("fixture chunks = %q, want %q", got, want)
+			}
+			if err := os.WriteFile(path, original, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := writeMP4Items(path, tt.update); err != nil {
+				t.Fatalf("writeMP4Items: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) == len(original) {
+				t.Fatal("file size didn't change")
+			}
+			if got := mp4ChunksAt(t, data); !slices.Equal(got, want) {
+				t.Errorf("chunks = %q, want %q", got, want)
+			}
+
+			tags, err := readMP4Tags(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for typ, values := range tt.wantText {
+				if !slices.Equal(tags.text[typ], values) {
+					t.Errorf("%q = %q, want %q", typ, tags.text[typ], values)
+				}
+			}
+			if tt.update.cover != nil && !bytes.Equal(tags.cover, tt.update.cover) {
+				t.Error("cover not written")
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+			}
+			if _, err := os.Stat(path + ".tags.tmp"); !os.IsNotExist(err) {
+				t.Error("temp file left behind")
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package backend
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type PlaylistFileEntry struct {
+	Path       string
+	Title      string
+	Artist     string
+	Album      string
+	DurationMS int
+}
+
+type xspfPlaylist struct {
+	XMLName   xml.Name    `xml:"playlist"`
+	Version   string      `xml:"version,attr"`
+	Namespace string      `xml:"xmlns,attr"`
+	Title     string      `xml:"title,omitempty"`
+	Tracks    []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Album    string `xml:"album,omitempty"`
+	Duration int    `xml:"duration,omitempty"`
+}
+
+func PlaylistFilePath(dir, name, ext string) string {
+	return filepath.Join(dir, sanitizeFilename(name)+ext)
+}
+
+func playlistRelativePath(playlistDir, target string) string {
+	if rel, err := filepath.Rel(playlistDir, target); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(target)
+}
+
+func WriteM3U8(playlistPath string, entries []PlaylistFileEntry) error {
+	if err := os.MkdirAll(filepath.Dir(playlistPath), 0755); err != nil {
+		return fmt.Errorf("failed to create playlist directory: %w", err)
+	}
+
+	tmpPath := playlistPath + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create playlist file: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "#EXTM3U")
+
+	playlistDir := filepath.Dir(playlistPath)
+	for _, entry := range entries {
+		duration := -1
+		if entry.DurationMS > 0 {
+			duration = entry.DurationMS / 1000
+		}
+		fmt.Fprintf(w, "#EXTINF:%d,%s - %s\n", duration, entry.Artist, entry.Title)
+		fmt.Fprintln(w, playlistRelativePath(playlistDir, entry.Path))
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write playlist file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write playlist file: %w", err)
+	}
+
+	return os.Rename(tmpPath, playlistPath)
+}
+
+func WriteXSPF(playlistPath, title string, entries []PlaylistFileEntry) error {
+	if err := os.MkdirAll(filepath.Dir(playlistPath), 0755); err != nil {
+		return fmt.Errorf("failed to create playlist directory: %w", err)
+	}
+
+	playlist := xspfPlaylist{
+		Version:   "1",
+		Namespace: "http://xspf.org/ns/0/",
+		Title:     title,
+	}
+
+	playlistDir := filepath.Dir(playlistPath)
+	for _, entry := range entries {
+		location := (&url.URL{Path: playlistRelativePath(playlistDir, entry.Path)}).String()
+		playlist.Tracks = append(playlist.Tracks, xspfTrack{
+			Location: location,
+			Title:    entry.Title,
+			Creator:  entry.Artist,
+			Album:    entry.Album,
+			Duration: entry.DurationMS,
+		})
+	}
+
+	data, err := xml.MarshalIndent(playlist, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode playlist: %w", err)
+	}
+
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	tmpPath := playlistPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write playlist file: %w", err)
+	}
+	return os.Rename(tmpPath, playlistPath)
+}
+
+func WritePlaylistFile(playlistPath, title string, entries []PlaylistFileEntry) error {
+	switch strings.ToLower(filepath.Ext(playlistPath)) {
+	case ".m3u8", ".m3u":
+		return WriteM3U8(playlistPath, entries)
+	case ".xspf":
+		return WriteXSPF(playlistPath, title, entries)
+	default:
+		return fmt.Errorf("unsupported playlist format: %s (use .m3u8 or .xspf)", filepath.Ext(playlistPath))
+	}
+}
+
+func PlaylistEntriesFromDir(dir string) ([]PlaylistFileEntry, error) {
+	files, err := ListAudioFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type scanned struct {
+		entry PlaylistFileEntry
+		disc  int
+		track int
+	}
+
+	var items []scanned
+	for _, file := range files {
+		if strings.Contains(filepath.ToSlash(file.Path), "/"+syncTrashDir+"/") {
+			continue
+		}
+
+		item := scanned{entry: PlaylistFileEntry{Path: file.Path}}
+		if meta, err := ReadAudioMetadata(file.Path); err == nil {
+			item.entry.Title = meta.Title
+			item.entry.Artist = meta.Artist
+			item.entry.Album = meta.Album
+			item.disc = meta.DiscNumber
+			item.track = meta.TrackNumber
+		}
+		if item.entry.Title == "" {
+			item.entry.Title = strings.TrimSuffix(file.Name, filepath.Ext(file.Name))
+		}
+		if duration, err := GetAudioDuration(file.Path); err == nil {
+			item.entry.DurationMS = int(duration * 1000)
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.entry.Album != b.entry.Album {
+			return a.entry.Album < b.entry.Album
+		}
+		if a.disc != b.disc {
+			return a.disc < b.disc
+		}
+		if a.track != b.track {
+			return a.track < b.track
+		}
+		return a.entry.Path < b.entry.Path
+	})
+
+	entries := make([]PlaylistFileEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, item.entry)
+	}
+	return entries, nil
+}
+
+func PlaylistEntriesFromHistory(items []HistoryItem) []PlaylistFileEntry {
+	var entries []PlaylistFileEntry
+	seen := make(map[string]bool)
+
+	// History is newest first; playlists read better oldest first
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if item.Path == "" || seen[item.Path] || !fileExists(item.Path) {
+			continue
+		}
+		seen[item.Path] = true
+
+		entry := PlaylistFileEntry{
+			Path:   item.Path,
+			Title:  item.Title,
+			Artist: item.Artists,
+			Album:  item.Album,
+		}
+		if ms := parseDuration(item.DurationStr); ms > 0 {
+			entry.DurationMS = ms
+		} else if duration, err := GetAudioDuration(item.Path); err == nil {
+			entry.DurationMS = int(duration * 1000)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,156 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	syncStateFile = ".spotiflac-sync.json"
+	syncTrashDir  = ".trash"
+)
+
+type SyncedTrack struct {
+	SpotifyID  string `json:"spotify_id"`
+	Title      string `json:"title"`
+	Artists    string `json:"artists"`
+	Album      string `json:"album"`
+	DurationMS int    `json:"duration_ms"`
+	Path       string `json:"path,omitempty"`
+}
+
+type SyncState struct {
+	PlaylistID   string                 `json:"playlist_id"`
+	PlaylistName string                 `json:"playlist_name"`
+	LastSync     int64                  `json:"last_sync"`
+	Order        []string               `json:"order"`
+	Tracks       map[string]SyncedTrack `json:"tracks"`
+}
+
+type SyncDiff struct {
+	Added   []AlbumTrackMetadata
+	Removed []SyncedTrack
+	Kept    int
+}
+
+func LoadSyncState(dir string) (*SyncState, error) {
+	state := &SyncState{Tracks: make(map[string]SyncedTrack)}
+
+	data, err := os.ReadFile(filepath.Join(dir, syncStateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse sync state: %w", err)
+	}
+	if state.Tracks == nil {
+		state.Tracks = make(map[string]SyncedTrack)
+	}
+	return state, nil
+}
+
+func SaveSyncState(dir string, state *SyncState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, syncStateFile)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// DiffPlaylist compares the current playlist against the saved state. Tracks
+// whose file has gone missing from disk count as added again.
+func DiffPlaylist(state *SyncState, tracks []AlbumTrackMetadata) SyncDiff {
+	var diff SyncDiff
+	current := make(map[string]bool)
+
+	for _, track := range tracks {
+		if track.SpotifyID == "" || current[track.SpotifyID] {
+			continue
+		}
+		current[track.SpotifyID] = true
+
+		if synced, ok := state.Tracks[track.SpotifyID]; ok && synced.Path != "" && fileExists(synced.Path) {
+			diff.Kept++
+			continue
+		}
+		diff.Added = append(diff.Added, track)
+	}
+
+	for id, synced := range state.Tracks {
+		if !current[id] {
+			diff.Removed = append(diff.Removed, synced)
+		}
+	}
+
+	return diff
+}
+
+func (s *SyncState) SetOrder(tracks []AlbumTrackMetadata) {
+	s.Order = s.Order[:0]
+	seen := make(map[string]bool)
+	for _, track := range tracks {
+		if track.SpotifyID == "" || seen[track.SpotifyID] {
+			continue
+		}
+		seen[track.SpotifyID] = true
+		s.Order = append(s.Order, track.SpotifyID)
+	}
+	s.LastSync = time.Now().Unix()
+}
+
+func (s *SyncState) PlaylistEntries() []PlaylistFileEntry {
+	var entries []PlaylistFileEntry
+	for _, id := range s.Order {
+		track, ok := s.Tracks[id]
+		if !ok || track.Path == "" {
+			continue
+		}
+		entries = append(entries, PlaylistFileEntry{
+			Path:       track.Path,
+			Title:      track.Title,
+			Artist:     track.Artists,
+			Album:      track.Album,
+			DurationMS: track.DurationMS,
+		})
+	}
+	return entries
+}
+
+// MoveToTrash moves path into dir's trash folder. Files outside dir belong
+// to another folder or playlist and are left alone.
+func MoveToTrash(dir, path string) (string, error) {
+	if !IsWithinDir(dir, path) {
+		return "", fmt.Errorf("not moving %s to trash: it is outside %s", path, dir)
+	}
+	trashDir := filepath.Join(dir, syncTrashDir)
+	if err := os.MkdirAll(trashDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create trash folder: %w", err)
+	}
+
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	target := filepath.Join(trashDir, base)
+	for i := 1; fileExists(target); i++ {
+		target = filepath.Join(trashDir, fmt.Sprintf("%s (%d)%s", name, i, ext))
+	}
+
+	if err := os.Rename(path, target); err != nil {
+		return "", fmt.Errorf("failed to move %s to trash: %w", base, err)
+	}
+	return target, nil
+}
@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMoveToTrashStaysInDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "Playlist")
+	inside := filepath.Join(dir, "song.flac")
+	outside := filepath.Join(root, "Other", "song.flac")
+	for _, path := range []string{inside, outside} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("audio"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := MoveToTrash(dir, outside); err == nil {
+		t.Error("moved a file from outside the playlist folder")
+	}
+	if !fileExists(outside) {
+		t.Error("file outside the playlist folder is gone")
+	}
+
+	target, err := MoveToTrash(dir, inside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(inside) || !fileExists(target) || filepath.Dir(target) != filepath.Join(dir, syncTrashDir) {
+		t.Errorf("file not moved to the trash folder, target %s", target)
+	}
+}
@@ -1,9 +1,10 @@
 package backend
 
 import (
-	"fmt"
+	"context"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,6 +16,7 @@ const (
 	StatusCompleted   DownloadStatus = "completed"
 	StatusFailed      DownloadStatus = "failed"
 	StatusSkipped     DownloadStatus = "skipped"
+	StatusInterrupted DownloadStatus = "interrupted"
 )
 
 type DownloadItem struct {
@@ -33,6 +35,57 @@ type DownloadItem struct {
 	FilePath     string         `json:"file_path"`
 }
 
+// DownloadEvent names passed to the download event handler
+const (
+	EventQueued      = "queued"
+	EventStarted     = "started"
+	EventProgress    = "progress"
+	EventCompleted   = "completed"
+	EventFailed      = "failed"
+	EventSkipped     = "skipped"
+	EventInterrupted = "interrupted"
+)
+
+// DownloadEventHandler is called with a copy of the item after every queue
+// change. It must not block for long; progress events arrive every 256 KB.
+type DownloadEventHandler func(event string, item DownloadItem)
+
+var downloadEventHandler atomic.Pointer[DownloadEventHandler]
+
+func SetDownloadEventHandler(handler DownloadEventHandler) {
+	if handler == nil {
+		downloadEventHandler.Store(nil)
+		return
+	}
+	downloadEventHandler.Store(&handler)
+}
+
+func notifyDownloadEvent(event string, item DownloadItem) {
+	if handler := downloadEventHandler.Load(); handler != nil {
+		(*handler)(event, item)
+	}
+}
+
+// updateQueueItem applies update to the item with the given id and reports
+// event for it once the queue lock is released.
+func updateQueueItem(id, event string, update func(item *DownloadItem)) {
+	downloadQueueLock.Lock()
+	var updated *DownloadItem
+	for i := range downloadQueue {
+		if downloadQueue[i].ID == id {
+			update(&downloadQueue[i])
+			item := downloadQueue[i]
+			updated = &item
+			break
+		}
+	}
+	downloadQueueLock.Unlock()
+
+	if updated != nil {
+		notifyDownloadEvent(event, *updated)
+	}
+}
+
 var (
 	currentProgress     float64
 	currentProgressLock sync.RWMutex
@@ -123,10 +176,10 @@ type ProgressWriter struct {
 	itemID      string
 }
 
-func NewProgressWriter(writer io.Writer) *ProgressWriter {
+func NewProgressWriter(ctx context.Context, writer io.Writer) *ProgressWriter {
 	now := getCurrentTimeMillis()
 	return &ProgressWriter{
-		writer:      writer,
+		writer:      NewThrottledWriter(ctx, writer),
 		total:       0,
 		lastPrinted: 0,
 		startTime:   now,
@@ -136,8 +189,8 @@ func NewProgressWriter(writer io.Writer) *ProgressWriter {
 	}
 }
 
-func NewProgressWriterWithID(writer io.Writer, itemID string) *ProgressWriter {
-	pw := NewProgressWriter(writer)
+func NewProgressWriterWithID(ctx context.Context, writer io.Writer, itemID string) *ProgressWriter {
+	pw := NewProgressWriter(ctx, writer)
 	pw.itemID = itemID
 	return pw
 }
@@ -161,15 +214,19 @@ func (pw *ProgressWriter) Write(p []byte) (int, error) {
 		if timeDiff > 0 {
 			speedMBps = (bytesDiff / (1024 * 1024)) / timeDiff
 			SetDownloadSpeed(speedMBps)
-			fmt.Printf("\rDownloaded: %.2f MB (%.2f MB/s)", mbDownloaded, speedMBps)
+			progressf("\rDownloaded: %.2f MB (%.2f MB/s)", mbDownloaded, speedMBps)
 		} else {
-			fmt.Printf("\rDownloaded: %.2f MB", mbDownloaded)
+			progressf("\rDownloaded: %.2f MB", mbDownloaded)
 		}
 
 		SetDownloadProgress(mbDownloaded)
 
-		if pw.itemID != "" {
-			UpdateItemProgress(pw.itemID, mbDownloaded, speedMBps)
+		itemID := pw.itemID
+		if itemID == "" {
+			itemID = GetCurrentItemID()
+		}
+		if itemID != "" {
+			UpdateItemProgress(itemID, mbDownloaded, speedMBps)
 		}
 
 		pw.lastPrinted = pw.total
@@ -184,10 +241,106 @@ func (pw *ProgressWriter) GetTotal() int64 {
 	return pw.total
 }
 
-func AddToQueue(id, trackName, artistName, albumName, isrc string) {
-	downloadQueueLock.Lock()
-	defer downloadQueueLock.Unlock()
+// ThrottledWriter paces writes against a bandwidth budget shared by every
+// download in the process, so concurrent transfers split the limit, and
+// holds them while a pause window is active. Waiting stops when ctx is
+// cancelled.
+type ThrottledWriter struct {
+	ctx    context.Context
+	writer io.Writer
+}
+
+type rateLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+const throttleChunkSize = 32 * 1024
+
+var (
+	rateLimitBytes int64
+	rateLimitLock  sync.RWMutex
+	sharedLimiter  rateLimiter
+)
+
+func SetRateLimit(bytesPerSecond int64) {
+	rateLimitLock.Lock()
+	defer rateLimitLock.Unlock()
+	rateLimitBytes = bytesPerSecond
+}
+
+func GetRateLimit() int64 {
+	rateLimitLock.RLock()
+	defer rateLimitLock.RUnlock()
+	return rateLimitBytes
+}
+
+func NewThrottledWriter(ctx context.Context, writer io.Writer) *ThrottledWriter {
+	return &ThrottledWriter{ctx: ctx, writer: writer}
+}
+
+func (tw *ThrottledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > throttleChunkSize {
+			chunk = chunk[:throttleChunkSize]
+		}
+
+		// A download already running when a pause window starts stops too
+		if err := WaitForDownloadWindow(tw.ctx); err != nil {
+			return written, err
+		}
+		if limit := effectiveRateLimit(time.Now()); limit > 0 {
+			if delay := sharedLimiter.reserve(len(chunk), limit); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-tw.ctx.Done():
+					timer.Stop()
+					return written, tw.ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+
+		n, err := tw.writer.Write(chunk)
+		written += n
+		downloadedBytes.add(float64(n))
+		if err != nil {
+			return written, err
+		}
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// reserve takes n bytes from the bucket and returns how long the caller has
+// to wait before writing them. The bucket holds at most one second of budget.
+func (l *rateLimiter) reserve(n int, limit int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	burst := float64(limit)
+	if l.last.IsZero() {
+		l.tokens = burst
+	} else {
+		l.tokens += now.Sub(l.last).Seconds() * float64(limit)
+		if l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / float64(limit) * float64(time.Second))
+}
 
+func AddToQueue(id, trackName, artistName, albumName, isrc string) {
 	item := DownloadItem{
 		ID:         id,
 		TrackName:  trackName,
@@ -202,41 +355,58 @@ func AddToQueue(id, trackName, artistName, albumName, isrc string) {
 		EndTime:    0,
 	}
 
+	downloadQueueLock.Lock()
 	downloadQueue = append(downloadQueue, item)
+	downloadQueueLock.Unlock()
 
 	sessionStartLock.Lock()
 	if sessionStartTime == 0 {
 		sessionStartTime = time.Now().Unix()
 	}
 	sessionStartLock.Unlock()
+
+	notifyDownloadEvent(EventQueued, item)
 }
 
 func StartDownloadItem(id string) {
-	downloadQueueLock.Lock()
-	defer downloadQueueLock.Unlock()
-
-	for i := range downloadQueue {
-		if downloadQueue[i].ID == id {
-			downloadQueue[i].Status = StatusDownloading
-			downloadQueue[i].StartTime = time.Now().Unix()
-			downloadQueue[i].Progress = 0
-			break
-		}
-	}
-
 	currentItemLock.Lock()
 	currentItemID = id
 	currentItemLock.Unlock()
+
+	updateQueueItem(id, EventStarted, func(item *DownloadItem) {
+		item.Status = StatusDownloading
+		item.StartTime = time.Now().Unix()
+		item.Progress = 0
+	})
 }
 
 func UpdateItemProgress(id string, progress, speed float64) {
+	updateQueueItem(id, EventProgress, func(item *DownloadItem) {
+		item.Progress = progress
+		item.Speed = speed
+	})
+}
+
+// UpdateItemInfo fills in track details that were unknown when the item was
+// queued, e.g. after metadata has been fetched.
+func UpdateItemInfo(id, trackName, artistName, albumName, isrc string) {
 	downloadQueueLock.Lock()
 	defer downloadQueueLock.Unlock()
 
 	for i := range downloadQueue {
 		if downloadQueue[i].ID == id {
-			downloadQueue[i].Progress = progress
-			downloadQueue[i].Speed = speed
+			if trackName != "" {
+				downloadQueue[i].TrackName = trackName
+			}
+			if artistName != "" {
+				downloadQueue[i].ArtistName = artistName
+			}
+			if albumName != "" {
+				downloadQueue[i].AlbumName = albumName
+			}
+			if isrc != "" {
+				downloadQueue[i].ISRC = isrc
+			}
 			break
 		}
 	}
@@ -249,51 +419,43 @@ func GetCurrentItemID() string {
 }
 
 func CompleteDownloadItem(id, filePath string, finalSize float64) {
-	downloadQueueLock.Lock()
-	defer downloadQueueLock.Unlock()
-
-	for i := range downloadQueue {
-		if downloadQueue[i].ID == id {
-			downloadQueue[i].Status = StatusCompleted
-			downloadQueue[i].EndTime = time.Now().Unix()
-			downloadQueue[i].FilePath = filePath
-			downloadQueue[i].Progress = finalSize
-			downloadQueue[i].TotalSize = finalSize
+	updateQueueItem(id, EventCompleted, func(item *DownloadItem) {
+		item.Status = StatusCompleted
+		item.EndTime = time.Now().Unix()
+		item.FilePath = filePath
+		item.Progress = finalSize
+		item.TotalSize = finalSize
 
-			totalDownloadedLock.Lock()
-			totalDownloaded += finalSize
-			totalDownloadedLock.Unlock()
-			break
-		}
-	}
+		totalDownloadedLock.Lock()
+		totalDownloaded += finalSize
+		totalDownloadedLock.Unlock()
+	})
 }
 
 func FailDownloadItem(id, errorMsg string) {
-	downloadQueueLock.Lock()
-	defer downloadQueueLock.Unlock()
+	updateQueueItem(id, EventFailed, func(item *DownloadItem) {
+		item.Status = StatusFailed
+		item.EndTime = time.Now().Unix()
+		item.ErrorMessage = errorMsg
+	})
+}
 
-	for i := range downloadQueue {
-		if downloadQueue[i].ID == id {
-			downloadQueue[i].Status = StatusFailed
-			downloadQueue[i].EndTime = time.Now().Unix()
-			downloadQueue[i].ErrorMessage = errorMsg
-			break
-		}
-	}
+// InterruptDownloadItem marks an item whose download was stopped by the user
+// (e.g. Ctrl+C) rather than by an error.
+func InterruptDownloadItem(id string) {
+	updateQueueItem(id, EventInterrupted, func(item *DownloadItem) {
+		item.Status = StatusInterrupted
+		item.EndTime = time.Now().Unix()
+		item.ErrorMessage = "Interrupted"
+	})
 }
 
 func SkipDownloadItem(id, filePath string) {
-	downloadQueueLock.Lock()
-	defer downloadQueueLock.Unlock()
-
-	for i := range downloadQueue {
-		if downloadQueue[i].ID == id {
-			downloadQueue[i].Status = StatusSkipped
-			downloadQueue[i].EndTime = time.Now().Unix()
-			downloadQueue[i].FilePath = filePath
-			break
-		}
-	}
+	updateQueueItem(id, EventSkipped, func(item *DownloadItem) {
+		item.Status = StatusSkipped
+		item.EndTime = time.Now().Unix()
+		item.FilePath = filePath
+	})
 }
 
 func GetDownloadQueue() DownloadQueueInfo {
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestThrottledWriterStopsOnCancel(t *testing.T) {
+	SetRateLimit(1024)
+	defer SetRateLimit(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out bytes.Buffer
+	writer := NewThrottledWriter(ctx, &out)
+	// Drain the bucket so the next write has to wait
+	if _, err := writer.Write(make([]byte, 1024)); err != nil {
+		t.Fatal(err)
+	}
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := writer.Write(make([]byte, 64*1024))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("write returned %v after cancel", elapsed)
+	}
+}
+
+func TestThrottledWriterHoldsDuringPause(t *testing.T) {
+	now := time.Now()
+	minute := now.Hour()*60 + now.Minute()
+	SetRateWindows([]RateWindow{{Start: minute, End: (minute + 2) % (24 * 60), Pause: true}})
+	defer SetRateWindows(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out bytes.Buffer
+	writer := NewThrottledWriter(ctx, &out)
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	n, err := writer.Write([]byte("data"))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if n != 0 || out.Len() != 0 {
+		t.Errorf("wrote %d bytes during a pause window", out.Len())
+	}
+}
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +18,9 @@ import (
 type QobuzDownloader struct {
 	client *http.Client
 	appID  string
+
+	ExistingFiles ExistingFileAction
+	Release       ReleaseTags
 }
 
 type QobuzSearchResponse struct {
@@ -42,6 +47,7 @@ type QobuzTrack struct {
 	Hires               bool    `json:"hires"`
 	HiresStreamable     bool    `json:"hires_streamable"`
 	ReleaseDateOriginal string  `json:"release_date_original"`
+	ParentalWarning     bool    `json:"parental_warning"`
 	Performer           struct {
 		Name string `json:"name"`
 		ID   int64  `json:"id"`
@@ -49,6 +55,7 @@ type QobuzTrack struct {
 	Album struct {
 		Title string `json:"title"`
 		ID    string `json:"id"`
+		UPC   string `json:"upc"`
 		Image struct {
 			Small     string `json:"small"`
 			Thumbnail string `json:"thumbnail"`
@@ -70,26 +77,24 @@ type QobuzStreamResponse struct {
 
 func NewQobuzDownloader() *QobuzDownloader {
 	return &QobuzDownloader{
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
-		appID: "798273057",
+		client: newHTTPClient("qobuz", 60*time.Second),
+		appID:  "798273057",
 	}
 }
 
-func (q *QobuzDownloader) SearchByISRC(isrc string) (*QobuzTrack, error) {
+func (q *QobuzDownloader) SearchByISRC(ctx context.Context, isrc string) (*QobuzTrack, error) {
 
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly93d3cucW9idXouY29tL2FwaS5qc29uLzAuMi90cmFjay9zZWFyY2g/cXVlcnk9")
 	url := fmt.Sprintf("%s%s&limit=1&app_id=%s", string(apiBase), isrc, q.appID)
 
-	resp, err := q.client.Get(url)
+	resp, err := httpGet(ctx, q.client, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search track: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		return nil, statusError("qobuz", resp)
 	}
 
 	var searchResp QobuzSearchResponse
@@ -113,37 +118,38 @@ func (q *QobuzDownloader) SearchByISRC(isrc string) (*QobuzTrack, error) {
 	}
 
 	if len(searchResp.Tracks.Items) == 0 {
-		return nil, fmt.Errorf("track not found for ISRC: %s", isrc)
+		return nil, newServiceError("qobuz", ErrNotFound, "track not found for ISRC "+isrc, nil)
 	}
 
 	return &searchResp.Tracks.Items[0], nil
 }
 
-func (q *QobuzDownloader) GetDownloadURL(trackID int64, quality string) (string, error) {
+func (q *QobuzDownloader) GetDownloadURL(ctx context.Context, trackID int64, quality string) (string, error) {
 
 	qualityCode := quality
 	if qualityCode == "" {
 		qualityCode = "6"
 	}
 
-	fmt.Printf("Getting download URL for track ID: %d with requested quality: %s\n", trackID, qualityCode)
-	fmt.Printf("Quality codes: 6=FLAC 16-bit, 7=FLAC 24-bit\n")
+	qobuzLog.Info("getting download URL", "track_id", trackID, "quality", qualityCode)
 
 	primaryBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9kYWIueWVldC5zdS9hcGkvc3RyZWFtP3RyYWNrSWQ9")
 
 	primaryURL := fmt.Sprintf("%s%d&quality=%s", string(primaryBase), trackID, qualityCode)
-	fmt.Printf("Trying Primary API: %s\n", primaryURL)
+	qobuzLog.Debug("trying API", "api", "primary", "url", primaryURL)
 
-	resp, err := q.client.Get(primaryURL)
+	start := time.Now()
+	resp, err := httpGet(ctx, q.client, primaryURL)
+	observeMirror("qobuz", primaryURL, start, responseError("qobuz", resp, err))
 	if err == nil && resp.StatusCode == 200 {
 		defer resp.Body.Close()
 
 		body, _ := io.ReadAll(resp.Body)
-		fmt.Printf("Primary API response: %s\n", string(body))
+		qobuzLog.Debug("API response", "api", "primary", "body", string(body))
 
 		var streamResp QobuzStreamResponse
 		if err := json.Unmarshal(body, &streamResp); err == nil && streamResp.URL != "" {
-			fmt.Printf("✓ Got download URL from Primary API\n")
+			qobuzLog.Info("got download URL", "api", "primary")
 			return streamResp.URL, nil
 		}
 	}
@@ -151,21 +157,23 @@ func (q *QobuzDownloader) GetDownloadURL(trackID int64, quality string) (string,
 		resp.Body.Close()
 	}
 
-	fmt.Println("Primary API failed, trying Fallback API #1...")
+	qobuzLog.Info("API failed, trying fallback", "api", "primary")
 	fallbackBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9kYWJtdXNpYy54eXovYXBpL3N0cmVhbT90cmFja0lkPQ==")
 	fallbackURL := fmt.Sprintf("%s%d&quality=%s", string(fallbackBase), trackID, qualityCode)
 
-	resp, err = q.client.Get(fallbackURL)
+	start = time.Now()
+	resp, err = httpGet(ctx, q.client, fallbackURL)
+	observeMirror("qobuz", fallbackURL, start, responseError("qobuz", resp, err))
 	if err == nil && resp.StatusCode == 200 {
 		defer resp.Body.Close()
 
 		body, err := io.ReadAll(resp.Body)
 		if err == nil && len(body) > 0 {
-			fmt.Printf("Fallback API #1 response: %s\n", string(body))
+			qobuzLog.Debug("API response", "api", "fallback-1", "body", string(body))
 
 			var streamResp QobuzStreamResponse
 			if err := json.Unmarshal(body, &streamResp); err == nil && streamResp.URL != "" {
-				fmt.Printf("✓ Got download URL from Fallback API #1\n")
+				qobuzLog.Info("got download URL", "api", "fallback-1")
 				return streamResp.URL, nil
 			}
 		}
@@ -174,20 +182,22 @@ func (q *QobuzDownloader) GetDownloadURL(trackID int64, quality string) (string,
 		resp.Body.Close()
 	}
 
-	fmt.Println("Fallback API #1 failed, trying Fallback API #2...")
+	qobuzLog.Info("API failed, trying fallback", "api", "fallback-1")
 	fallback2Base, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9xb2J1ei5zcXVpZC53dGYvYXBpL2Rvd25sb2FkLW11c2ljP3RyYWNrX2lkPQ==")
 	fallback2URL := fmt.Sprintf("%s%d&quality=%s", string(fallback2Base), trackID, qualityCode)
 
-	resp, err = q.client.Get(fallback2URL)
+	start = time.Now()
+	resp, err = httpGet(ctx, q.client, fallback2URL)
+	observeMirror("qobuz", fallback2URL, start, responseError("qobuz", resp, err))
 	if err != nil {
-		return "", fmt.Errorf("all APIs failed to get download URL: %w", err)
+		return "", newServiceError("qobuz", ErrUpstreamDown, "all APIs failed to get download URL", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		fmt.Printf("Fallback API #2 error response (status %d): %s\n", resp.StatusCode, string(body))
-		return "", fmt.Errorf("all APIs returned non-200 status")
+		qobuzLog.Debug("API error response", "api", "fallback-2", "status", resp.StatusCode, "body", string(body))
+		return "", newServiceError("qobuz", ErrUpstreamDown, "all APIs returned non-200 status", statusError("qobuz", resp))
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -199,7 +209,7 @@ func (q *QobuzDownloader) GetDownloadURL(trackID int64, quality string) (string,
 		return "", fmt.Errorf("API returned empty response")
 	}
 
-	fmt.Printf("Fallback API #2 response: %s\n", string(body))
+	qobuzLog.Debug("API response", "api", "fallback-2", "body", string(body))
 
 	var streamResp QobuzStreamResponse
 	if err := json.Unmarshal(body, &streamResp); err != nil {
@@ -212,62 +222,58 @@ func (q *QobuzDownloader) GetDownloadURL(trackID int64, quality string) (string,
 	}
 
 	if streamResp.URL == "" {
-		return "", fmt.Errorf("no download URL available from any API")
+		return "", newServiceError("qobuz", ErrUpstreamDown, "no download URL available from any API", nil)
 	}
 
-	fmt.Printf("✓ Got download URL from Fallback API #2\n")
+	qobuzLog.Info("got download URL", "api", "fallback-2")
 	return streamResp.URL, nil
 }
 
-func (q *QobuzDownloader) DownloadFile(url, filepath string) error {
-	fmt.Println("Starting file download...")
-
-	downloadClient := &http.Client{
-		Timeout: 5 * time.Minute,
-	}
+func (q *QobuzDownloader) DownloadFile(ctx context.Context, url, filepath string) error {
+	downloadClient := newHTTPClient("qobuz-download", 5*time.Minute)
 
-	resp, err := downloadClient.Get(url)
+	resp, err := httpGet(ctx, downloadClient, url)
 	if err != nil {
 		return fmt.Errorf("failed to download file: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed with status %d", resp.StatusCode)
+		return fmt.Errorf("download failed: %w", statusError("qobuz", resp))
 	}
 
-	fmt.Printf("Creating file: %s\n", filepath)
+	qobuzLog.Debug("creating file", "path", filepath)
 	out, err := os.Create(filepath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
 	defer out.Close()
 
-	fmt.Println("Downloading...")
-
-	pw := NewProgressWriter(out)
+	pw := NewProgressWriter(ctx, out)
 	_, err = io.Copy(pw, resp.Body)
 	if err != nil {
+		out.Close()
+		os.Remove(filepath)
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
-	fmt.Printf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
+	progressf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
 	return nil
 }
 
-func (q *QobuzDownloader) DownloadCoverArt(coverURL, filepath string) error {
+func (q *QobuzDownloader) DownloadCoverArt(ctx context.Context, coverURL, filepath string) error {
 	if coverURL == "" {
 		return fmt.Errorf("no cover URL provided")
 	}
 
-	resp, err := q.client.Get(coverURL)
+	resp, err := httpGet(ctx, q.client, coverURL)
 	if err != nil {
 		return fmt.Errorf("failed to download cover: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("cover download failed with status %d", resp.StatusCode)
+		return fmt.Errorf("cover download failed: %w", statusError("qobuz", resp))
 	}
 
 	out, err := os.Create(filepath)
@@ -276,8 +282,12 @@ func (q *QobuzDownloader) DownloadCoverArt(coverURL, filepath string) error {
 	}
 	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
-	return err
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		os.Remove(filepath)
+		return err
+	}
+	return nil
 }
 
 func buildQobuzFilename(title, artist, album, albumArtist, releaseDate string, trackNumber, discNumber int, format string, includeTrackNumber bool, position int, useAlbumTrackNumber bool) string {
@@ -334,8 +344,8 @@ func buildQobuzFilename(title, artist, album, albumArtist, releaseDate string, t
 	return filename + ".flac"
 }
 
-func (q *QobuzDownloader) DownloadByISRC(deezerISRC, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate string, useAlbumTrackNumber bool, spotifyCoverURL string, embedMaxQualityCover bool, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
-	fmt.Printf("Fetching track info for ISRC: %s\n", deezerISRC)
+func (q *QobuzDownloader) DownloadByISRC(ctx context.Context, deezerISRC, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate string, useAlbumTrackNumber bool, spotifyCoverURL string, embedMaxQualityCover bool, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
+	qobuzLog.Info("fetching track info", "isrc", deezerISRC)
 
 	if outputDir != "." {
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -343,7 +353,7 @@ func (q *QobuzDownloader) DownloadByISRC(deezerISRC, outputDir, quality, filenam
 		}
 	}
 
-	track, err := q.SearchByISRC(deezerISRC)
+	track, err := q.SearchByISRC(ctx, deezerISRC)
 	if err != nil {
 		return "", err
 	}
@@ -352,17 +362,15 @@ func (q *QobuzDownloader) DownloadByISRC(deezerISRC, outputDir, quality, filenam
 	trackTitle := spotifyTrackName
 	albumTitle := spotifyAlbumName
 
-	fmt.Printf("Found track: %s - %s\n", artists, trackTitle)
-	fmt.Printf("Album: %s\n", albumTitle)
+	qobuzLog.Info("found track", "artist", artists, "title", trackTitle, "album", albumTitle)
 
 	qualityInfo := "Standard"
 	if track.Hires {
 		qualityInfo = fmt.Sprintf("Hi-Res (%d-bit / %.1f kHz)", track.MaximumBitDepth, track.MaximumSamplingRate)
 	}
-	fmt.Printf("Quality: %s\n", qualityInfo)
+	qobuzLog.Info("track quality", "quality", qualityInfo)
 
-	fmt.Println("Getting download URL...")
-	downloadURL, err := q.GetDownloadURL(track.ID, quality)
+	downloadURL, err := q.GetDownloadURL(ctx, track.ID, quality)
 	if err != nil {
 		return "", fmt.Errorf("failed to get download URL: %w", err)
 	}
@@ -375,7 +383,7 @@ func (q *QobuzDownloader) DownloadByISRC(deezerISRC, outputDir, quality, filenam
 	if len(downloadURL) > 60 {
 		urlPreview = downloadURL[:60] + "..."
 	}
-	fmt.Printf("Download URL obtained: %s\n", urlPreview)
+	qobuzLog.Debug("download URL obtained", "url", urlPreview)
 
 	safeArtist := sanitizeFilename(artists)
 	safeTitle := sanitizeFilename(trackTitle)
@@ -383,36 +391,33 @@ func (q *QobuzDownloader) DownloadByISRC(deezerISRC, outputDir, quality, filenam
 	safeAlbumArtist := sanitizeFilename(spotifyAlbumArtist)
 
 	filename := buildQobuzFilename(safeTitle, safeArtist, safeAlbum, safeAlbumArtist, spotifyReleaseDate, spotifyTrackNumber, spotifyDiscNumber, filenameFormat, includeTrackNumber, position, useAlbumTrackNumber)
-	filepath := filepath.Join(outputDir, filename)
+	filepath, exists := resolveOutputPath(filepath.Join(outputDir, filename), q.ExistingFiles)
 
-	if fileInfo, err := os.Stat(filepath); err == nil && fileInfo.Size() > 0 {
-		fmt.Printf("File already exists: %s (%.2f MB)\n", filepath, float64(fileInfo.Size())/(1024*1024))
+	if exists {
+		fileInfo, _ := os.Stat(filepath)
+		qobuzLog.Info("file already exists", "path", filepath, "size_mb", float64(fileInfo.Size())/(1024*1024))
 		return "EXISTS:" + filepath, nil
 	}
 
-	fmt.Printf("Downloading FLAC file to: %s\n", filepath)
-	if err := q.DownloadFile(downloadURL, filepath); err != nil {
+	qobuzLog.Info("downloading", "path", filepath)
+	if err := q.DownloadFile(ctx, downloadURL, filepath); err != nil {
 		return "", fmt.Errorf("failed to download file: %w", err)
 	}
 
-	fmt.Printf("Downloaded: %s\n", filepath)
-
 	coverPath := ""
 
 	if spotifyCoverURL != "" {
 		coverPath = filepath + ".cover.jpg"
 		coverClient := NewCoverClient()
-		if err := coverClient.DownloadCoverToPath(spotifyCoverURL, coverPath, embedMaxQualityCover); err != nil {
-			fmt.Printf("Warning: Failed to download Spotify cover: %v\n", err)
+		if err := coverClient.DownloadCoverToPath(ctx, spotifyCoverURL, coverPath, embedMaxQualityCover); err != nil {
+			qobuzLog.Warn("failed to download Spotify cover", "error", err)
 			coverPath = ""
 		} else {
 			defer os.Remove(coverPath)
-			fmt.Println("Spotify cover downloaded")
+			qobuzLog.Debug("Spotify cover downloaded", "path", coverPath)
 		}
 	}
 
-	fmt.Println("Embedding metadata and cover art...")
-
 	trackNumberToEmbed := spotifyTrackNumber
 	if trackNumberToEmbed == 0 {
 		trackNumberToEmbed = 1
@@ -432,12 +437,21 @@ func (q *QobuzDownloader) DownloadByISRC(deezerISRC, outputDir, quality, filenam
 		Copyright:   spotifyCopyright,
 		Publisher:   spotifyPublisher,
 		Description: "https://github.com/afkarxyz/SpotiFLAC",
+		ISRC:        deezerISRC,
+		SpotifyID:   spotifyTrackIDFromURL(spotifyURL),
+
+		UPC:           track.Album.UPC,
+		Label:         track.Album.Label.Name,
+		Explicit:      track.ParentalWarning,
+		SourceService: "qobuz",
+		SourceTrackID: strconv.FormatInt(track.ID, 10),
 	}
+	q.Release.apply(&metadata)
 
 	if err := EmbedMetadata(filepath, metadata, coverPath); err != nil {
 		return "", fmt.Errorf("failed to embed metadata: %w", err)
 	}
 
-	fmt.Println("Metadata embedded successfully!")
+	qobuzLog.Info("downloaded", "path", filepath)
 	return filepath, nil
 }
@@ -0,0 +1,180 @@
+package backend
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type PendingTrack struct {
+	SpotifyID string         `json:"spotify_id"`
+	Title     string         `json:"title"`
+	Artists   string         `json:"artists"`
+	Album     string         `json:"album"`
+	Source    string         `json:"source"`
+	Status    DownloadStatus `json:"status"`
+	Error     string         `json:"error,omitempty"`
+	Path      string         `json:"path,omitempty"`
+	AddedAt   int64          `json:"added_at"`
+	UpdatedAt int64          `json:"updated_at"`
+}
+
+const pendingBucket = "PendingQueue"
+
+func AddPendingTracks(tracks []PendingTrack, appName string) (int, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return 0, err
+		}
+	}
+
+	added := 0
+	err := historyDB.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(pendingBucket))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().Unix()
+		for _, track := range tracks {
+			if existing := b.Get([]byte(track.SpotifyID)); existing != nil {
+				var prev PendingTrack
+				if json.Unmarshal(existing, &prev) == nil && prev.Status != StatusFailed {
+					continue
+				}
+			}
+
+			track.Status = StatusQueued
+			track.Error = ""
+			track.AddedAt = now
+			track.UpdatedAt = now
+
+			buf, err := json.Marshal(track)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(track.SpotifyID), buf); err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+
+	return added, err
+}
+
+func GetPendingTracks(appName string) ([]PendingTrack, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return nil, err
+		}
+	}
+
+	var tracks []PendingTrack
+	err := historyDB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(pendingBucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var track PendingTrack
+			if err := json.Unmarshal(v, &track); err == nil {
+				tracks = append(tracks, track)
+			}
+			return nil
+		})
+	})
+
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return tracks[i].AddedAt < tracks[j].AddedAt
+	})
+
+	return tracks, err
+}
+
+func GetPendingTrack(spotifyID string, appName string) (*PendingTrack, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return nil, err
+		}
+	}
+
+	var track *PendingTrack
+	err := historyDB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(pendingBucket))
+		if b == nil {
+			return nil
+		}
+		if v := b.Get([]byte(spotifyID)); v != nil {
+			track = &PendingTrack{}
+			return json.Unmarshal(v, track)
+		}
+		return nil
+	})
+
+	return track, err
+}
+
+func UpdatePendingTrack(track PendingTrack, appName string) error {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return err
+		}
+	}
+
+	return historyDB.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(pendingBucket))
+		if err != nil {
+			return err
+		}
+
+		track.UpdatedAt = time.Now().Unix()
+		buf, err := json.Marshal(track)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(track.SpotifyID), buf)
+	})
+}
+
+func ClearPendingTracks(onlyFinished bool, appName string) (int, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return 0, err
+		}
+	}
+
+	removed := 0
+	err := historyDB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(pendingBucket))
+		if b == nil {
+			return nil
+		}
+
+		var keys [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			var track PendingTrack
+			if onlyFinished && json.Unmarshal(v, &track) == nil && track.Status != StatusCompleted && track.Status != StatusSkipped {
+				return nil
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+
+	return removed, err
+}
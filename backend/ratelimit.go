@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateWindow overrides the global rate limit between Start and End, given in
+// minutes after midnight. Windows may wrap past midnight (e.g. 23:00-07:00).
+type RateWindow struct {
+	Start int
+	End   int
+	Limit int64
+	Pause bool
+}
+
+var (
+	rateWindows     []RateWindow
+	rateWindowsLock sync.RWMutex
+)
+
+// ParseRate parses sizes like "5M", "500K", "1.5MB" or "1024" into bytes per
+// second. Units are powers of 1024. "0", "" and "unlimited" mean no limit.
+func ParseRate(input string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(input))
+	if value == "" || value == "0" || value == "UNLIMITED" {
+		return 0, nil
+	}
+
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "/S"), "B")
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1024
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid rate: %s (use e.g. 500K, 5M)", input)
+	}
+	return int64(number * multiplier), nil
+}
+
+func FormatRate(bytesPerSecond int64) string {
+	switch {
+	case bytesPerSecond <= 0:
+		return "unlimited"
+	case bytesPerSecond >= 1024*1024:
+		return fmt.Sprintf("%.1f MB/s", float64(bytesPerSecond)/(1024*1024))
+	default:
+		return fmt.Sprintf("%.0f KB/s", float64(bytesPerSecond)/1024)
+	}
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time: %s (use HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ParseRateWindows parses a comma-separated list of "HH:MM-HH:MM[=RATE]"
+// entries. RATE is a size such as 5M, "unlimited" (the default) or "pause".
+func ParseRateWindows(spec string) ([]RateWindow, error) {
+	var windows []RateWindow
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		span, rate, _ := strings.Cut(entry, "=")
+		startStr, endStr, ok := strings.Cut(span, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid window: %s (use HH:MM-HH:MM[=RATE])", entry)
+		}
+
+		var window RateWindow
+		var err error
+		if window.Start, err = parseClock(startStr); err != nil {
+			return nil, err
+		}
+		if window.End, err = parseClock(endStr); err != nil {
+			return nil, err
+		}
+		if window.Start == window.End {
+			return nil, fmt.Errorf("invalid window: %s (start equals end)", entry)
+		}
+
+		if strings.EqualFold(strings.TrimSpace(rate), "pause") {
+			window.Pause = true
+		} else if window.Limit, err = ParseRate(rate); err != nil {
+			return nil, err
+		}
+
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+func SetRateWindows(windows []RateWindow) {
+	rateWindowsLock.Lock()
+	defer rateWindowsLock.Unlock()
+	rateWindows = windows
+}
+
+func (w RateWindow) contains(minute int) bool {
+	if w.Start < w.End {
+		return minute >= w.Start && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+func activeRateWindow(now time.Time) *RateWindow {
+	rateWindowsLock.RLock()
+	defer rateWindowsLock.RUnlock()
+
+	minute := now.Hour()*60 + now.Minute()
+	for i := range rateWindows {
+		if rateWindows[i].contains(minute) {
+			window := rateWindows[i]
+			return &window
+		}
+	}
+	return nil
+}
+
+// effectiveRateLimit returns the limit of the window active at now, falling
+// back to the global --limit-rate outside all windows.
+func effectiveRateLimit(now time.Time) int64 {
+	if window := activeRateWindow(now); window != nil && !window.Pause {
+		return window.Limit
+	}
+	return GetRateLimit()
+}
+
+// DownloadsPaused reports whether a pause window is active and when it ends.
+func DownloadsPaused(now time.Time) (bool, time.Time) {
+	window := activeRateWindow(now)
+	if window == nil || !window.Pause {
+		return false, time.Time{}
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	resume := midnight.Add(time.Duration(window.End) * time.Minute)
+	if !resume.After(now) {
+		resume = resume.AddDate(0, 0, 1)
+	}
+	return true, resume
+}
+
+// WaitForDownloadWindow blocks until no pause window is active. It returns
+// early with the context error if ctx is cancelled.
+func WaitForDownloadWindow(ctx context.Context) error {
+	for {
+		paused, resume := DownloadsPaused(time.Now())
+		if !paused {
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(resume))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
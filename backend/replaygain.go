@@ -0,0 +1,233 @@
+package backend
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	id3v2 "github.com/bogem/id3v2/v2"
+	"github.com/go-flac/go-flac"
+)
+
+const (
+	// ReplayGainReference is the ReplayGain 2.0 target loudness in LUFS
+	ReplayGainReference = -18.0
+	// r128Reference is the EBU R128 target Opus gains are relative to
+	r128Reference = -23.0
+)
+
+// ReplayGain holds gains in dB relative to ReplayGainReference and linear
+// true peaks. The album values are only set when HasAlbum is.
+type ReplayGain struct {
+	TrackGain float64 `json:"track_gain"`
+	TrackPeak float64 `json:"track_peak"`
+	AlbumGain float64 `json:"album_gain,omitempty"`
+	AlbumPeak float64 `json:"album_peak,omitempty"`
+	HasAlbum  bool    `json:"has_album,omitempty"`
+}
+
+// replayGainFor returns the gain that brings l to the reference loudness.
+// Silence gets no gain.
+func replayGainFor(l *Loudness) float64 {
+	if math.IsInf(l.Integrated, -1) {
+		return 0
+	}
+	return ReplayGainReference - l.Integrated
+}
+
+// TrackReplayGain returns the track values for a measurement
+func TrackReplayGain(track *Loudness) ReplayGain {
+	return ReplayGain{TrackGain: replayGainFor(track), TrackPeak: track.TruePeak}
+}
+
+// SetAlbum adds the album values for the album the track belongs to
+func (rg *ReplayGain) SetAlbum(album *Loudness) {
+	rg.AlbumGain = replayGainFor(album)
+	rg.AlbumPeak = album.TruePeak
+	rg.HasAlbum = true
+}
+
+// fields returns the REPLAYGAIN_* tags. Track-only values clear album tags
+// left from an earlier run, which would no longer match.
+func (rg ReplayGain) fields() []tagField {
+	fields := []tagField{
+		{"REPLAYGAIN_TRACK_GAIN", formatGain(rg.TrackGain)},
+		{"REPLAYGAIN_TRACK_PEAK", formatPeak(rg.TrackPeak)},
+		{"REPLAYGAIN_ALBUM_GAIN", ""},
+		{"REPLAYGAIN_ALBUM_PEAK", ""},
+	}
+	if rg.HasAlbum {
+		fields[2].value = formatGain(rg.AlbumGain)
+		fields[3].value = formatPeak(rg.AlbumPeak)
+	}
+	return fields
+}
+
+// r128Fields returns the Opus gain tags: Q7.8 fixed point dB relative to
+// -23 LUFS, which is what RFC 7845 players read instead of REPLAYGAIN_*
+func (rg ReplayGain) r128Fields() []tagField {
+	q78 := func(gain float64) string {
+		value := math.Round((gain + r128Reference - ReplayGainReference) * 256)
+		return strconv.Itoa(int(max(math.MinInt16, min(math.MaxInt16, value))))
+	}
+	fields := []tagField{{"R128_TRACK_GAIN", q78(rg.TrackGain)}}
+	if rg.HasAlbum {
+		fields = append(fields, tagField{"R128_ALBUM_GAIN", q78(rg.AlbumGain)})
+	}
+	return fields
+}
+
+func formatGain(gain float64) string {
+	return fmt.Sprintf("%.2f dB", gain)
+}
+
+func formatPeak(peak float64) string {
+	return fmt.Sprintf("%.6f", peak)
+}
+
+// parseGain reads a "-6.52 dB" gain value
+func parseGain(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(value, "dB"), "DB"))
+	gain, err := strconv.ParseFloat(value, 64)
+	return gain, err == nil
+}
+
+// setReplayGainTag fills one REPLAYGAIN_* value read from a file into m
+func (m *Metadata) setReplayGainTag(key, value string) {
+	if m.ReplayGain == nil {
+		m.ReplayGain = &ReplayGain{}
+	}
+	rg := m.ReplayGain
+	switch key {
+	case "replaygain_track_gain":
+		rg.TrackGain, _ = parseGain(value)
+	case "replaygain_track_peak":
+		rg.TrackPeak, _ = strconv.ParseFloat(strings.TrimSpace(value), 64)
+	case "replaygain_album_gain":
+		rg.AlbumGain, rg.HasAlbum = parseGain(value)
+	case "replaygain_album_peak":
+		rg.AlbumPeak, _ = strconv.ParseFloat(strings.TrimSpace(value), 64)
+	}
+}
+
+// WriteReplayGain writes the ReplayGain tags into a FLAC, MP3 or M4A file,
+// leaving its other tags alone
+func WriteReplayGain(filePath string, rg ReplayGain) error {
+	// The values describe the audio as it is now, so they replace old ones
+	// unless the merge policy says to keep existing tags
+	mode := GetTagMergeMode()
+	if mode == TagMergeReplace {
+		mode = TagMergeOverwrite
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".flac":
+		f, err := flac.ParseFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to parse FLAC file: %w", err)
+		}
+		mergeVorbisComment(f, rg.fields(), mode)
+		if err := f.Save(filePath); err != nil {
+			return fmt.Errorf("failed to save FLAC file: %w", err)
+		}
+		return nil
+
+	case ".mp3":
+		tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+		if err != nil {
+			return fmt.Errorf("failed to open MP3 file: %w", err)
+		}
+		defer tag.Close()
+
+		var existing []tagField
+		for _, frame := range tag.GetFrames("TXXX") {
+			if udtf, ok := frame.(id3v2.UserDefinedTextFrame); ok {
+				existing = append(existing, tagField{udtf.Description, udtf.Value})
+			}
+		}
+		tag.DeleteFrames("TXXX")
+		for _, field := range mergeTagFields(existing, rg.fields(), mode) {
+			tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+				Encoding:    id3v2.EncodingUTF8,
+				Description: field.name,
+				Value:       field.value,
+			})
+		}
+		if err := tag.Save(); err != nil {
+			return fmt.Errorf("failed to save MP3 tags: %w", err)
+		}
+		return nil
+
+	case ".m4a":
+		existing, err := readMP4Tags(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read M4A tags: %w", err)
+		}
+		update := mp4ItemUpdate{freeform: mergeTagFields(existing.freeform, rg.fields(), mode)}
+		if mode == TagMergeOverwrite {
+			for _, field := range rg.fields() {
+				if field.value == "" {
+					update.clear = append(update.clear, field.name)
+				}
+			}
+		}
+		return writeMP4Items(filePath, update)
+
+	default:
+		return fmt.Errorf("unsupported file format for ReplayGain tags: %s", filepath.Ext(filePath))
+	}
+}
+
+// ReplayGainResult is the outcome of a ReplayGain run for one file
+type ReplayGainResult struct {
+	File string `json:"file"`
+	// Loudness is the integrated loudness in LUFS, nil for silence
+	Loudness   *float64    `json:"loudness_lufs,omitempty"`
+	ReplayGain *ReplayGain `json:"replaygain,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// ApplyReplayGain measures files and writes their track gains, and with
+// album set also album gains computed over all files that could be measured
+func ApplyReplayGain(files []string, album bool) []ReplayGainResult {
+	results := make([]ReplayGainResult, len(files))
+	loudness := make([]*Loudness, len(files))
+	var tracks []*Loudness
+	for i, file := range files {
+		results[i].File = file
+		l, err := MeasureLoudness(file)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		loudness[i] = l
+		tracks = append(tracks, l)
+		if !math.IsInf(l.Integrated, -1) {
+			integrated := l.Integrated
+			results[i].Loudness = &integrated
+		}
+	}
+
+	var albumLoudness *Loudness
+	if album && len(tracks) > 0 {
+		albumLoudness = AlbumLoudness(tracks)
+	}
+	for i, l := range loudness {
+		if l == nil {
+			continue
+		}
+		rg := TrackReplayGain(l)
+		if albumLoudness != nil {
+			rg.SetAlbum(albumLoudness)
+		}
+		if err := WriteReplayGain(files[i], rg); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].ReplayGain = &rg
+	}
+	return results
+}
@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestReplayGainFor(t *testing.T) {
+	tests := []struct {
+		integrated float64
+		want       float64
+	}{
+		{-18, 0},
+		{-8, -10},
+		{-23.5, 5.5},
+		{math.Inf(-1), 0},
+	}
+	for _, tt := range tests {
+		if got := replayGainFor(&Loudness{Integrated: tt.integrated}); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("replayGainFor(%v) = %v, want %v", tt.integrated, got, tt.want)
+		}
+	}
+}
+
+func TestR128Fields(t *testing.T) {
+	tests := []struct {
+		name string
+		rg   ReplayGain
+		want []tagField
+	}{
+		// R128 gains are relative to -23 LUFS, 5dB below the ReplayGain reference
+		{name: "at reference", rg: ReplayGain{TrackGain: 0}, want: []tagField{{"R128_TRACK_GAIN", "-1280"}}},
+		{name: "at -23 LUFS", rg: ReplayGain{TrackGain: 5}, want: []tagField{{"R128_TRACK_GAIN", "0"}}},
+		{name: "rounded to 1/256 dB", rg: ReplayGain{TrackGain: -3.3}, want: []tagField{{"R128_TRACK_GAIN", "-2125"}}},
+		{name: "clamped high", rg: ReplayGain{TrackGain: 200}, want: []tagField{{"R128_TRACK_GAIN", "32767"}}},
+		{name: "clamped low", rg: ReplayGain{TrackGain: -200}, want: []tagField{{"R128_TRACK_GAIN", "-32768"}}},
+		{
+			name: "with album",
+			rg:   ReplayGain{TrackGain: 6, AlbumGain: 4.5, HasAlbum: true},
+			want: []tagField{{"R128_TRACK_GAIN", "256"}, {"R128_ALBUM_GAIN", "-128"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rg.r128Fields(); !slices.Equal(got, tt.want) {
+				t.Errorf("r128Fields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayGainFields(t *testing.T) {
+	tests := []struct {
+		name string
+		rg   ReplayGain
+		want []tagField
+	}{
+		{
+			name: "track only clears album",
+			rg:   ReplayGain{TrackGain: -6.524, TrackPeak: 0.98765432},
+			want: []tagField{
+				{"REPLAYGAIN_TRACK_GAIN", "-6.52 dB"},
+				{"REPLAYGAIN_TRACK_PEAK", "0.987654"},
+				{"REPLAYGAIN_ALBUM_GAIN", ""},
+				{"REPLAYGAIN_ALBUM_PEAK", ""},
+			},
+		},
+		{
+			name: "with album",
+			rg:   ReplayGain{TrackGain: 1.5, TrackPeak: 0.5, AlbumGain: -0.25, AlbumPeak: 1.02, HasAlbum: true},
+			want: []tagField{
+				{"REPLAYGAIN_TRACK_GAIN", "1.50 dB"},
+				{"REPLAYGAIN_TRACK_PEAK", "0.500000"},
+				{"REPLAYGAIN_ALBUM_GAIN", "-0.25 dB"},
+				{"REPLAYGAIN_ALBUM_PEAK", "1.020000"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rg.fields(); !slices.Equal(got, tt.want) {
+				t.Errorf("fields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGain(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+		ok    bool
+	}{
+		{"-6.52 dB", -6.52, true},
+		{"+1.00 dB", 1, true},
+		{" 3.5dB ", 3.5, true},
+		{"2 DB", 2, true},
+		{"0", 0, true},
+		{"", 0, false},
+		{"loud", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseGain(tt.value)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseGain(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
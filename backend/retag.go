@@ -0,0 +1,348 @@
+package backend
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Ways a file can be matched to a Spotify track
+const (
+	MatchedBySpotifyID = "spotify_id"
+	MatchedByISRC      = "isrc"
+	MatchedBySearch    = "search"
+)
+
+// TagChange is one field a retag would change. Old is empty for fields the
+// file doesn't have yet.
+type TagChange struct {
+	Field string   `json:"field"`
+	Old   []string `json:"old,omitempty"`
+	New   []string `json:"new"`
+}
+
+// RetagPlan is the tag update proposed for one file
+type RetagPlan struct {
+	File       string      `json:"file"`
+	SpotifyID  string      `json:"spotify_id,omitempty"`
+	MatchedBy  string      `json:"matched_by,omitempty"`
+	Confidence float64     `json:"confidence,omitempty"`
+	Changes    []TagChange `json:"changes,omitempty"`
+	// Cover is "added" or "replaced" when the cover art changes
+	Cover string `json:"cover,omitempty"`
+	Error string `json:"error,omitempty"`
+
+	cover []byte
+}
+
+// HasChanges reports whether applying the plan would change the file
+func (p *RetagPlan) HasChanges() bool {
+	return p.Error == "" && (len(p.Changes) > 0 || p.Cover != "")
+}
+
+// Apply writes the planned changes, leaving every other tag in place
+func (p *RetagPlan) Apply() error {
+	if !p.HasChanges() {
+		return nil
+	}
+	edit := TagEdit{Cover: p.cover}
+	for _, change := range p.Changes {
+		for _, value := range change.New {
+			edit.Set = append(edit.Set, TagField{change.Field, value})
+		}
+	}
+	return EditTags(p.File, edit)
+}
+
+// Retagger plans tag updates for local files from Spotify metadata
+type Retagger struct {
+	// MinConfidence is the match score a file without a Spotify ID needs,
+	// whether it is found by ISRC or by a search
+	MinConfidence   float64
+	SkipCover       bool
+	MaxQualityCover bool
+	AppName         string
+
+	covers coverCache
+}
+
+// Plan identifies filePath on Spotify and compares its tags with the
+// current metadata. Failures are reported in the plan's Error.
+func (r *Retagger) Plan(ctx context.Context, filePath string) *RetagPlan {
+	plan := &RetagPlan{File: filePath}
+	tags, err := ReadTags(filePath)
+	if err != nil {
+		plan.Error = err.Error()
+		return plan
+	}
+
+	plan.SpotifyID, plan.MatchedBy, plan.Confidence, err = r.identify(ctx, filePath, tags)
+	if err != nil {
+		plan.Error = err.Error()
+		return plan
+	}
+
+	metadata, coverURL, err := SpotifyTrackMetadata(ctx, plan.SpotifyID, r.AppName)
+	if err != nil {
+		plan.Error = err.Error()
+		return plan
+	}
+
+	fillMissing := GetTagMergeMode() == TagMergeFillMissing
+	wanted := make(map[string][]string)
+	var order []string
+	for _, field := range metadata.vorbisFields() {
+		if _, ok := wanted[field.name]; !ok {
+			order = append(order, field.name)
+		}
+		wanted[field.name] = append(wanted[field.name], field.value)
+	}
+	for _, name := range order {
+		old, want := tags.Values(name), wanted[name]
+		have := old
+		if tags.yearOnlyDate {
+			have, want = id3v23Comparable(name, old, want)
+		}
+		if sameTagValues(name, have, want) || (fillMissing && len(old) > 0) {
+			continue
+		}
+		plan.Changes = append(plan.Changes, TagChange{Field: name, Old: old, New: wanted[name]})
+	}
+
+	if !r.SkipCover && coverURL != "" && !(fillMissing && tags.HasCover) {
+		cover, err := r.covers.get(ctx, coverURL, r.MaxQualityCover)
+		switch {
+		case err != nil:
+			metadataLog.Warn("failed to download cover art", "url", coverURL, "error", err)
+		case !tags.HasCover:
+			plan.cover, plan.Cover = cover, "added"
+		case !bytes.Equal(tags.Cover, cover):
+			plan.cover, plan.Cover = cover, "replaced"
+		}
+	}
+	return plan
+}
+
+// identify finds the Spotify track a file is, by its embedded Spotify ID,
+// its ISRC, or a search on its title, artist and duration
+func (r *Retagger) identify(ctx context.Context, filePath string, tags *FileTags) (string, string, float64, error) {
+	first := func(name string) string {
+		if values := tags.Values(name); len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+	if id := first("SPOTIFY_TRACK_ID"); id != "" {
+		return id, MatchedBySpotifyID, 1, nil
+	}
+
+	title, artist := first("TITLE"), strings.Join(tags.Values("ARTIST"), ", ")
+	durationMS := 0
+	if duration, err := GetAudioDuration(filePath); err == nil {
+		durationMS = int(math.Round(duration * 1000))
+	}
+
+	if isrc := first("ISRC"); isrc != "" {
+		results, err := SearchSpotifyByType(ctx, "isrc:"+isrc, "track", importSearchLimit, 0)
+		if err != nil {
+			return "", "", 0, err
+		}
+		// Without a title there is nothing to check the ISRC against
+		if len(results) > 0 && title == "" {
+			return results[0].ID, MatchedByISRC, 1, nil
+		}
+		// Singles and albums share ISRCs, the title and duration pick one. A
+		// best match that still scores low means the ISRC tag is wrong, so
+		// the file is searched for like one without it.
+		if match, ok := bestISRCMatch(scoreTrackCandidates(results, title, artist, durationMS), r.MinConfidence); ok {
+			return match.ID, MatchedByISRC, match.Score, nil
+		}
+	}
+
+	if title == "" {
+		return "", "", 0, fmt.Errorf("no Spotify ID, ISRC or title to identify the track by")
+	}
+	candidates, err := searchTrackMatch(ctx, strings.TrimSpace(artist+" "+title), title, artist, durationMS)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if err := acceptMatch(candidates, r.MinConfidence); err != nil {
+		return "", "", 0, err
+	}
+	return candidates[0].ID, MatchedBySearch, candidates[0].Score, nil
+}
+
+// ScoredCandidate is a search result and how well it matches a file
+type ScoredCandidate struct {
+	SearchResult
+	Score float64 `json:"score"`
+}
+
+// searchTrackMatch searches Spotify and scores the results against title,
+// artist and duration, best first
+func searchTrackMatch(ctx context.Context, query, title, artist string, durationMS int) ([]ScoredCandidate, error) {
+	results, err := SearchSpotifyByType(ctx, query, "track", importSearchLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	return scoreTrackCandidates(results, title, artist, durationMS), nil
+}
+
+// scoreTrackCandidates scores results against title, artist and duration,
+// best first
+func scoreTrackCandidates(results []SearchResult, title, artist string, durationMS int) []ScoredCandidate {
+	candidates := make([]ScoredCandidate, len(results))
+	for i, result := range results {
+		candidates[i] = ScoredCandidate{result, ScoreTrackMatch(title, artist, durationMS, result)}
+	}
+	slices.SortStableFunc(candidates, func(a, b ScoredCandidate) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return candidates
+}
+
+// bestISRCMatch returns the best of the tracks sharing an ISRC when it scores
+// at least minConfidence. Unlike a search, close runners-up are expected:
+// they are the same recording on another release.
+func bestISRCMatch(candidates []ScoredCandidate, minConfidence float64) (ScoredCandidate, bool) {
+	if minConfidence <= 0 {
+		minConfidence = DefaultImportConfidence
+	}
+	if len(candidates) == 0 || candidates[0].Score < minConfidence {
+		return ScoredCandidate{}, false
+	}
+	return candidates[0], true
+}
+
+// acceptMatch returns nil when the best candidate scores at least
+// minConfidence and clearly beats the runner-up
+func acceptMatch(candidates []ScoredCandidate, minConfidence float64) error {
+	if minConfidence <= 0 {
+		minConfidence = DefaultImportConfidence
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no search results")
+	}
+	best := candidates[0]
+	if best.Score < minConfidence {
+		return fmt.Errorf("best match %q by %s scored %.2f", best.Name, best.Artists, best.Score)
+	}
+	if len(candidates) > 1 && best.Score-candidates[1].Score < importAmbiguityMargin {
+		return fmt.Errorf("ambiguous match: %q by %s and %q by %s both scored about %.2f",
+			best.Name, best.Artists, candidates[1].Name, candidates[1].Artists, best.Score)
+	}
+	return nil
+}
+
+// id3v23Comparable reduces the values of a field to what an ID3v2.3 file
+// reads back: a year is all it can hold for DATE, and the frames written one
+// value per name are split on "/", so those are compared joined
+func id3v23Comparable(name string, old, wanted []string) ([]string, []string) {
+	switch {
+	case name == "DATE" && len(wanted) == 1:
+		return old, []string{extractYear(wanted[0])}
+	case id3MultiValueFrames[id3FrameFor(name)] && len(old) > 0:
+		return []string{strings.Join(old, "/")}, []string{strings.Join(wanted, "/")}
+	}
+	return old, wanted
+}
+
+// sameTagValues compares values, numerically for the number fields so "03"
+// equals "3"
+func sameTagValues(name string, old, wanted []string) bool {
+	if _, _, ok := numberPair(name); ok && len(old) == 1 && len(wanted) == 1 {
+		a, errA := strconv.Atoi(strings.TrimSpace(old[0]))
+		b, errB := strconv.Atoi(strings.TrimSpace(wanted[0]))
+		if errA == nil && errB == nil {
+			return a == b
+		}
+	}
+	return slices.Equal(old, wanted)
+}
+
+// coverCache downloads each cover once per run, tracks of an album share it
+type coverCache map[string][]byte
+
+func (c *coverCache) get(ctx context.Context, coverURL string, maxQuality bool) ([]byte, error) {
+	if cover, ok := (*c)[coverURL]; ok {
+		return cover, nil
+	}
+
+	tmpFile, err := os.CreateTemp("", "cover-*.jpg")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	if err := NewCoverClient().DownloadCoverToPath(ctx, coverURL, tmpFile.Name(), maxQuality); err != nil {
+		return nil, err
+	}
+	cover, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return nil, err
+	}
+	if *c == nil {
+		*c = make(coverCache)
+	}
+	(*c)[coverURL] = cover
+	return cover, nil
+}
+
+// SpotifyTrackMetadata fetches a track and returns the metadata a download
+// of it would be tagged with, and its cover URL
+func SpotifyTrackMetadata(ctx context.Context, spotifyID, appName string) (Metadata, string, error) {
+	data, err := GetFilteredSpotifyData(ctx, "https://open.spotify.com/track/"+spotifyID, false, 0)
+	if err != nil {
+		return Metadata{}, "", fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	response, ok := data.(TrackResponse)
+	if !ok {
+		return Metadata{}, "", fmt.Errorf("unexpected metadata response for track %s", spotifyID)
+	}
+	track := response.Track
+
+	names := func(artists []ArtistSimple) []string {
+		var names []string
+		for _, artist := range artists {
+			names = append(names, artist.Name)
+		}
+		return names
+	}
+	metadata := Metadata{
+		Title:       track.Name,
+		Artist:      track.Artists,
+		Album:       track.AlbumName,
+		AlbumArtist: track.AlbumArtist,
+		Date:        track.ReleaseDate,
+		ReleaseDate: track.ReleaseDate,
+		TrackNumber: track.TrackNumber,
+		TotalTracks: track.TotalTracks,
+		DiscNumber:  track.DiscNumber,
+		TotalDiscs:  track.TotalDiscs,
+		URL:         track.ExternalURL,
+		Copyright:   track.Copyright,
+		Publisher:   track.Publisher,
+		ISRC:        track.ISRC,
+		SpotifyID:   spotifyID,
+
+		SpotifyAlbumID: track.AlbumID,
+		Explicit:       track.Explicit,
+		Artists:        names(track.ArtistsData),
+		AlbumArtists:   names(track.AlbumArtistsData),
+	}
+	if len(metadata.Artists) > 0 {
+		metadata.Artist = JoinMultiValue(metadata.Artists)
+		metadata.Genres = GenresForArtist(ctx, track.ArtistsData[0].ID, appName)
+	}
+	if len(metadata.AlbumArtists) > 0 {
+		metadata.AlbumArtist = JoinMultiValue(metadata.AlbumArtists)
+	}
+	return metadata, track.Images, nil
+}
@@ -0,0 +1,112 @@
+package backend
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBestISRCMatch(t *testing.T) {
+	candidates := func(scores ...float64) []ScoredCandidate {
+		var c []ScoredCandidate
+		for i, score := range scores {
+			c = append(c, ScoredCandidate{SearchResult{ID: string(rune('a' + i))}, score})
+		}
+		return c
+	}
+
+	tests := []struct {
+		name          string
+		candidates    []ScoredCandidate
+		minConfidence float64
+		wantID        string
+		wantOK        bool
+	}{
+		{name: "no tracks", candidates: nil, minConfidence: 0.8},
+		{name: "confident", candidates: candidates(0.95, 0.4), minConfidence: 0.8, wantID: "a", wantOK: true},
+		// The same recording on a single and an album scores the same
+		{name: "tied releases", candidates: candidates(0.9, 0.9), minConfidence: 0.8, wantID: "a", wantOK: true},
+		{name: "below threshold", candidates: candidates(0.05), minConfidence: 0.8},
+		{name: "default threshold", candidates: candidates(DefaultImportConfidence - 0.01), minConfidence: 0},
+		{name: "at default threshold", candidates: candidates(DefaultImportConfidence), minConfidence: 0, wantID: "a", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := bestISRCMatch(tt.candidates, tt.minConfidence)
+			if ok != tt.wantOK || match.ID != tt.wantID {
+				t.Errorf("bestISRCMatch() = %q, %v; want %q, %v", match.ID, ok, tt.wantID, tt.wantOK)
+			}
+			if ok && match.Score != tt.candidates[0].Score {
+				t.Errorf("score = %v, want %v", match.Score, tt.candidates[0].Score)
+			}
+		})
+	}
+}
+
+func TestSameTagValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		field  string
+		old    []string
+		wanted []string
+		want   bool
+	}{
+		{name: "equal", field: "TITLE", old: []string{"Song"}, wanted: []string{"Song"}, want: true},
+		{name: "different", field: "TITLE", old: []string{"Song"}, wanted: []string{"Other"}},
+		{name: "padded track number", field: "TRACKNUMBER", old: []string{"03"}, wanted: []string{"3"}, want: true},
+		{name: "padded total", field: "TOTALDISCS", old: []string{" 2"}, wanted: []string{"2"}, want: true},
+		{name: "padding only counts for numbers", field: "TITLE", old: []string{"03"}, wanted: []string{"3"}},
+		{name: "value order matters", field: "ARTIST", old: []string{"B", "A"}, wanted: []string{"A", "B"}},
+		{name: "missing", field: "ALBUM", old: nil, wanted: []string{"Album"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameTagValues(tt.field, tt.old, tt.wanted); got != tt.want {
+				t.Errorf("sameTagValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestID3v23Comparable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	writeTestMP3(t, path, 3)
+	edit := TagEdit{Set: []TagField{
+		{"ARTIST", "AC/DC"},
+		{"GENRE", "Rock"},
+		{"GENRE", "Pop"},
+		{"TITLE", "AC/DC Live"},
+		{"DATE", "1980-07-25"},
+	}}
+	if err := EditTags(path, edit); err != nil {
+		t.Fatalf("EditTags: %v", err)
+	}
+	tags, err := ReadTags(path)
+	if err != nil {
+		t.Fatalf("ReadTags: %v", err)
+	}
+
+	tests := []struct {
+		field  string
+		wanted []string
+		want   bool
+	}{
+		// Read back as ["AC", "DC"], still the same artist
+		{field: "ARTIST", wanted: []string{"AC/DC"}, want: true},
+		{field: "ARTIST", wanted: []string{"AC", "DC"}, want: true},
+		{field: "ARTIST", wanted: []string{"AC/DC", "Other"}},
+		{field: "GENRE", wanted: []string{"Rock", "Pop"}, want: true},
+		{field: "TITLE", wanted: []string{"AC/DC Live"}, want: true},
+		{field: "DATE", wanted: []string{"1980-07-25"}, want: true},
+		{field: "DATE", wanted: []string{"1981-07-25"}},
+		{field: "ALBUM", wanted: []string{"Back in Black"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			have, want := id3v23Comparable(tt.field, tags.Values(tt.field), tt.wanted)
+			if got := sameTagValues(tt.field, have, want); got != tt.want {
+				t.Errorf("%s %q vs %q: same = %v, want %v", tt.field, tags.Values(tt.field), tt.wanted, got, tt.want)
+			}
+		})
+	}
+}
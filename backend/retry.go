@@ -0,0 +1,82 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy retries operations that fail with a transient error (see
+// IsTransient), backing off exponentially between attempts.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var (
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 2 * time.Second, MaxDelay: 30 * time.Second}
+
+	// song.link allows about 10 requests per minute
+	SongLinkRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 15 * time.Second, MaxDelay: time.Minute}
+)
+
+// Delay returns how long to wait before the given retry (1 for the first).
+// A Retry-After sent by the service takes precedence.
+func (p RetryPolicy) Delay(retry int, err error) time.Duration {
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) && serviceErr.RetryAfter > 0 {
+		return min(serviceErr.RetryAfter, p.MaxDelay)
+	}
+
+	delay := p.BaseDelay << (retry - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	// Up to 20% jitter so parallel clients don't retry in lockstep
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+// Do calls fn until it succeeds, fails permanently, the attempts run out or
+// ctx is cancelled, and returns the last error. When the attempts run out
+// the error is no longer transient, so an outer Do doesn't multiply them.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	attempts := max(p.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !IsTransient(err) {
+			return err
+		}
+		if attempt >= attempts {
+			return &retriesExhausted{err}
+		}
+
+		delay := p.Delay(attempt, err)
+		retryLog.Warn("transient failure, retrying", "error", err, "delay", delay.Round(time.Second), "attempt", attempt+1, "max_attempts", attempts)
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// doRequest sends a body-less request under policy. Any status other than
+// 200 becomes a *ServiceError; the returned response always has status 200.
+func doRequest(ctx context.Context, client *http.Client, req *http.Request, service string, policy RetryPolicy) (*http.Response, error) {
+	var resp *http.Response
+	err := policy.Do(ctx, func() error {
+		r, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		if r.StatusCode != http.StatusOK {
+			r.Body.Close()
+			return statusError(service, r)
+		}
+		resp = r
+		return nil
+	})
+	return resp, err
+}
@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDoesNotMultiply(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	calls := 0
+	err := policy.Do(context.Background(), func() error {
+		return policy.Do(context.Background(), func() error {
+			calls++
+			return &ServiceError{Kind: ErrUpstreamDown, Service: "test", StatusCode: 503}
+		})
+	})
+	if calls != 3 {
+		t.Errorf("nested retries made %d calls, want 3", calls)
+	}
+	if !errors.Is(err, ErrUpstreamDown) {
+		t.Errorf("err = %v, want it to wrap ErrUpstreamDown", err)
+	}
+	if IsTransient(err) {
+		t.Error("exhausted error is still transient")
+	}
+	if kind := ErrorKind(err); kind != "upstream-down" {
+		t.Errorf("ErrorKind = %q, want upstream-down", kind)
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{"success", []error{nil}, 1, nil},
+		{"permanent", []error{ErrNotFound}, 1, ErrNotFound},
+		{"conversion failure", []error{&ServiceError{Kind: ErrConversionFailed, Service: "tidal"}}, 1, ErrConversionFailed},
+		{"transient then success", []error{ErrRateLimited, nil}, 2, nil},
+		{"transient until exhausted", []error{ErrRateLimited, ErrRateLimited, ErrRateLimited}, 3, ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := policy.Do(context.Background(), func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -35,14 +36,12 @@ type TrackAvailability struct {
 
 func NewSongLinkClient() *SongLinkClient {
 	return &SongLinkClient{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:           newHTTPClient("songlink", 30*time.Second),
 		apiCallResetTime: time.Now(),
 	}
 }
 
-func (s *SongLinkClient) GetAllURLsFromSpotify(spotifyTrackID string) (*SongLinkURLs, error) {
+func (s *SongLinkClient) GetAllURLsFromSpotify(ctx context.Context, spotifyTrackID string) (*SongLinkURLs, error) {
 
 	now := time.Now()
 	if now.Sub(s.apiCallResetTime) >= time.Minute {
@@ -53,8 +52,11 @@ func (s *SongLinkClient) GetAllURLsFromSpotify(spotifyTrackID string) (*SongLink
 	if s.apiCallCount >= 9 {
 		waitTime := time.Minute - now.Sub(s.apiCallResetTime)
 		if waitTime > 0 {
-			fmt.Printf("Rate limit reached, waiting %v...\n", waitTime.Round(time.Second))
-			time.Sleep(waitTime)
+			songLinkLog.Info("rate limit reached, waiting", "wait", waitTime.Round(time.Second))
+			observeSongLinkWait(waitTime)
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return nil, err
+			}
 			s.apiCallCount = 0
 			s.apiCallResetTime = time.Now()
 		}
@@ -65,8 +67,11 @@ func (s *SongLinkClient) GetAllURLsFromSpotify(spotifyTrackID string) (*SongLink
 		minDelay := 7 * time.Second
 		if timeSinceLastCall < minDelay {
 			waitTime := minDelay - timeSinceLastCall
-			fmt.Printf("Rate limiting: waiting %v...\n", waitTime.Round(time.Second))
-			time.Sleep(waitTime)
+			songLinkLog.Debug("rate limiting requests", "wait", waitTime.Round(time.Second))
+			observeSongLinkWait(waitTime)
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -76,41 +81,18 @@ func (s *SongLinkClient) GetAllURLsFromSpotify(spotifyTrackID string) (*SongLink
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9hcGkuc29uZy5saW5rL3YxLWFscGhhLjEvbGlua3M/dXJsPQ==")
 	apiURL := fmt.Sprintf("%s%s", string(apiBase), url.QueryEscape(spotifyURL))
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	fmt.Println("Getting streaming URLs from song.link...")
-
-	maxRetries := 3
-	var resp *http.Response
-	for i := 0; i < maxRetries; i++ {
-		resp, err = s.client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get URLs: %w", err)
-		}
-
-		s.lastAPICallTime = time.Now()
-		s.apiCallCount++
+	songLinkLog.Info("getting streaming URLs", "spotify_id", spotifyTrackID)
 
-		if resp.StatusCode == 429 {
-			resp.Body.Close()
-			if i < maxRetries-1 {
-				waitTime := 15 * time.Second
-				fmt.Printf("Rate limited by API, waiting %v before retry...\n", waitTime)
-				time.Sleep(waitTime)
-				continue
-			}
-			return nil, fmt.Errorf("API rate limit exceeded after %d retries", maxRetries)
-		}
-
-		if resp.StatusCode != 200 {
-			resp.Body.Close()
-			return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-		}
-
-		break
+	resp, err := doRequest(ctx, s.client, req, "song.link", SongLinkRetryPolicy)
+	s.lastAPICallTime = time.Now()
+	s.apiCallCount++
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URLs: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -142,7 +124,7 @@ func (s *SongLinkClient) GetAllURLsFromSpotify(spotifyTrackID string) (*SongLink
 
 	if tidalLink, ok := songLinkResp.LinksByPlatform["tidal"]; ok && tidalLink.URL != "" {
 		urls.TidalURL = tidalLink.URL
-		fmt.Printf("✓ Tidal URL found\n")
+		songLinkLog.Debug("found Tidal URL", "url", urls.TidalURL)
 	}
 
 	if amazonLink, ok := songLinkResp.LinksByPlatform["amazonMusic"]; ok && amazonLink.URL != "" {
@@ -150,18 +132,18 @@ func (s *SongLinkClient) GetAllURLsFromSpotify(spotifyTrackID string) (*SongLink
 
 		if len(amazonURL) > 0 {
 			urls.AmazonURL = amazonURL
-			fmt.Printf("✓ Amazon URL found\n")
+			songLinkLog.Debug("found Amazon URL", "url", urls.AmazonURL)
 		}
 	}
 
 	if urls.TidalURL == "" && urls.AmazonURL == "" {
-		return nil, fmt.Errorf("no streaming URLs found")
+		return nil, newServiceError("song.link", ErrNotFound, "no streaming URLs found", nil)
 	}
 
 	return urls, nil
 }
 
-func (s *SongLinkClient) CheckTrackAvailability(spotifyTrackID string, isrc string) (*TrackAvailability, error) {
+func (s *SongLinkClient) CheckTrackAvailability(ctx context.Context, spotifyTrackID string, isrc string) (*TrackAvailability, error) {
 
 	now := time.Now()
 	if now.Sub(s.apiCallResetTime) >= time.Minute {
@@ -172,8 +154,11 @@ func (s *SongLinkClient) CheckTrackAvailability(spotifyTrackID string, isrc stri
 	if s.apiCallCount >= 9 {
 		waitTime := time.Minute - now.Sub(s.apiCallResetTime)
 		if waitTime > 0 {
-			fmt.Printf("Rate limit reached, waiting %v...\n", waitTime.Round(time.Second))
-			time.Sleep(waitTime)
+			songLinkLog.Info("rate limit reached, waiting", "wait", waitTime.Round(time.Second))
+			observeSongLinkWait(waitTime)
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return nil, err
+			}
 			s.apiCallCount = 0
 			s.apiCallResetTime = time.Now()
 		}
@@ -184,8 +169,11 @@ func (s *SongLinkClient) CheckTrackAvailability(spotifyTrackID string, isrc stri
 		minDelay := 7 * time.Second
 		if timeSinceLastCall < minDelay {
 			waitTime := minDelay - timeSinceLastCall
-			fmt.Printf("Rate limiting: waiting %v...\n", waitTime.Round(time.Second))
-			time.Sleep(waitTime)
+			songLinkLog.Debug("rate limiting requests", "wait", waitTime.Round(time.Second))
+			observeSongLinkWait(waitTime)
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -195,41 +183,18 @@ func (s *SongLinkClient) CheckTrackAvailability(spotifyTrackID string, isrc stri
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9hcGkuc29uZy5saW5rL3YxLWFscGhhLjEvbGlua3M/dXJsPQ==")
 	apiURL := fmt.Sprintf("%s%s", string(apiBase), url.QueryEscape(spotifyURL))
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	fmt.Printf("Checking availability for track: %s\n", spotifyTrackID)
+	songLinkLog.Info("checking availability", "spotify_id", spotifyTrackID)
 
-	maxRetries := 3
-	var resp *http.Response
-	for i := 0; i < maxRetries; i++ {
-		resp, err = s.client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check availability: %w", err)
-		}
-
-		s.lastAPICallTime = time.Now()
-		s.apiCallCount++
-
-		if resp.StatusCode == 429 {
-			resp.Body.Close()
-			if i < maxRetries-1 {
-				waitTime := 15 * time.Second
-				fmt.Printf("Rate limited by API, waiting %v before retry...\n", waitTime)
-				time.Sleep(waitTime)
-				continue
-			}
-			return nil, fmt.Errorf("API rate limit exceeded after %d retries", maxRetries)
-		}
-
-		if resp.StatusCode != 200 {
-			resp.Body.Close()
-			return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-		}
-
-		break
+	resp, err := doRequest(ctx, s.client, req, "song.link", SongLinkRetryPolicy)
+	s.lastAPICallTime = time.Now()
+	s.apiCallCount++
+	if err != nil {
+		return nil, fmt.Errorf("failed to check availability: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -274,9 +239,9 @@ func (s *SongLinkClient) CheckTrackAvailability(spotifyTrackID string, isrc stri
 	if deezerLink, ok := songLinkResp.LinksByPlatform["deezer"]; ok && deezerLink.URL != "" {
 		deezerURL := deezerLink.URL
 
-		deezerISRC, err := GetDeezerISRC(deezerURL)
+		deezerISRC, err := GetDeezerISRC(ctx, deezerURL)
 		if err == nil && deezerISRC != "" {
-			qobuzAvailable := checkQobuzAvailability(deezerISRC)
+			qobuzAvailable := checkQobuzAvailability(ctx, deezerISRC)
 			availability.Qobuz = qobuzAvailable
 		}
 	}
@@ -284,14 +249,14 @@ func (s *SongLinkClient) CheckTrackAvailability(spotifyTrackID string, isrc stri
 	return availability, nil
 }
 
-func checkQobuzAvailability(isrc string) bool {
-	client := &http.Client{Timeout: 10 * time.Second}
+func checkQobuzAvailability(ctx context.Context, isrc string) bool {
+	client := newHTTPClient("qobuz", 10*time.Second)
 	appID := "798273057"
 
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly93d3cucW9idXouY29tL2FwaS5qc29uLzAuMi90cmFjay9zZWFyY2g/cXVlcnk9")
 	searchURL := fmt.Sprintf("%s%s&limit=1&app_id=%s", string(apiBase), isrc, appID)
 
-	resp, err := client.Get(searchURL)
+	resp, err := httpGet(ctx, client, searchURL)
 	if err != nil {
 		return false
 	}
@@ -313,7 +278,7 @@ func checkQobuzAvailability(isrc string) bool {
 	return searchResp.Tracks.Total > 0
 }
 
-func (s *SongLinkClient) GetDeezerURLFromSpotify(spotifyTrackID string) (string, error) {
+func (s *SongLinkClient) GetDeezerURLFromSpotify(ctx context.Context, spotifyTrackID string) (string, error) {
 
 	now := time.Now()
 	if now.Sub(s.apiCallResetTime) >= time.Minute {
@@ -324,8 +289,11 @@ func (s *SongLinkClient) GetDeezerURLFromSpotify(spotifyTrackID string) (string,
 	if s.apiCallCount >= 9 {
 		waitTime := time.Minute - now.Sub(s.apiCallResetTime)
 		if waitTime > 0 {
-			fmt.Printf("Rate limit reached, waiting %v...\n", waitTime.Round(time.Second))
-			time.Sleep(waitTime)
+			songLinkLog.Info("rate limit reached, waiting", "wait", waitTime.Round(time.Second))
+			observeSongLinkWait(waitTime)
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return "", err
+			}
 			s.apiCallCount = 0
 			s.apiCallResetTime = time.Now()
 		}
@@ -336,8 +304,11 @@ func (s *SongLinkClient) GetDeezerURLFromSpotify(spotifyTrackID string) (string,
 		minDelay := 7 * time.Second
 		if timeSinceLastCall < minDelay {
 			waitTime := minDelay - timeSinceLastCall
-			fmt.Printf("Rate limiting: waiting %v...\n", waitTime.Round(time.Second))
-			time.Sleep(waitTime)
+			songLinkLog.Debug("rate limiting requests", "wait", waitTime.Round(time.Second))
+			observeSongLinkWait(waitTime)
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return "", err
+			}
 		}
 	}
 
@@ -347,41 +318,18 @@ func (s *SongLinkClient) GetDeezerURLFromSpotify(spotifyTrackID string) (string,
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9hcGkuc29uZy5saW5rL3YxLWFscGhhLjEvbGlua3M/dXJsPQ==")
 	apiURL := fmt.Sprintf("%s%s", string(apiBase), url.QueryEscape(spotifyURL))
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	fmt.Println("Getting Deezer URL from song.link...")
-
-	maxRetries := 3
-	var resp *http.Response
-	for i := 0; i < maxRetries; i++ {
-		resp, err = s.client.Do(req)
-		if err != nil {
-			return "", fmt.Errorf("failed to get Deezer URL: %w", err)
-		}
-
-		s.lastAPICallTime = time.Now()
-		s.apiCallCount++
-
-		if resp.StatusCode == 429 {
-			resp.Body.Close()
-			if i < maxRetries-1 {
-				waitTime := 15 * time.Second
-				fmt.Printf("Rate limited by API, waiting %v before retry...\n", waitTime)
-				time.Sleep(waitTime)
-				continue
-			}
-			return "", fmt.Errorf("API rate limit exceeded after %d retries", maxRetries)
-		}
-
-		if resp.StatusCode != 200 {
-			resp.Body.Close()
-			return "", fmt.Errorf("API returned status %d", resp.StatusCode)
-		}
+	songLinkLog.Info("getting Deezer URL", "spotify_id", spotifyTrackID)
 
-		break
+	resp, err := doRequest(ctx, s.client, req, "song.link", SongLinkRetryPolicy)
+	s.lastAPICallTime = time.Now()
+	s.apiCallCount++
+	if err != nil {
+		return "", fmt.Errorf("failed to get Deezer URL: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -396,15 +344,15 @@ func (s *SongLinkClient) GetDeezerURLFromSpotify(spotifyTrackID string) (string,
 
 	deezerLink, ok := songLinkResp.LinksByPlatform["deezer"]
 	if !ok || deezerLink.URL == "" {
-		return "", fmt.Errorf("deezer link not found")
+		return "", newServiceError("song.link", ErrNotFound, "deezer link not found", nil)
 	}
 
 	deezerURL := deezerLink.URL
-	fmt.Printf("Found Deezer URL: %s\n", deezerURL)
+	songLinkLog.Info("found Deezer URL", "url", deezerURL)
 	return deezerURL, nil
 }
 
-func GetDeezerISRC(deezerURL string) (string, error) {
+func GetDeezerISRC(ctx context.Context, deezerURL string) (string, error) {
 
 	var trackID string
 	if strings.Contains(deezerURL, "/track/") {
@@ -421,15 +369,15 @@ func GetDeezerISRC(deezerURL string) (string, error) {
 
 	apiURL := fmt.Sprintf("https://api.deezer.com/track/%s", trackID)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(apiURL)
+	client := newHTTPClient("deezer", 10*time.Second)
+	resp, err := httpGet(ctx, client, apiURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to call Deezer API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("Deezer API returned status %d", resp.StatusCode)
+		return "", statusError("deezer", resp)
 	}
 
 	var deezerTrack struct {
@@ -445,6 +393,6 @@ func GetDeezerISRC(deezerURL string) (string, error) {
 		return "", fmt.Errorf("ISRC not found in Deezer API response for track %s", trackID)
 	}
 
-	fmt.Printf("Found ISRC from Deezer: %s (track: %s)\n", deezerTrack.ISRC, deezerTrack.Title)
+	songLinkLog.Info("found ISRC from Deezer", "isrc", deezerTrack.ISRC, "title", deezerTrack.Title)
 	return deezerTrack.ISRC, nil
 }
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"bytes"
+	"context"
 	"encoding/base32"
 	"encoding/base64"
 	"encoding/hex"
@@ -36,7 +37,7 @@ type SpotifyClient struct {
 
 func NewSpotifyClient() *SpotifyClient {
 	return &SpotifyClient{
-		client:  &http.Client{Timeout: 30 * time.Second},
+		client:  newHTTPClient("spotify", 30*time.Second),
 		cookies: make(map[string]string),
 	}
 }
@@ -93,13 +94,13 @@ func base32Encode(data []byte) string {
 	return b32.EncodeToString(data)
 }
 
-func (c *SpotifyClient) getAccessToken() error {
+func (c *SpotifyClient) getAccessToken(ctx context.Context) error {
 	totpCode, version, err := c.generateTOTP()
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("GET", "https://open.spotify.com/api/token", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://open.spotify.com/api/token", nil)
 	if err != nil {
 		return err
 	}
@@ -122,7 +123,7 @@ func (c *SpotifyClient) getAccessToken() error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("%w: access token request failed: HTTP %d", SpotifyError, resp.StatusCode)
+		return fmt.Errorf("%w: access token request failed: %w", SpotifyError, statusError("spotify", resp))
 	}
 
 	var data map[string]interface{}
@@ -143,8 +144,8 @@ func (c *SpotifyClient) getAccessToken() error {
 	return nil
 }
 
-func (c *SpotifyClient) getSessionInfo() error {
-	req, err := http.NewRequest("GET", "https://open.spotify.com", nil)
+func (c *SpotifyClient) getSessionInfo(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://open.spotify.com", nil)
 	if err != nil {
 		return err
 	}
@@ -162,7 +163,7 @@ func (c *SpotifyClient) getSessionInfo() error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("%w: session initialization failed: HTTP %d", SpotifyError, resp.StatusCode)
+		return fmt.Errorf("%w: session initialization failed: %w", SpotifyError, statusError("spotify", resp))
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -192,12 +193,12 @@ func (c *SpotifyClient) getSessionInfo() error {
 	return nil
 }
 
-func (c *SpotifyClient) getClientToken() error {
+func (c *SpotifyClient) getClientToken(ctx context.Context) error {
 	if c.clientID == "" || c.deviceID == "" || c.clientVersion == "" {
-		if err := c.getSessionInfo(); err != nil {
+		if err := c.getSessionInfo(ctx); err != nil {
 			return err
 		}
-		if err := c.getAccessToken(); err != nil {
+		if err := c.getAccessToken(ctx); err != nil {
 			return err
 		}
 	}
@@ -222,7 +223,7 @@ func (c *SpotifyClient) getClientToken() error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", "https://clienttoken.spotify.com/v1/clienttoken", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://clienttoken.spotify.com/v1/clienttoken", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
@@ -239,7 +240,7 @@ func (c *SpotifyClient) getClientToken() error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("%w: client token request failed: HTTP %d", SpotifyError, resp.StatusCode)
+		return fmt.Errorf("%w: client token request failed: %w", SpotifyError, statusError("spotify", resp))
 	}
 
 	var data map[string]interface{}
@@ -257,19 +258,19 @@ func (c *SpotifyClient) getClientToken() error {
 	return nil
 }
 
-func (c *SpotifyClient) Initialize() error {
-	if err := c.getSessionInfo(); err != nil {
+func (c *SpotifyClient) Initialize(ctx context.Context) error {
+	if err := c.getSessionInfo(ctx); err != nil {
 		return err
 	}
-	if err := c.getAccessToken(); err != nil {
+	if err := c.getAccessToken(ctx); err != nil {
 		return err
 	}
-	return c.getClientToken()
+	return c.getClientToken(ctx)
 }
 
-func (c *SpotifyClient) Query(payload map[string]interface{}) (map[string]interface{}, error) {
+func (c *SpotifyClient) Query(ctx context.Context, payload map[string]interface{}) (map[string]interface{}, error) {
 	if c.accessToken == "" || c.clientToken == "" {
-		if err := c.Initialize(); err != nil {
+		if err := c.Initialize(ctx); err != nil {
 			return nil, err
 		}
 	}
@@ -279,7 +280,7 @@ func (c *SpotifyClient) Query(payload map[string]interface{}) (map[string]interf
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", "https://api-partner.spotify.com/pathfinder/v2/query", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api-partner.spotify.com/pathfinder/v2/query", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
@@ -306,7 +307,7 @@ func (c *SpotifyClient) Query(payload map[string]interface{}) (map[string]interf
 		if len(errorText) > 200 {
 			errorText = errorText[:200]
 		}
-		return nil, fmt.Errorf("%w: API query failed: HTTP %d | %s", SpotifyError, resp.StatusCode, errorText)
+		return nil, fmt.Errorf("%w: API query failed: %w | %s", SpotifyError, statusError("spotify", resp), errorText)
 	}
 
 	var result map[string]interface{}
@@ -378,6 +379,9 @@ func extractArtists(artistsData map[string]interface{}) []map[string]interface{}
 		artistInfo := map[string]interface{}{
 			"name": getString(profile, "name"),
 		}
+		if uri := getString(itemMap, "uri"); strings.Contains(uri, ":") {
+			artistInfo["id"] = uri[strings.LastIndex(uri, ":")+1:]
+		}
 		artists = append(artists, artistInfo)
 	}
 	return artists
@@ -699,10 +703,11 @@ func FilterTrack(data map[string]interface{}, albumFetchData ...map[string]inter
 
 		albumArtistsString := ""
 		albumLabel := ""
+		var albumArtists []map[string]interface{}
 		if albumFetchDataMap != nil && len(albumFetchDataMap) > 0 {
 			albumUnionData := getMap(getMap(albumFetchDataMap, "data"), "albumUnion")
 			if len(albumUnionData) > 0 {
-				albumArtists := extractArtists(getMap(albumUnionData, "artists"))
+				albumArtists = extractArtists(getMap(albumUnionData, "artists"))
 				if len(albumArtists) > 0 {
 					albumArtistNames := []string{}
 					for _, artist := range albumArtists {
@@ -715,7 +720,7 @@ func FilterTrack(data map[string]interface{}, albumFetchData ...map[string]inter
 		}
 
 		if albumArtistsString == "" {
-			albumArtists := extractArtists(getMap(albumData, "artists"))
+			albumArtists = extractArtists(getMap(albumData, "artists"))
 			if len(albumArtists) > 0 {
 				albumArtistNames := []string{}
 				for _, artist := range albumArtists {
@@ -735,6 +740,7 @@ func FilterTrack(data map[string]interface{}, albumFetchData ...map[string]inter
 
 		if albumArtistsString != "" {
 			albumInfo["artists"] = albumArtistsString
+			albumInfo["artists_data"] = albumArtists
 		}
 
 		if albumLabel != "" {
@@ -785,6 +791,9 @@ func FilterTrack(data map[string]interface{}, albumFetchData ...map[string]inter
 		"plays":     getString(trackData, "playcount"),
 		"cover":     cover,
 		"isrc":      getString(getMap(trackData, "externalIds"), "isrc"),
+		"explicit":  getString(getMap(trackData, "contentRating"), "label") == "EXPLICIT",
+
+		"artists_data": artists,
 	}
 
 	return filtered
@@ -1201,6 +1210,7 @@ func extractRelease(release map[string]interface{}) map[string]interface{} {
 	return map[string]interface{}{
 		"id":    releaseID,
 		"name":  getString(release, "name"),
+		"type":  strings.ToLower(getString(release, "type")),
 		"cover": cover,
 		"date":  releaseDate,
 		"year":  year,
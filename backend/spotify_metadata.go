@@ -24,7 +24,7 @@ type SpotifyMetadataClient struct {
 
 func NewSpotifyMetadataClient() *SpotifyMetadataClient {
 	return &SpotifyMetadataClient{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: newHTTPClient("spotify", 30*time.Second),
 	}
 }
 
@@ -47,6 +47,11 @@ type TrackMetadata struct {
 	Publisher   string `json:"publisher,omitempty"`
 	Plays       string `json:"plays,omitempty"`
 	PreviewURL  string `json:"preview_url,omitempty"`
+	AlbumID     string `json:"album_id,omitempty"`
+	Explicit    bool   `json:"explicit,omitempty"`
+
+	ArtistsData      []ArtistSimple `json:"artists_data,omitempty"`
+	AlbumArtistsData []ArtistSimple `json:"album_artists_data,omitempty"`
 }
 
 type ArtistSimple struct {
@@ -185,20 +190,28 @@ type apiTrackResponse struct {
 	Copyright string `json:"copyright"`
 	Plays     string `json:"plays"`
 	ISRC      string `json:"isrc"`
+	Explicit  bool   `json:"explicit"`
 	Album     struct {
-		ID       string `json:"id"`
-		Name     string `json:"name"`
-		Released string `json:"released"`
-		Year     int    `json:"year"`
-		Tracks   int    `json:"tracks"`
-		Artists  string `json:"artists"`
-		Label    string `json:"label"`
+		ID          string          `json:"id"`
+		Name        string          `json:"name"`
+		Released    string          `json:"released"`
+		Year        int             `json:"year"`
+		Tracks      int             `json:"tracks"`
+		Artists     string          `json:"artists"`
+		ArtistsData []apiArtistName `json:"artists_data"`
+		Label       string          `json:"label"`
 	} `json:"album"`
 	Cover struct {
 		Small  string `json:"small"`
 		Medium string `json:"medium"`
 		Large  string `json:"large"`
 	} `json:"cover"`
+	ArtistsData []apiArtistName `json:"artists_data"`
+}
+
+type apiArtistName struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 type apiAlbumResponse struct {
@@ -264,6 +277,7 @@ type apiArtistResponse struct {
 		All []struct {
 			ID    string `json:"id"`
 			Name  string `json:"name"`
+			Type  string `json:"type"`
 			Cover string `json:"cover"`
 			Date  string `json:"date"`
 			Year  int    `json:"year"`
@@ -385,7 +399,7 @@ func (c *SpotifyMetadataClient) processSpotifyData(ctx context.Context, raw inte
 
 func (c *SpotifyMetadataClient) fetchTrack(ctx context.Context, trackID string) (*apiTrackResponse, error) {
 	client := NewSpotifyClient()
-	if err := client.Initialize(); err != nil {
+	if err := client.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize spotify client: %w", err)
 	}
 
@@ -402,7 +416,7 @@ func (c *SpotifyMetadataClient) fetchTrack(ctx context.Context, trackID string)
 		},
 	}
 
-	data, err := client.Query(payload)
+	data, err := client.Query(ctx, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query track: %w", err)
 	}
@@ -439,7 +453,7 @@ func (c *SpotifyMetadataClient) fetchTrack(ctx context.Context, trackID string)
 							},
 						},
 					}
-					albumFetchData, _ = client.Query(albumPayload)
+					albumFetchData, _ = client.Query(ctx, albumPayload)
 				}
 			}
 		}
@@ -462,7 +476,7 @@ func (c *SpotifyMetadataClient) fetchTrack(ctx context.Context, trackID string)
 
 func (c *SpotifyMetadataClient) fetchAlbum(ctx context.Context, albumID string) (*apiAlbumResponse, error) {
 	client := NewSpotifyClient()
-	if err := client.Initialize(); err != nil {
+	if err := client.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize spotify client: %w", err)
 	}
 
@@ -489,7 +503,7 @@ func (c *SpotifyMetadataClient) fetchAlbum(ctx context.Context, albumID string)
 			},
 		}
 
-		response, err := client.Query(payload)
+		response, err := client.Query(ctx, payload)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query album: %w", err)
 		}
@@ -555,7 +569,7 @@ func (c *SpotifyMetadataClient) fetchAlbum(ctx context.Context, albumID string)
 
 func (c *SpotifyMetadataClient) fetchPlaylist(ctx context.Context, playlistID string) (*apiPlaylistResponse, error) {
 	client := NewSpotifyClient()
-	if err := client.Initialize(); err != nil {
+	if err := client.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize spotify client: %w", err)
 	}
 
@@ -582,7 +596,7 @@ func (c *SpotifyMetadataClient) fetchPlaylist(ctx context.Context, playlistID st
 			},
 		}
 
-		response, err := client.Query(payload)
+		response, err := client.Query(ctx, payload)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query playlist: %w", err)
 		}
@@ -648,7 +662,7 @@ func (c *SpotifyMetadataClient) fetchPlaylist(ctx context.Context, playlistID st
 
 func (c *SpotifyMetadataClient) fetchArtistDiscography(ctx context.Context, parsed spotifyURI) (*apiArtistResponse, error) {
 	client := NewSpotifyClient()
-	if err := client.Initialize(); err != nil {
+	if err := client.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize spotify client: %w", err)
 	}
 
@@ -666,7 +680,7 @@ func (c *SpotifyMetadataClient) fetchArtistDiscography(ctx context.Context, pars
 		},
 	}
 
-	data, err := client.Query(overviewPayload)
+	data, err := client.Query(ctx, overviewPayload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query artist overview: %w", err)
 	}
@@ -693,7 +707,7 @@ func (c *SpotifyMetadataClient) fetchArtistDiscography(ctx context.Context, pars
 			},
 		}
 
-		response, err := client.Query(discographyPayload)
+		response, err := client.Query(ctx, discographyPayload)
 		if err != nil {
 			break
 		}
@@ -809,6 +823,21 @@ func (c *SpotifyMetadataClient) fetchArtistDiscography(ctx context.Context, pars
 	return &result, nil
 }
 
+func artistSimples(artists []apiArtistName) []ArtistSimple {
+	var simples []ArtistSimple
+	for _, artist := range artists {
+		if artist.Name == "" {
+			continue
+		}
+		simple := ArtistSimple{ID: artist.ID, Name: artist.Name}
+		if artist.ID != "" {
+			simple.ExternalURL = fmt.Sprintf("https://open.spotify.com/artist/%s", artist.ID)
+		}
+		simples = append(simples, simple)
+	}
+	return simples
+}
+
 func (c *SpotifyMetadataClient) formatTrackData(raw *apiTrackResponse) TrackResponse {
 	durationMS := parseDuration(raw.Duration)
 
@@ -844,6 +873,11 @@ func (c *SpotifyMetadataClient) formatTrackData(raw *apiTrackResponse) TrackResp
 		Copyright:   raw.Copyright,
 		Publisher:   raw.Album.Label,
 		Plays:       raw.Plays,
+		AlbumID:     raw.Album.ID,
+		Explicit:    raw.Explicit,
+
+		ArtistsData:      artistSimples(raw.ArtistsData),
+		AlbumArtistsData: artistSimples(raw.Album.ArtistsData),
 	}
 
 	return TrackResponse{
@@ -977,10 +1011,16 @@ func (c *SpotifyMetadataClient) formatPlaylistData(raw *apiPlaylistResponse) Pla
 func (c *SpotifyMetadataClient) formatArtistDiscographyData(ctx context.Context, raw *apiArtistResponse) (*ArtistDiscographyPayload, error) {
 	discType := "all"
 
+	genres, err := NewSpotifyClient().GetArtistGenres(ctx, raw.ID)
+	if err != nil {
+		spotifyLog.Debug("artist genres unavailable", "artist_id", raw.ID, "error", err)
+		genres = []string{}
+	}
+
 	info := ArtistInfoMetadata{
 		Name:            raw.Name,
 		Followers:       raw.Stats.Followers,
-		Genres:          []string{},
+		Genres:          genres,
 		Images:          raw.Avatar,
 		Header:          raw.Header,
 		Gallery:         raw.Gallery,
@@ -1010,10 +1050,15 @@ func (c *SpotifyMetadataClient) formatArtistDiscographyData(ctx context.Context,
 
 		}
 
+		albumType := alb.Type
+		if albumType == "" {
+			albumType = "album"
+		}
+
 		albumList = append(albumList, DiscographyAlbumMetadata{
 			ID:          alb.ID,
 			Name:        alb.Name,
-			AlbumType:   "album",
+			AlbumType:   albumType,
 			ReleaseDate: alb.Date,
 			TotalTracks: 0,
 			Artists:     raw.Name,
@@ -1023,7 +1068,7 @@ func (c *SpotifyMetadataClient) formatArtistDiscographyData(ctx context.Context,
 
 		albumData, err := c.fetchAlbum(ctx, alb.ID)
 		if err != nil {
-			fmt.Printf("Error getting tracks for album %s: %v\n", alb.Name, err)
+			spotifyLog.Warn("failed to get album tracks", "album", alb.Name, "error", err)
 			continue
 		}
 
@@ -1052,7 +1097,7 @@ func (c *SpotifyMetadataClient) formatArtistDiscographyData(ctx context.Context,
 				Name:        tr.Name,
 				AlbumName:   albumData.Name,
 				AlbumArtist: albumData.Artists,
-				AlbumType:   "album",
+				AlbumType:   albumType,
 				DurationMS:  durationMS,
 				Images:      albumData.Cover,
 				ReleaseDate: albumData.ReleaseDate,
@@ -1189,7 +1234,7 @@ func (c *SpotifyMetadataClient) Search(ctx context.Context, query string, limit
 	}
 
 	client := NewSpotifyClient()
-	if err := client.Initialize(); err != nil {
+	if err := client.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize spotify client: %w", err)
 	}
 
@@ -1213,7 +1258,7 @@ func (c *SpotifyMetadataClient) Search(ctx context.Context, query string, limit
 		},
 	}
 
-	data, err := client.Query(payload)
+	data, err := client.Query(ctx, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query search: %w", err)
 	}
@@ -1305,7 +1350,7 @@ func (c *SpotifyMetadataClient) SearchByType(ctx context.Context, query string,
 	}
 
 	client := NewSpotifyClient()
-	if err := client.Initialize(); err != nil {
+	if err := client.Initialize(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize spotify client: %w", err)
 	}
 
@@ -1329,7 +1374,7 @@ func (c *SpotifyMetadataClient) SearchByType(ctx context.Context, query string,
 		},
 	}
 
-	data, err := client.Query(payload)
+	data, err := client.Query(ctx, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query search: %w", err)
 	}
@@ -1407,15 +1452,64 @@ func SearchSpotifyByType(ctx context.Context, query string, searchType string, l
 	return client.SearchByType(ctx, query, searchType, limit, offset)
 }
 
-func GetPreviewURL(trackID string) (string, error) {
+type ArtistRelease struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	ReleaseDate string `json:"release_date"`
+	ExternalURL string `json:"external_urls"`
+}
+
+func GetArtistReleases(ctx context.Context, artistID string) (string, []ArtistRelease, error) {
+	client := NewSpotifyMetadataClient()
+	raw, err := client.fetchArtistDiscography(ctx, spotifyURI{Type: "artist_discography", ID: artistID, DiscographyGroup: "all"})
+	if err != nil {
+		return "", nil, err
+	}
+
+	releases := make([]ArtistRelease, 0, len(raw.Discography.All))
+	for _, alb := range raw.Discography.All {
+		releaseType := alb.Type
+		if releaseType == "" {
+			releaseType = "album"
+		}
+		releases = append(releases, ArtistRelease{
+			ID:          alb.ID,
+			Name:        alb.Name,
+			Type:        releaseType,
+			ReleaseDate: alb.Date,
+			ExternalURL: fmt.Sprintf("https://open.spotify.com/album/%s", alb.ID),
+		})
+	}
+
+	return raw.Name, releases, nil
+}
+
+func ParseSpotifyArtistID(input string) (string, error) {
+	trimmed := strings.TrimSpace(input)
+	if len(trimmed) == 22 && !strings.ContainsAny(trimmed, ":/") {
+		return trimmed, nil
+	}
+
+	parsed, err := parseSpotifyURI(trimmed)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Type != "artist" && parsed.Type != "artist_discography" {
+		return "", fmt.Errorf("not an artist URL: %s", input)
+	}
+	return parsed.ID, nil
+}
+
+func GetPreviewURL(ctx context.Context, trackID string) (string, error) {
 	if trackID == "" {
 		return "", errors.New("track ID cannot be empty")
 	}
 
 	embedURL := fmt.Sprintf("https://open.spotify.com/embed/track/%s", trackID)
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(embedURL)
+	client := newHTTPClient("spotify", 15*time.Second)
+	resp, err := httpGet(ctx, client, embedURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch embed page: %w", err)
 	}
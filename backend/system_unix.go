@@ -3,6 +3,7 @@
 package backend
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"runtime"
@@ -39,3 +40,8 @@ func GetOSInfo() (string, error) {
 		return fmt.Sprintf("%s %s", osType, arch), nil
 	}
 }
+
+// shellCommand runs command through the user's shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"runtime"
@@ -39,3 +40,10 @@ func GetOSInfo() (string, error) {
 	}
 	return strings.TrimSpace(string(out)), nil
 }
+
+// shellCommand runs command through cmd.exe
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd", "/C", command)
+	setHideWindow(cmd)
+	return cmd
+}
@@ -0,0 +1,558 @@
+package backend
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	id3v2 "github.com/bogem/id3v2/v2"
+	"github.com/go-flac/flacpicture"
+	"github.com/go-flac/flacvorbis"
+	"github.com/go-flac/go-flac"
+)
+
+// TagField is one tag value under its Vorbis comment name. Fields with
+// several values appear once per value.
+type TagField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// FileTags are the tags read from a FLAC, MP3 or M4A file
+type FileTags struct {
+	Fields   []TagField `json:"fields"`
+	HasCover bool       `json:"has_cover"`
+	Cover    []byte     `json:"-"`
+
+	// yearOnlyDate is set when DATE can only hold a year, as in ID3v2.3
+	yearOnlyDate bool
+}
+
+// Values returns the values of the field called name
+func (t *FileTags) Values(name string) []string {
+	var values []string
+	for _, field := range t.Fields {
+		if strings.EqualFold(field.Name, name) {
+			values = append(values, field.Value)
+		}
+	}
+	return values
+}
+
+// TagEdit is a change to a file's tags. Set replaces every value of the
+// fields it names, Remove deletes fields, and everything else in the file is
+// left alone.
+type TagEdit struct {
+	Set         []TagField
+	Remove      []string
+	Cover       []byte
+	RemoveCover bool
+}
+
+// tagFieldFormats lists the fields that have a standard ID3v2 frame or MP4
+// atom, in the order they are shown. Other fields are TXXX frames and
+// freeform atoms. DATE is TYER in ID3v2.3.
+var tagFieldFormats = []struct {
+	name, id3, mp4 string
+}{
+	{"TITLE", "TIT2", "\xa9nam"},
+	{"ARTIST", "TPE1", "\xa9ART"},
+	{"ALBUM", "TALB", "\xa9alb"},
+	{"ALBUMARTIST", "TPE2", "aART"},
+	{"DATE", "TDRC", "\xa9day"},
+	{"TRACKNUMBER", "TRCK", "trkn"},
+	{"TOTALTRACKS", "TRCK", "trkn"},
+	{"DISCNUMBER", "TPOS", "disk"},
+	{"TOTALDISCS", "TPOS", "disk"},
+	{"GENRE", "TCON", "\xa9gen"},
+	{"COMPOSER", "TCOM", "\xa9wrt"},
+	{"LYRICIST", "TEXT", ""},
+	{"GROUPING", "TIT1", "\xa9grp"},
+	{"COPYRIGHT", "TCOP", "cprt"},
+	{"PUBLISHER", "TPUB", ""},
+	{"ISRC", "TSRC", ""},
+	{"ENCODER", "TSSE", "\xa9too"},
+	{"COMMENT", "COMM", "\xa9cmt"},
+	{"LYRICS", "USLT", "\xa9lyr"},
+}
+
+// id3MultiValueFrames hold one value per name. ID3v2.3 has no value
+// separator of its own, so these are joined and split on "/" there.
+var id3MultiValueFrames = map[string]bool{"TPE1": true, "TPE2": true, "TCON": true, "TCOM": true, "TEXT": true}
+
+// tagFieldAliases are other names commonly used for the same fields
+var tagFieldAliases = map[string]string{
+	"ALBUM ARTIST":   "ALBUMARTIST",
+	"ALBUM_ARTIST":   "ALBUMARTIST",
+	"YEAR":           "DATE",
+	"TRACK":          "TRACKNUMBER",
+	"DISC":           "DISCNUMBER",
+	"TRACKTOTAL":     "TOTALTRACKS",
+	"DISCTOTAL":      "TOTALDISCS",
+	"DESCRIPTION":    "COMMENT",
+	"UNSYNCEDLYRICS": "LYRICS",
+}
+
+// CanonicalTagName returns the upper-case field name tags are read and
+// written under, resolving aliases such as YEAR for DATE
+func CanonicalTagName(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if alias, ok := tagFieldAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// numberPair returns the two fields stored together in TRCK/trkn or
+// TPOS/disk, or ok false for other fields
+func numberPair(name string) (number, total string, ok bool) {
+	switch name {
+	case "TRACKNUMBER", "TOTALTRACKS":
+		return "TRACKNUMBER", "TOTALTRACKS", true
+	case "DISCNUMBER", "TOTALDISCS":
+		return "DISCNUMBER", "TOTALDISCS", true
+	}
+	return "", "", false
+}
+
+func id3FrameFor(name string) string {
+	for _, format := range tagFieldFormats {
+		if format.name == name {
+			return format.id3
+		}
+	}
+	return ""
+}
+
+func mp4AtomFor(name string) string {
+	for _, format := range tagFieldFormats {
+		if format.name == name {
+			return format.mp4
+		}
+	}
+	return ""
+}
+
+// isID3TextFrame reports whether id is a standard ID3v2 text frame, which
+// can be set by its ID as well as by a field name
+func isID3TextFrame(id string) bool {
+	if len(id) != 4 || id[0] != 'T' || id == "TXXX" {
+		return false
+	}
+	for _, ids := range []map[string]string{id3v2.V23CommonIDs, id3v2.V24CommonIDs} {
+		for _, known := range ids {
+			if known == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadTags reads every text tag and the cover art of a FLAC, MP3 or M4A file
+func ReadTags(filePath string) (*FileTags, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".flac":
+		return readFLACTags(filePath)
+	case ".mp3":
+		return readMP3Tags(filePath)
+	case ".m4a":
+		return readM4ATags(filePath)
+	default:
+		return nil, fmt.Errorf("unsupported file format: %s", filepath.Ext(filePath))
+	}
+}
+
+func readFLACTags(filePath string) (*FileTags, error) {
+	f, err := flac.ParseFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FLAC file: %w", err)
+	}
+
+	tags := &FileTags{}
+	for _, block := range f.Meta {
+		switch block.Type {
+		case flac.VorbisComment:
+			cmt, err := flacvorbis.ParseFromMetaDataBlock(*block)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse Vorbis comments: %w", err)
+			}
+			for _, field := range parseVorbisFields(cmt.Comments) {
+				tags.Fields = append(tags.Fields, TagField{strings.ToUpper(field.name), field.value})
+			}
+		case flac.Picture:
+			if pic, err := flacpicture.ParseFromMetaDataBlock(*block); err == nil && tags.Cover == nil {
+				tags.Cover = pic.ImageData
+			}
+			tags.HasCover = true
+		}
+	}
+	return tags, nil
+}
+
+func readMP3Tags(filePath string) (*FileTags, error) {
+	tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MP3 file: %w", err)
+	}
+	defer tag.Close()
+
+	tags := &FileTags{yearOnlyDate: tag.Version() < 4}
+	values := make(map[string][]string)
+	add := func(name string, value string) {
+		if value != "" {
+			values[name] = append(values[name], value)
+		}
+	}
+	for id, frames := range tag.AllFrames() {
+		for _, frame := range frames {
+			switch frame := frame.(type) {
+			case id3v2.UserDefinedTextFrame:
+				add(strings.ToUpper(frame.Description), frame.Value)
+			case id3v2.CommentFrame:
+				// Described comments are player data such as iTunNORM
+				if frame.Description == "" {
+					add("COMMENT", frame.Text)
+				}
+			case id3v2.UnsynchronisedLyricsFrame:
+				add("LYRICS", frame.Lyrics)
+			case id3v2.PictureFrame:
+				if !tags.HasCover {
+					tags.Cover, tags.HasCover = frame.Picture, true
+				}
+			case id3v2.TextFrame:
+				text := strings.TrimRight(frame.Text, "\x00")
+				switch id {
+				case "TRCK":
+					number, total, _ := strings.Cut(text, "/")
+					add("TRACKNUMBER", strings.TrimSpace(number))
+					add("TOTALTRACKS", strings.TrimSpace(total))
+					continue
+				case "TPOS":
+					number, total, _ := strings.Cut(text, "/")
+					add("DISCNUMBER", strings.TrimSpace(number))
+					add("TOTALDISCS", strings.TrimSpace(total))
+					continue
+				}
+				name := id
+				if id == "TYER" {
+					name = "DATE"
+				}
+				for _, format := range tagFieldFormats {
+					if format.id3 == id {
+						name = format.name
+						break
+					}
+				}
+				// ID3v2.4 separates multiple values with null bytes
+				separator := "\x00"
+				if tag.Version() < 4 && id3MultiValueFrames[id] {
+					separator = "/"
+				}
+				for _, value := range strings.Split(text, separator) {
+					add(name, strings.TrimSpace(value))
+				}
+			}
+		}
+	}
+	tags.Fields = orderedTagFields(values)
+	return tags, nil
+}
+
+func readM4ATags(filePath string) (*FileTags, error) {
+	existing, err := readMP4Tags(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read M4A tags: %w", err)
+	}
+
+	values := make(map[string][]string)
+	for atom, texts := range existing.text {
+		name := atom
+		for _, format := range tagFieldFormats {
+			if format.mp4 == atom {
+				name = format.name
+				break
+			}
+		}
+		values[name] = append(values[name], texts...)
+	}
+	for atom, pair := range existing.numbers {
+		number, total := "TRACKNUMBER", "TOTALTRACKS"
+		if atom == "disk" {
+			number, total = "DISCNUMBER", "TOTALDISCS"
+		}
+		if pair[0] > 0 {
+			values[number] = []string{strconv.Itoa(pair[0])}
+		}
+		if pair[1] > 0 {
+			values[total] = []string{strconv.Itoa(pair[1])}
+		}
+	}
+	for _, field := range existing.freeform {
+		name := strings.ToUpper(field.name)
+		values[name] = append(values[name], field.value)
+	}
+
+	return &FileTags{
+		Fields:   orderedTagFields(values),
+		HasCover: existing.atoms["covr"],
+		Cover:    existing.cover,
+	}, nil
+}
+
+// orderedTagFields flattens values into fields, the standard fields first
+func orderedTagFields(values map[string][]string) []TagField {
+	var fields []TagField
+	appendField := func(name string) {
+		for _, value := range values[name] {
+			fields = append(fields, TagField{name, value})
+		}
+		delete(values, name)
+	}
+	for _, format := range tagFieldFormats {
+		appendField(format.name)
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		appendField(name)
+	}
+	return fields
+}
+
+// EditTags applies edit to a FLAC, MP3 or M4A file. Edits are explicit, so
+// they always overwrite, whatever the tag-merge setting.
+func EditTags(filePath string, edit TagEdit) error {
+	current, err := ReadTags(filePath)
+	if err != nil {
+		return err
+	}
+
+	// The new values of every field the edit touches, nil for removed ones
+	changes := make(map[string][]string)
+	for _, name := range edit.Remove {
+		changes[CanonicalTagName(name)] = nil
+	}
+	for _, field := range edit.Set {
+		name := CanonicalTagName(field.Name)
+		if field.Value != "" {
+			changes[name] = append(changes[name], field.Value)
+		} else if _, ok := changes[name]; !ok {
+			changes[name] = nil
+		}
+	}
+	if len(changes) == 0 && edit.Cover == nil && !edit.RemoveCover {
+		return nil
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".flac":
+		return editFLACTags(filePath, changes, edit)
+	case ".mp3":
+		return editMP3Tags(filePath, current, changes, edit)
+	default:
+		return editM4ATags(filePath, current, changes, edit)
+	}
+}
+
+// changedNumbers returns the number and total that are stored together for
+// a pair of fields once changes are applied
+func changedNumbers(current *FileTags, changes map[string][]string, numberName string) (number, total int) {
+	value := func(name string) int {
+		values, ok := changes[name]
+		if !ok {
+			values = current.Values(name)
+		}
+		if len(values) == 0 {
+			return 0
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(values[0]))
+		return n
+	}
+	_, totalName, _ := numberPair(numberName)
+	return value(numberName), value(totalName)
+}
+
+func editFLACTags(filePath string, changes map[string][]string, edit TagEdit) error {
+	f, err := flac.ParseFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse FLAC file: %w", err)
+	}
+
+	var managed []tagField
+	for _, name := range slices.Sorted(maps.Keys(changes)) {
+		managed = append(managed, tagField{name, ""})
+		for _, value := range changes[name] {
+			managed = append(managed, tagField{name, value})
+		}
+	}
+	if len(managed) > 0 {
+		mergeVorbisComment(f, managed, TagMergeOverwrite)
+	}
+
+	if edit.RemoveCover {
+		f.Meta = slices.DeleteFunc(f.Meta, func(block *flac.MetaDataBlock) bool {
+			return block.Type == flac.Picture
+		})
+	}
+	if edit.Cover != nil {
+		if err := setFLACPicture(f, edit.Cover); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Save(filePath); err != nil {
+		return fmt.Errorf("failed to save FLAC file: %w", err)
+	}
+	return nil
+}
+
+func editMP3Tags(filePath string, current *FileTags, changes map[string][]string, edit TagEdit) error {
+	tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+	if err != nil {
+		return fmt.Errorf("failed to open MP3 file: %w", err)
+	}
+	defer tag.Close()
+
+	setText := func(id string, values []string) {
+		tag.DeleteFrames(id)
+		if len(values) == 0 {
+			return
+		}
+		separator := "/"
+		if tag.Version() >= 4 {
+			separator = "\x00"
+		}
+		tag.AddTextFrame(id, id3v2.EncodingUTF8, strings.Join(values, separator))
+	}
+
+	var userDefined []tagField
+	for _, name := range slices.Sorted(maps.Keys(changes)) {
+		values := changes[name]
+		if numberName, _, ok := numberPair(name); ok {
+			var value []string
+			switch number, total := changedNumbers(current, changes, numberName); {
+			case number > 0 && total > 0:
+				value = []string{fmt.Sprintf("%d/%d", number, total)}
+			case number > 0:
+				value = []string{strconv.Itoa(number)}
+			}
+			setText(id3FrameFor(numberName), value)
+			continue
+		}
+
+		switch id := id3FrameFor(name); {
+		case name == "DATE":
+			tag.DeleteFrames("TYER")
+			tag.DeleteFrames("TDRC")
+			if len(values) > 0 && tag.Version() < 4 {
+				values = []string{extractYear(values[0])}
+			}
+			setText(tag.CommonID("Year"), values)
+		case id == "COMM":
+			// Only the plain comment; described ones belong to players
+			var kept []id3v2.CommentFrame
+			for _, frame := range tag.GetFrames("COMM") {
+				if comment, ok := frame.(id3v2.CommentFrame); ok && comment.Description != "" {
+					kept = append(kept, comment)
+				}
+			}
+			tag.DeleteFrames("COMM")
+			for _, comment := range kept {
+				tag.AddCommentFrame(comment)
+			}
+			for _, value := range values {
+				tag.AddCommentFrame(id3v2.CommentFrame{Encoding: id3v2.EncodingUTF8, Language: "eng", Text: value})
+			}
+		case id == "USLT":
+			tag.DeleteFrames("USLT")
+			for _, value := range values {
+				tag.AddUnsynchronisedLyricsFrame(id3v2.UnsynchronisedLyricsFrame{Encoding: id3v2.EncodingUTF8, Language: "eng", Lyrics: value})
+			}
+		case id != "":
+			setText(id, values)
+		case isID3TextFrame(name):
+			setText(name, values)
+		default:
+			userDefined = append(userDefined, tagField{name, ""})
+			for _, value := range values {
+				userDefined = append(userDefined, tagField{name, value})
+			}
+		}
+	}
+
+	if len(userDefined) > 0 {
+		var existing []tagField
+		for _, frame := range tag.GetFrames("TXXX") {
+			if udtf, ok := frame.(id3v2.UserDefinedTextFrame); ok {
+				existing = append(existing, tagField{udtf.Description, udtf.Value})
+			}
+		}
+		tag.DeleteFrames("TXXX")
+		for _, field := range mergeTagFields(existing, userDefined, TagMergeOverwrite) {
+			tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+				Encoding:    id3v2.EncodingUTF8,
+				Description: field.name,
+				Value:       field.value,
+			})
+		}
+	}
+
+	pictureID := tag.CommonID("Attached picture")
+	if edit.RemoveCover || edit.Cover != nil {
+		tag.DeleteFrames(pictureID)
+	}
+	if edit.Cover != nil {
+		tag.AddAttachedPicture(id3v2.PictureFrame{
+			Encoding:    id3v2.EncodingUTF8,
+			MimeType:    coverMIMEType(edit.Cover),
+			PictureType: id3v2.PTFrontCover,
+			Description: "Cover",
+			Picture:     edit.Cover,
+		})
+	}
+
+	if err := tag.Save(); err != nil {
+		return fmt.Errorf("failed to save MP3 tags: %w", err)
+	}
+	return nil
+}
+
+func editM4ATags(filePath string, current *FileTags, changes map[string][]string, edit TagEdit) error {
+	update := mp4ItemUpdate{
+		text:    make(map[string][]string),
+		numbers: make(map[string][2]int),
+		cover:   edit.Cover,
+	}
+	for _, name := range slices.Sorted(maps.Keys(changes)) {
+		values := changes[name]
+		if numberName, _, ok := numberPair(name); ok {
+			number, total := changedNumbers(current, changes, numberName)
+			update.numbers[mp4AtomFor(numberName)] = [2]int{number, total}
+			continue
+		}
+		if atom := mp4AtomFor(name); atom != "" {
+			update.text[atom] = values
+			continue
+		}
+
+		update.clear = append(update.clear, name)
+		for _, value := range values {
+			update.freeform = append(update.freeform, tagField{name, value})
+		}
+	}
+	if edit.RemoveCover && edit.Cover == nil {
+		update.text["covr"] = nil
+	}
+
+	if err := writeMP4Items(filePath, update); err != nil {
+		return fmt.Errorf("failed to write MP4 items: %w", err)
+	}
+	return nil
+}
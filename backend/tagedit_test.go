@@ -0,0 +1,212 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	id3v2 "github.com/bogem/id3v2/v2"
+	"github.com/go-flac/go-flac"
+)
+
+// writeTestFLAC writes a FLAC file with a STREAMINFO block and a stub frame,
+// enough for the tag reader and writer
+func writeTestFLAC(t *testing.T, path string) {
+	t.Helper()
+	info := make([]byte, 34)
+	binary.BigEndian.PutUint16(info[0:], 4096)
+	binary.BigEndian.PutUint16(info[2:], 4096)
+	// 44.1kHz, 2 channels, 16 bits per sample, no samples
+	binary.BigEndian.PutUint64(info[10:], 44100<<44|1<<41|15<<36)
+	f := &flac.File{
+		Meta:   []*flac.MetaDataBlock{{Type: flac.StreamInfo, Data: info}},
+		Frames: append([]byte{0xFF, 0xF8}, make([]byte, 14)...),
+	}
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTestMP3 writes an MP3 file with an ID3v2 tag of the given version,
+// holding only an encoder frame, in front of some silent frames
+func writeTestMP3(t *testing.T, path string, version byte) {
+	t.Helper()
+	frame := append([]byte{0xFF, 0xFB, 0x90, 0x64}, make([]byte, 413)...)
+	if err := os.WriteFile(path, bytes.Repeat(frame, 8), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tag.Close()
+	tag.SetVersion(version)
+	// An empty tag isn't written, and with it the version would be lost
+	tag.AddTextFrame("TSSE", id3v2.EncodingUTF8, "test")
+	if err := tag.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testCover(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEditTagsRoundTrip(t *testing.T) {
+	cover := testCover(t)
+	edit := TagEdit{
+		Set: []TagField{
+			{"TITLE", "Title"},
+			{"ARTIST", "Artist A"},
+			{"ARTIST", "Artist B"},
+			{"ALBUM", "Album"},
+			{"DATE", "2021-03-04"},
+			{"TRACKNUMBER", "3"},
+			{"TOTALTRACKS", "12"},
+			{"DISCNUMBER", "1"},
+			{"GENRE", "Rock"},
+			{"COMMENT", "A comment"},
+			{"mood", "Calm"},
+		},
+		Cover: cover,
+	}
+	want := map[string][]string{
+		"TITLE":       {"Title"},
+		"ARTIST":      {"Artist A", "Artist B"},
+		"ALBUM":       {"Album"},
+		"DATE":        {"2021-03-04"},
+		"TRACKNUMBER": {"3"},
+		"TOTALTRACKS": {"12"},
+		"DISCNUMBER":  {"1"},
+		"GENRE":       {"Rock"},
+		"COMMENT":     {"A comment"},
+		"MOOD":        {"Calm"},
+	}
+
+	tests := []struct {
+		name  string
+		ext   string
+		write func(t *testing.T, path string)
+	}{
+		{name: "flac", ext: ".flac", write: writeTestFLAC},
+		{name: "mp3", ext: ".mp3", write: func(t *testing.T, path string) { writeTestMP3(t, path, 4) }},
+		{name: "m4a", ext: ".m4a", write: func(t *testing.T, path string) {
+			if err := os.WriteFile(path, buildTestMP4(false), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "track"+tt.ext)
+			tt.write(t, path)
+
+			if err := EditTags(path, edit); err != nil {
+				t.Fatalf("EditTags: %v", err)
+			}
+			tags, err := ReadTags(path)
+			if err != nil {
+				t.Fatalf("ReadTags: %v", err)
+			}
+			for name, values := range want {
+				if got := tags.Values(name); !slices.Equal(got, values) {
+					t.Errorf("%s = %q, want %q", name, got, values)
+				}
+			}
+			if !tags.HasCover || !bytes.Equal(tags.Cover, cover) {
+				t.Error("cover not read back")
+			}
+
+			// Removing fields leaves the others alone
+			if err := EditTags(path, TagEdit{Remove: []string{"ARTIST", "MOOD"}, Set: []TagField{{"TITLE", "New Title"}}, RemoveCover: true}); err != nil {
+				t.Fatalf("EditTags: %v", err)
+			}
+			if tags, err = ReadTags(path); err != nil {
+				t.Fatalf("ReadTags: %v", err)
+			}
+			if got := tags.Values("ARTIST"); len(got) != 0 {
+				t.Errorf("ARTIST = %q after removal", got)
+			}
+			if got := tags.Values("MOOD"); len(got) != 0 {
+				t.Errorf("MOOD = %q after removal", got)
+			}
+			if got := tags.Values("TITLE"); !slices.Equal(got, []string{"New Title"}) {
+				t.Errorf("TITLE = %q, want New Title", got)
+			}
+			if got := tags.Values("ALBUM"); !slices.Equal(got, []string{"Album"}) {
+				t.Errorf("ALBUM = %q, want Album", got)
+			}
+			if tags.HasCover {
+				t.Error("cover not removed")
+			}
+		})
+	}
+}
+
+func TestCanonicalTagName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"title", "TITLE"},
+		{" Album Artist ", "ALBUMARTIST"},
+		{"album_artist", "ALBUMARTIST"},
+		{"year", "DATE"},
+		{"tracktotal", "TOTALTRACKS"},
+		{"unsyncedlyrics", "LYRICS"},
+		{"replaygain_track_gain", "REPLAYGAIN_TRACK_GAIN"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalTagName(tt.name); got != tt.want {
+			t.Errorf("CanonicalTagName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMP3V23MultiValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	writeTestMP3(t, path, 3)
+
+	edit := TagEdit{Set: []TagField{
+		{"TITLE", "AC/DC Live"},
+		{"ARTIST", "Artist A"},
+		{"ARTIST", "Artist B"},
+		{"GENRE", "Rock"},
+		{"GENRE", "Pop"},
+		{"DATE", "2021-03-04"},
+	}}
+	if err := EditTags(path, edit); err != nil {
+		t.Fatalf("EditTags: %v", err)
+	}
+	tags, err := ReadTags(path)
+	if err != nil {
+		t.Fatalf("ReadTags: %v", err)
+	}
+
+	want := map[string][]string{
+		// Only frames written one value per name are split
+		"TITLE":  {"AC/DC Live"},
+		"ARTIST": {"Artist A", "Artist B"},
+		"GENRE":  {"Rock", "Pop"},
+		"DATE":   {"2021"},
+	}
+	for name, values := range want {
+		if got := tags.Values(name); !slices.Equal(got, values) {
+			t.Errorf("%s = %q, want %q", name, got, values)
+		}
+	}
+	if !tags.yearOnlyDate {
+		t.Error("yearOnlyDate not set for ID3v2.3")
+	}
+}
@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TagMergeMode decides what happens to tags already in a file when metadata
+// is embedded
+type TagMergeMode string
+
+const (
+	// TagMergeOverwrite replaces the fields SpotiFLAC writes and keeps
+	// everything else
+	TagMergeOverwrite TagMergeMode = "overwrite"
+	// TagMergeFillMissing only adds fields the file doesn't have yet
+	TagMergeFillMissing TagMergeMode = "fill-missing"
+	// TagMergeReplace drops all existing tags first
+	TagMergeReplace TagMergeMode = "replace-all"
+)
+
+var (
+	tagMergeMode     = TagMergeOverwrite
+	tagMergeModeLock sync.RWMutex
+)
+
+func ParseTagMergeMode(value string) (TagMergeMode, error) {
+	switch mode := TagMergeMode(value); mode {
+	case "":
+		return TagMergeOverwrite, nil
+	case TagMergeOverwrite, TagMergeFillMissing, TagMergeReplace:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid tag merge mode: %s (must be: overwrite, fill-missing or replace-all)", value)
+}
+
+// SetTagMergeMode sets the merge policy used by every embed function
+func SetTagMergeMode(mode TagMergeMode) {
+	tagMergeModeLock.Lock()
+	defer tagMergeModeLock.Unlock()
+	tagMergeMode = mode
+}
+
+func GetTagMergeMode() TagMergeMode {
+	tagMergeModeLock.RLock()
+	defer tagMergeModeLock.RUnlock()
+	return tagMergeMode
+}
+
+// mergeTagFields combines a file's existing fields with the managed fields
+// being written. Names compare case-insensitively and a name is never taken
+// from both sides, so keys are not duplicated; repeated names within one side
+// (multi-value fields) are kept together. A managed field with an empty value
+// claims its name without writing anything, which removes it in overwrite
+// mode.
+func mergeTagFields(existing, managed []tagField, mode TagMergeMode) []tagField {
+	names := func(fields []tagField) map[string]bool {
+		set := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			set[strings.ToUpper(field.name)] = true
+		}
+		return set
+	}
+
+	var merged []tagField
+	switch mode {
+	case TagMergeFillMissing:
+		have := names(existing)
+		merged = append(merged, existing...)
+		for _, field := range managed {
+			if field.value != "" && !have[strings.ToUpper(field.name)] {
+				merged = append(merged, field)
+			}
+		}
+	case TagMergeReplace:
+		for _, field := range managed {
+			if field.value != "" {
+				merged = append(merged, field)
+			}
+		}
+	default:
+		// Managed fields take the place of the first existing field with
+		// their name, so the file keeps its order
+		written := names(managed)
+		placed := make(map[string]bool)
+		place := func(name string) {
+			placed[name] = true
+			for _, field := range managed {
+				if field.value != "" && strings.ToUpper(field.name) == name {
+					merged = append(merged, field)
+				}
+			}
+		}
+		for _, field := range existing {
+			name := strings.ToUpper(field.name)
+			if !written[name] {
+				merged = append(merged, field)
+			} else if !placed[name] {
+				place(name)
+			}
+		}
+		for _, field := range managed {
+			if name := strings.ToUpper(field.name); !placed[name] {
+				place(name)
+			}
+		}
+	}
+	return merged
+}
+
+// parseVorbisFields splits NAME=value comments into fields, skipping
+// malformed entries
+func parseVorbisFields(comments []string) []tagField {
+	fields := make([]tagField, 0, len(comments))
+	for _, comment := range comments {
+		if name, value, ok := strings.Cut(comment, "="); ok && name != "" {
+			fields = append(fields, tagField{name, value})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTagMergeMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    TagMergeMode
+		wantErr bool
+	}{
+		{"", TagMergeOverwrite, false},
+		{"overwrite", TagMergeOverwrite, false},
+		{"fill-missing", TagMergeFillMissing, false},
+		{"replace-all", TagMergeReplace, false},
+		{"Overwrite", "", true},
+		{"merge", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTagMergeMode(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTagMergeMode(%q) = %q, %v; want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMergeTagFields(t *testing.T) {
+	existing := []tagField{
+		{"TITLE", "Old Title"},
+		{"ARTIST", "Old A"},
+		{"ARTIST", "Old B"},
+		{"MOOD", "Calm"},
+		{"comment", "Ripped"},
+	}
+
+	tests := []struct {
+		name     string
+		existing []tagField
+		managed  []tagField
+		mode     TagMergeMode
+		want     []tagField
+	}{
+		{
+			name:     "overwrite keeps order and unmanaged fields",
+			existing: existing,
+			managed:  []tagField{{"ARTIST", "New A"}, {"TITLE", "New Title"}, {"ALBUM", "Album"}},
+			mode:     TagMergeOverwrite,
+			want: []tagField{
+				{"TITLE", "New Title"},
+				{"ARTIST", "New A"},
+				{"MOOD", "Calm"},
+				{"comment", "Ripped"},
+				{"ALBUM", "Album"},
+			},
+		},
+		{
+			name:     "overwrite matches names case-insensitively",
+			existing: existing,
+			managed:  []tagField{{"COMMENT", "New"}},
+			mode:     TagMergeOverwrite,
+			want: []tagField{
+				{"TITLE", "Old Title"},
+				{"ARTIST", "Old A"},
+				{"ARTIST", "Old B"},
+				{"MOOD", "Calm"},
+				{"COMMENT", "New"},
+			},
+		},
+		{
+			name:     "overwrite with an empty value removes the field",
+			existing: existing,
+			managed:  []tagField{{"MOOD", ""}, {"ARTIST", ""}, {"ARTIST", "X"}, {"ARTIST", "Y"}},
+			mode:     TagMergeOverwrite,
+			want: []tagField{
+				{"TITLE", "Old Title"},
+				{"ARTIST", "X"},
+				{"ARTIST", "Y"},
+				{"comment", "Ripped"},
+			},
+		},
+		{
+			name:     "fill-missing only adds new names",
+			existing: existing,
+			managed:  []tagField{{"title", "New Title"}, {"ALBUM", "Album"}, {"GENRE", ""}},
+			mode:     TagMergeFillMissing,
+			want:     append(slices.Clone(existing), tagField{"ALBUM", "Album"}),
+		},
+		{
+			name:     "replace-all drops existing fields",
+			existing: existing,
+			managed:  []tagField{{"TITLE", "New Title"}, {"GENRE", ""}, {"ALBUM", "Album"}},
+			mode:     TagMergeReplace,
+			want:     []tagField{{"TITLE", "New Title"}, {"ALBUM", "Album"}},
+		},
+		{
+			name:    "no existing fields",
+			managed: []tagField{{"TITLE", "Title"}, {"ARTIST", "A"}, {"ARTIST", "B"}},
+			mode:    TagMergeOverwrite,
+			want:    []tagField{{"TITLE", "Title"}, {"ARTIST", "A"}, {"ARTIST", "B"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeTagFields(tt.existing, tt.managed, tt.mode); !slices.Equal(got, tt.want) {
+				t.Errorf("mergeTagFields() =\n  %v\nwant\n  %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVorbisFields(t *testing.T) {
+	got := parseVorbisFields([]string{"TITLE=Song", "EMPTY=", "=nameless", "junk", "NOTE=a=b"})
+	want := []tagField{{"TITLE", "Song"}, {"EMPTY", ""}, {"NOTE", "a=b"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseVorbisFields() = %v, want %v", got, want)
+	}
+}
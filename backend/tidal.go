@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
@@ -12,6 +13,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -23,6 +25,9 @@ type TidalDownloader struct {
 	clientID     string
 	clientSecret string
 	apiURL       string
+
+	ExistingFiles ExistingFileAction
+	Release       ReleaseTags
 }
 
 type TidalTrack struct {
@@ -88,9 +93,7 @@ func NewTidalDownloader(apiURL string) *TidalDownloader {
 
 	if apiURL == "" {
 		downloader := &TidalDownloader{
-			client: &http.Client{
-				Timeout: 5 * time.Second,
-			},
+			client:       newHTTPClient("tidal", 5*time.Second),
 			timeout:      5 * time.Second,
 			maxRetries:   3,
 			clientID:     string(clientID),
@@ -105,9 +108,7 @@ func NewTidalDownloader(apiURL string) *TidalDownloader {
 	}
 
 	return &TidalDownloader{
-		client: &http.Client{
-			Timeout: 5 * time.Second,
-		},
+		client:       newHTTPClient("tidal", 5*time.Second),
 		timeout:      5 * time.Second,
 		maxRetries:   3,
 		clientID:     string(clientID),
@@ -141,11 +142,11 @@ func (t *TidalDownloader) GetAvailableAPIs() ([]string, error) {
 	return apis, nil
 }
 
-func (t *TidalDownloader) GetAccessToken() (string, error) {
+func (t *TidalDownloader) GetAccessToken(ctx context.Context) (string, error) {
 	data := fmt.Sprintf("client_id=%s&grant_type=client_credentials", t.clientID)
 
 	authURL, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9hdXRoLnRpZGFsLmNvbS92MS9vYXV0aDIvdG9rZW4=")
-	req, err := http.NewRequest("POST", string(authURL), strings.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, "POST", string(authURL), strings.NewReader(data))
 	if err != nil {
 		return "", err
 	}
@@ -160,7 +161,7 @@ func (t *TidalDownloader) GetAccessToken() (string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("failed to get access token: HTTP %d", resp.StatusCode)
+		return "", fmt.Errorf("failed to get access token: %w", statusError("tidal", resp))
 	}
 
 	var result struct {
@@ -174,7 +175,7 @@ func (t *TidalDownloader) GetAccessToken() (string, error) {
 	return result.AccessToken, nil
 }
 
-func (t *TidalDownloader) GetTidalURLFromSpotify(spotifyTrackID string) (string, error) {
+func (t *TidalDownloader) GetTidalURLFromSpotify(ctx context.Context, spotifyTrackID string) (string, error) {
 
 	spotifyBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9vcGVuLnNwb3RpZnkuY29tL3RyYWNrLw==")
 	spotifyURL := fmt.Sprintf("%s%s", string(spotifyBase), spotifyTrackID)
@@ -182,23 +183,19 @@ func (t *TidalDownloader) GetTidalURLFromSpotify(spotifyTrackID string) (string,
 	apiBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9hcGkuc29uZy5saW5rL3YxLWFscGhhLjEvbGlua3M/dXJsPQ==")
 	apiURL := fmt.Sprintf("%s%s", string(apiBase), url.QueryEscape(spotifyURL))
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	fmt.Println("Getting Tidal URL...")
+	tidalLog.Info("getting Tidal URL from song.link", "spotify_id", spotifyTrackID)
 
-	resp, err := t.client.Do(req)
+	resp, err := doRequest(ctx, t.client, req, "song.link", SongLinkRetryPolicy)
 	if err != nil {
 		return "", fmt.Errorf("failed to get Tidal URL: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
 	var songLinkResp struct {
 		LinksByPlatform map[string]struct {
 			URL string `json:"url"`
@@ -210,11 +207,11 @@ func (t *TidalDownloader) GetTidalURLFromSpotify(spotifyTrackID string) (string,
 
 	tidalLink, ok := songLinkResp.LinksByPlatform["tidal"]
 	if !ok || tidalLink.URL == "" {
-		return "", fmt.Errorf("tidal link not found")
+		return "", newServiceError("song.link", ErrNotFound, "tidal link not found", nil)
 	}
 
 	tidalURL := tidalLink.URL
-	fmt.Printf("Found Tidal URL: %s\n", tidalURL)
+	tidalLog.Info("found Tidal URL", "url", tidalURL)
 	return tidalURL, nil
 }
 
@@ -237,8 +234,8 @@ func (t *TidalDownloader) GetTrackIDFromURL(tidalURL string) (int64, error) {
 	return trackID, nil
 }
 
-func (t *TidalDownloader) GetTrackInfoByID(trackID int64) (*TidalTrack, error) {
-	token, err := t.GetAccessToken()
+func (t *TidalDownloader) GetTrackInfoByID(ctx context.Context, trackID int64) (*TidalTrack, error) {
+	token, err := t.GetAccessToken(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get access token: %w", err)
 	}
@@ -246,7 +243,7 @@ func (t *TidalDownloader) GetTrackInfoByID(trackID int64) (*TidalTrack, error) {
 	trackBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9hcGkudGlkYWwuY29tL3YxL3RyYWNrcy8=")
 	trackURL := fmt.Sprintf("%s%d?countryCode=US", string(trackBase), trackID)
 
-	req, err := http.NewRequest("GET", trackURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", trackURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -261,7 +258,7 @@ func (t *TidalDownloader) GetTrackInfoByID(trackID int64) (*TidalTrack, error) {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get track info: HTTP %d - %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to get track info: %w - %s", statusError("tidal", resp), string(body))
 	}
 
 	var trackInfo TidalTrack
@@ -269,37 +266,37 @@ func (t *TidalDownloader) GetTrackInfoByID(trackID int64) (*TidalTrack, error) {
 		return nil, err
 	}
 
-	fmt.Printf("Found: %s (%s)\n", trackInfo.Title, trackInfo.AudioQuality)
+	tidalLog.Info("found track", "title", trackInfo.Title, "quality", trackInfo.AudioQuality)
 	return &trackInfo, nil
 }
 
-func (t *TidalDownloader) GetDownloadURL(trackID int64, quality string) (string, error) {
-	fmt.Println("Fetching URL...")
-
+func (t *TidalDownloader) GetDownloadURL(ctx context.Context, trackID int64, quality string) (string, error) {
 	url := fmt.Sprintf("%s/track/?id=%d&quality=%s", t.apiURL, trackID, quality)
-	fmt.Printf("Tidal API URL: %s\n", url)
+	tidalLog.Debug("fetching download URL", "url", url)
 
-	resp, err := t.client.Get(url)
+	start := time.Now()
+	resp, err := httpGet(ctx, t.client, url)
+	observeMirror("tidal", t.apiURL, start, responseError("tidal", resp, err))
 	if err != nil {
-		fmt.Printf("✗ Tidal API request failed: %v\n", err)
+		tidalLog.Warn("API request failed", "error", err)
 		return "", fmt.Errorf("failed to get download URL: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		fmt.Printf("✗ Tidal API returned status code: %d\n", resp.StatusCode)
-		return "", fmt.Errorf("API returned status code: %d", resp.StatusCode)
+		tidalLog.Warn("API returned error status", "status", resp.StatusCode)
+		return "", statusError("tidal", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Printf("✗ Failed to read response body: %v\n", err)
+		tidalLog.Warn("failed to read response body", "error", err)
 		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var v2Response TidalAPIResponseV2
 	if err := json.Unmarshal(body, &v2Response); err == nil && v2Response.Data.Manifest != "" {
-		fmt.Println("✓ Tidal manifest found (v2 API)")
+		tidalLog.Debug("manifest found", "api", "v2")
 		return "MANIFEST:" + v2Response.Data.Manifest, nil
 	}
 
@@ -310,52 +307,52 @@ func (t *TidalDownloader) GetDownloadURL(trackID int64, quality string) (string,
 		if len(bodyStr) > 200 {
 			bodyStr = bodyStr[:200] + "..."
 		}
-		fmt.Printf("✗ Failed to decode Tidal API response: %v (response: %s)\n", err, bodyStr)
+		tidalLog.Warn("failed to decode API response", "error", err, "response", bodyStr)
 		return "", fmt.Errorf("failed to decode response: %w (response: %s)", err, bodyStr)
 	}
 
 	if len(apiResponses) == 0 {
-		fmt.Println("✗ Tidal API returned empty response")
+		tidalLog.Warn("API returned empty response")
 		return "", fmt.Errorf("no download URL in response")
 	}
 
 	for _, item := range apiResponses {
 		if item.OriginalTrackURL != "" {
-			fmt.Println("✓ Tidal download URL found")
+			tidalLog.Debug("download URL found", "api", "v1")
 			return item.OriginalTrackURL, nil
 		}
 	}
 
-	fmt.Println("✗ No valid download URL in Tidal API response")
+	tidalLog.Warn("no valid download URL in API response")
 	return "", fmt.Errorf("download URL not found in response")
 }
 
-func (t *TidalDownloader) DownloadAlbumArt(albumID string) ([]byte, error) {
+func (t *TidalDownloader) DownloadAlbumArt(ctx context.Context, albumID string) ([]byte, error) {
 	albumID = strings.ReplaceAll(albumID, "-", "/")
 
 	imageBase, _ := base64.StdEncoding.DecodeString("aHR0cHM6Ly9yZXNvdXJjZXMudGlkYWwuY29tL2ltYWdlcy8=")
 	artURL := fmt.Sprintf("%s%s/1280x1280.jpg", string(imageBase), albumID)
 
-	resp, err := t.client.Get(artURL)
+	resp, err := httpGet(ctx, t.client, artURL)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to download album art: HTTP %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to download album art: %w", statusError("tidal", resp))
 	}
 
 	return io.ReadAll(resp.Body)
 }
 
-func (t *TidalDownloader) DownloadFile(url, filepath string) error {
+func (t *TidalDownloader) DownloadFile(ctx context.Context, url, filepath string) error {
 
 	if strings.HasPrefix(url, "MANIFEST:") {
-		return t.DownloadFromManifest(strings.TrimPrefix(url, "MANIFEST:"), filepath)
+		return t.DownloadFromManifest(ctx, strings.TrimPrefix(url, "MANIFEST:"), filepath)
 	}
 
-	resp, err := t.client.Get(url)
+	resp, err := httpGet(ctx, t.client, url)
 
 	if err != nil {
 		return fmt.Errorf("failed to download file: %w", err)
@@ -363,7 +360,7 @@ func (t *TidalDownloader) DownloadFile(url, filepath string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed with status %d", resp.StatusCode)
+		return fmt.Errorf("download failed: %w", statusError("tidal", resp))
 	}
 
 	out, err := os.Create(filepath)
@@ -372,39 +369,38 @@ func (t *TidalDownloader) DownloadFile(url, filepath string) error {
 	}
 	defer out.Close()
 
-	pw := NewProgressWriter(out)
+	pw := NewProgressWriter(ctx, out)
 	_, err = io.Copy(pw, resp.Body)
 	if err != nil {
+		out.Close()
+		os.Remove(filepath)
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
-	fmt.Printf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
+	progressf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
 
-	fmt.Println("Download complete")
 	return nil
 }
 
-func (t *TidalDownloader) DownloadFromManifest(manifestB64, outputPath string) error {
+func (t *TidalDownloader) DownloadFromManifest(ctx context.Context, manifestB64, outputPath string) error {
 	directURL, initURL, mediaURLs, err := parseManifest(manifestB64)
 	if err != nil {
 		return fmt.Errorf("failed to parse manifest: %w", err)
 	}
 
-	client := &http.Client{
-		Timeout: 120 * time.Second,
-	}
+	client := newHTTPClient("tidal-download", 120*time.Second)
 
 	if directURL != "" {
-		fmt.Println("Downloading file...")
+		tidalLog.Info("downloading file", "path", outputPath)
 
-		resp, err := client.Get(directURL)
+		resp, err := httpGet(ctx, client, directURL)
 		if err != nil {
 			return fmt.Errorf("failed to download file: %w", err)
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != 200 {
-			return fmt.Errorf("download failed with status %d", resp.StatusCode)
+			return fmt.Errorf("download failed: %w", statusError("tidal", resp))
 		}
 
 		out, err := os.Create(outputPath)
@@ -413,18 +409,19 @@ func (t *TidalDownloader) DownloadFromManifest(manifestB64, outputPath string) e
 		}
 		defer out.Close()
 
-		pw := NewProgressWriter(out)
+		pw := NewProgressWriter(ctx, out)
 		_, err = io.Copy(pw, resp.Body)
 		if err != nil {
+			out.Close()
+			os.Remove(outputPath)
 			return fmt.Errorf("failed to write file: %w", err)
 		}
 
-		fmt.Printf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
-		fmt.Println("Download complete")
+		progressf("\rDownloaded: %.2f MB (Complete)\n", float64(pw.GetTotal())/(1024*1024))
 		return nil
 	}
 
-	fmt.Printf("Downloading %d segments...\n", len(mediaURLs)+1)
+	tidalLog.Info("downloading segments", "count", len(mediaURLs)+1)
 
 	tempPath := outputPath + ".m4a.tmp"
 	out, err := os.Create(tempPath)
@@ -432,8 +429,7 @@ func (t *TidalDownloader) DownloadFromManifest(manifestB64, outputPath string) e
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
 
-	fmt.Print("Downloading init segment... ")
-	resp, err := client.Get(initURL)
+	resp, err := httpGet(ctx, client, initURL)
 	if err != nil {
 		out.Close()
 		os.Remove(tempPath)
@@ -445,21 +441,21 @@ func (t *TidalDownloader) DownloadFromManifest(manifestB64, outputPath string) e
 		os.Remove(tempPath)
 		return fmt.Errorf("init segment download failed with status %d", resp.StatusCode)
 	}
-	_, err = io.Copy(out, resp.Body)
+	_, err = io.Copy(NewThrottledWriter(ctx, out), resp.Body)
 	resp.Body.Close()
 	if err != nil {
 		out.Close()
 		os.Remove(tempPath)
 		return fmt.Errorf("failed to write init segment: %w", err)
 	}
-	fmt.Println("OK")
+	tidalLog.Debug("init segment downloaded")
 
 	totalSegments := len(mediaURLs)
 	var totalBytes int64
 	lastTime := time.Now()
 	var lastBytes int64
 	for i, mediaURL := range mediaURLs {
-		resp, err := client.Get(mediaURL)
+		resp, err := httpGet(ctx, client, mediaURL)
 		if err != nil {
 			out.Close()
 			os.Remove(tempPath)
@@ -471,7 +467,7 @@ func (t *TidalDownloader) DownloadFromManifest(manifestB64, outputPath string) e
 			os.Remove(tempPath)
 			return fmt.Errorf("segment %d download failed with status %d", i+1, resp.StatusCode)
 		}
-		n, err := io.Copy(out, resp.Body)
+		n, err := io.Copy(NewThrottledWriter(ctx, out), resp.Body)
 		totalBytes += n
 		resp.Body.Close()
 		if err != nil {
@@ -492,16 +488,19 @@ func (t *TidalDownloader) DownloadFromManifest(manifestB64, outputPath string) e
 			lastBytes = totalBytes
 		}
 		SetDownloadProgress(mbDownloaded)
+		if itemID := GetCurrentItemID(); itemID != "" {
+			UpdateItemProgress(itemID, mbDownloaded, speedMBps)
+		}
 
-		fmt.Printf("\rDownloading: %.2f MB (%d/%d segments)", mbDownloaded, i+1, totalSegments)
+		progressf("\rDownloading: %.2f MB (%d/%d segments)", mbDownloaded, i+1, totalSegments)
 	}
 
 	out.Close()
 
 	tempInfo, _ := os.Stat(tempPath)
-	fmt.Printf("\rDownloaded: %.2f MB (Complete)          \n", float64(tempInfo.Size())/(1024*1024))
+	progressf("\rDownloaded: %.2f MB (Complete)          \n", float64(tempInfo.Size())/(1024*1024))
 
-	fmt.Println("Converting to FLAC...")
+	tidalLog.Info("converting to FLAC", "path", outputPath)
 	ffmpegPath, err := GetFFmpegPath()
 	if err != nil {
 		return fmt.Errorf("ffmpeg not found: %w", err)
@@ -511,38 +510,45 @@ func (t *TidalDownloader) DownloadFromManifest(manifestB64, outputPath string) e
 		return fmt.Errorf("invalid ffmpeg executable: %w", err)
 	}
 
-	cmd := exec.Command(ffmpegPath, "-y", "-i", tempPath, "-vn", "-c:a", "flac", outputPath)
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-y", "-i", tempPath, "-vn", "-c:a", "flac", outputPath)
 	setHideWindow(cmd)
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	start := time.Now()
+	err = cmd.Run()
+	observeFFmpeg("dash-to-flac", start, err)
+	if err != nil {
+		if ctx.Err() != nil {
+			os.Remove(tempPath)
+			os.Remove(outputPath)
+			return ctx.Err()
+		}
 
 		m4aPath := strings.TrimSuffix(outputPath, ".flac") + ".m4a"
 		os.Rename(tempPath, m4aPath)
-		return fmt.Errorf("ffmpeg conversion failed (M4A saved as %s): %w - %s", m4aPath, err, stderr.String())
+		return newServiceError("tidal", ErrConversionFailed, fmt.Sprintf("ffmpeg conversion failed (M4A saved as %s): %s", m4aPath, stderr.String()), err)
 	}
 
 	os.Remove(tempPath)
-	fmt.Println("Download complete")
 
 	return nil
 }
 
-func (t *TidalDownloader) DownloadByURL(tidalURL, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate string, useAlbumTrackNumber bool, spotifyCoverURL string, embedMaxQualityCover bool, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
+func (t *TidalDownloader) DownloadByURL(ctx context.Context, tidalURL, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate string, useAlbumTrackNumber bool, spotifyCoverURL string, embedMaxQualityCover bool, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
 	if outputDir != "." {
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
 			return "", fmt.Errorf("directory error: %w", err)
 		}
 	}
 
-	fmt.Printf("Using Tidal URL: %s\n", tidalURL)
+	tidalLog.Info("using Tidal URL", "url", tidalURL)
 
 	trackID, err := t.GetTrackIDFromURL(tidalURL)
 	if err != nil {
 		return "", err
 	}
 
-	trackInfo, err := t.GetTrackInfoByID(trackID)
+	trackInfo, err := t.GetTrackInfoByID(ctx, trackID)
 	if err != nil {
 		return "", err
 	}
@@ -551,6 +557,10 @@ func (t *TidalDownloader) DownloadByURL(tidalURL, outputDir, quality, filenameFo
 		return "", fmt.Errorf("no track ID found")
 	}
 
+	if err := checkTidalQuality(trackInfo); err != nil {
+		return "", err
+	}
+
 	artistName := spotifyArtistName
 	trackTitle := spotifyTrackName
 	albumTitle := spotifyAlbumName
@@ -561,36 +571,35 @@ func (t *TidalDownloader) DownloadByURL(tidalURL, outputDir, quality, filenameFo
 	albumArtistForFile := sanitizeFilename(spotifyAlbumArtist)
 
 	filename := buildTidalFilename(trackTitleForFile, artistNameForFile, albumTitleForFile, albumArtistForFile, spotifyReleaseDate, trackInfo.TrackNumber, spotifyDiscNumber, filenameFormat, includeTrackNumber, position, useAlbumTrackNumber)
-	outputFilename := filepath.Join(outputDir, filename)
+	outputFilename, exists := resolveOutputPath(filepath.Join(outputDir, filename), t.ExistingFiles)
 
-	if fileInfo, err := os.Stat(outputFilename); err == nil && fileInfo.Size() > 0 {
-		fmt.Printf("File already exists: %s (%.2f MB)\n", outputFilename, float64(fileInfo.Size())/(1024*1024))
+	if exists {
+		fileInfo, _ := os.Stat(outputFilename)
+		tidalLog.Info("file already exists", "path", outputFilename, "size_mb", float64(fileInfo.Size())/(1024*1024))
 		return "EXISTS:" + outputFilename, nil
 	}
 
-	downloadURL, err := t.GetDownloadURL(trackInfo.ID, quality)
+	downloadURL, err := t.GetDownloadURL(ctx, trackInfo.ID, quality)
 	if err != nil {
 		return "", err
 	}
 
-	fmt.Printf("Downloading to: %s\n", outputFilename)
-	if err := t.DownloadFile(downloadURL, outputFilename); err != nil {
+	tidalLog.Info("downloading", "path", outputFilename)
+	if err := t.DownloadFile(ctx, downloadURL, outputFilename); err != nil {
 		return "", err
 	}
 
-	fmt.Println("Adding metadata...")
-
 	coverPath := ""
 
 	if spotifyCoverURL != "" {
 		coverPath = outputFilename + ".cover.jpg"
 		coverClient := NewCoverClient()
-		if err := coverClient.DownloadCoverToPath(spotifyCoverURL, coverPath, embedMaxQualityCover); err != nil {
-			fmt.Printf("Warning: Failed to download Spotify cover: %v\n", err)
+		if err := coverClient.DownloadCoverToPath(ctx, spotifyCoverURL, coverPath, embedMaxQualityCover); err != nil {
+			tidalLog.Warn("failed to download Spotify cover", "error", err)
 			coverPath = ""
 		} else {
 			defer os.Remove(coverPath)
-			fmt.Println("Spotify cover downloaded")
+			tidalLog.Debug("Spotify cover downloaded", "path", coverPath)
 		}
 	}
 
@@ -613,20 +622,26 @@ func (t *TidalDownloader) DownloadByURL(tidalURL, outputDir, quality, filenameFo
 		Copyright:   spotifyCopyright,
 		Publisher:   spotifyPublisher,
 		Description: "https://github.com/afkarxyz/SpotiFLAC",
+		ISRC:        trackInfo.ISRC,
+		SpotifyID:   spotifyTrackIDFromURL(spotifyURL),
+
+		Explicit:      trackInfo.Explicit,
+		SourceService: "tidal",
+		SourceTrackID: strconv.FormatInt(trackInfo.ID, 10),
 	}
+	t.Release.apply(&metadata)
 
 	if err := EmbedMetadata(outputFilename, metadata, coverPath); err != nil {
-		fmt.Printf("Tagging failed: %v\n", err)
+		tidalLog.Warn("tagging failed", "path", outputFilename, "error", err)
 	} else {
-		fmt.Println("Metadata saved")
+		tidalLog.Debug("metadata saved", "path", outputFilename)
 	}
 
-	fmt.Println("Done")
-	fmt.Println("✓ Downloaded successfully from Tidal")
+	tidalLog.Info("downloaded", "path", outputFilename)
 	return outputFilename, nil
 }
 
-func (t *TidalDownloader) DownloadByURLWithFallback(tidalURL, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate string, useAlbumTrackNumber bool, spotifyCoverURL string, embedMaxQualityCover bool, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
+func (t *TidalDownloader) DownloadByURLWithFallback(ctx context.Context, tidalURL, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate string, useAlbumTrackNumber bool, spotifyCoverURL string, embedMaxQualityCover bool, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
 	apis, err := t.GetAvailableAPIs()
 	if err != nil {
 		return "", fmt.Errorf("no APIs available for fallback: %w", err)
@@ -638,14 +653,14 @@ func (t *TidalDownloader) DownloadByURLWithFallback(tidalURL, outputDir, quality
 		}
 	}
 
-	fmt.Printf("Using Tidal URL: %s\n", tidalURL)
+	tidalLog.Info("using Tidal URL", "url", tidalURL)
 
 	trackID, err := t.GetTrackIDFromURL(tidalURL)
 	if err != nil {
 		return "", err
 	}
 
-	trackInfo, err := t.GetTrackInfoByID(trackID)
+	trackInfo, err := t.GetTrackInfoByID(ctx, trackID)
 	if err != nil {
 		return "", err
 	}
@@ -654,6 +669,10 @@ func (t *TidalDownloader) DownloadByURLWithFallback(tidalURL, outputDir, quality
 		return "", fmt.Errorf("no track ID found")
 	}
 
+	if err := checkTidalQuality(trackInfo); err != nil {
+		return "", err
+	}
+
 	artistName := spotifyArtistName
 	trackTitle := spotifyTrackName
 	albumTitle := spotifyAlbumName
@@ -664,37 +683,36 @@ func (t *TidalDownloader) DownloadByURLWithFallback(tidalURL, outputDir, quality
 	albumArtistForFile := sanitizeFilename(spotifyAlbumArtist)
 
 	filename := buildTidalFilename(trackTitleForFile, artistNameForFile, albumTitleForFile, albumArtistForFile, spotifyReleaseDate, trackInfo.TrackNumber, spotifyDiscNumber, filenameFormat, includeTrackNumber, position, useAlbumTrackNumber)
-	outputFilename := filepath.Join(outputDir, filename)
+	outputFilename, exists := resolveOutputPath(filepath.Join(outputDir, filename), t.ExistingFiles)
 
-	if fileInfo, err := os.Stat(outputFilename); err == nil && fileInfo.Size() > 0 {
-		fmt.Printf("File already exists: %s (%.2f MB)\n", outputFilename, float64(fileInfo.Size())/(1024*1024))
+	if exists {
+		fileInfo, _ := os.Stat(outputFilename)
+		tidalLog.Info("file already exists", "path", outputFilename, "size_mb", float64(fileInfo.Size())/(1024*1024))
 		return "EXISTS:" + outputFilename, nil
 	}
 
-	successAPI, downloadURL, err := getDownloadURLParallel(apis, trackInfo.ID, quality)
+	successAPI, downloadURL, err := getDownloadURLParallel(ctx, apis, trackInfo.ID, quality)
 	if err != nil {
 		return "", err
 	}
 
-	fmt.Printf("Downloading to: %s\n", outputFilename)
+	tidalLog.Info("downloading", "path", outputFilename, "api", successAPI)
 	downloader := NewTidalDownloader(successAPI)
-	if err := downloader.DownloadFile(downloadURL, outputFilename); err != nil {
+	if err := downloader.DownloadFile(ctx, downloadURL, outputFilename); err != nil {
 		return "", err
 	}
 
-	fmt.Println("Adding metadata...")
-
 	coverPath := ""
 
 	if spotifyCoverURL != "" {
 		coverPath = outputFilename + ".cover.jpg"
 		coverClient := NewCoverClient()
-		if err := coverClient.DownloadCoverToPath(spotifyCoverURL, coverPath, embedMaxQualityCover); err != nil {
-			fmt.Printf("Warning: Failed to download Spotify cover: %v\n", err)
+		if err := coverClient.DownloadCoverToPath(ctx, spotifyCoverURL, coverPath, embedMaxQualityCover); err != nil {
+			tidalLog.Warn("failed to download Spotify cover", "error", err)
 			coverPath = ""
 		} else {
 			defer os.Remove(coverPath)
-			fmt.Println("Spotify cover downloaded")
+			tidalLog.Debug("Spotify cover downloaded", "path", coverPath)
 		}
 	}
 
@@ -717,27 +735,33 @@ func (t *TidalDownloader) DownloadByURLWithFallback(tidalURL, outputDir, quality
 		Copyright:   spotifyCopyright,
 		Publisher:   spotifyPublisher,
 		Description: "https://github.com/afkarxyz/SpotiFLAC",
+		ISRC:        trackInfo.ISRC,
+		SpotifyID:   spotifyTrackIDFromURL(spotifyURL),
+
+		Explicit:      trackInfo.Explicit,
+		SourceService: "tidal",
+		SourceTrackID: strconv.FormatInt(trackInfo.ID, 10),
 	}
+	t.Release.apply(&metadata)
 
 	if err := EmbedMetadata(outputFilename, metadata, coverPath); err != nil {
-		fmt.Printf("Tagging failed: %v\n", err)
+		tidalLog.Warn("tagging failed", "path", outputFilename, "error", err)
 	} else {
-		fmt.Println("Metadata saved")
+		tidalLog.Debug("metadata saved", "path", outputFilename)
 	}
 
-	fmt.Println("Done")
-	fmt.Println("✓ Downloaded successfully from Tidal")
+	tidalLog.Info("downloaded", "path", outputFilename)
 	return outputFilename, nil
 }
 
-func (t *TidalDownloader) Download(spotifyTrackID, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate string, useAlbumTrackNumber bool, spotifyCoverURL string, embedMaxQualityCover bool, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
+func (t *TidalDownloader) Download(ctx context.Context, spotifyTrackID, outputDir, quality, filenameFormat string, includeTrackNumber bool, position int, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate string, useAlbumTrackNumber bool, spotifyCoverURL string, embedMaxQualityCover bool, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks int, spotifyTotalDiscs int, spotifyCopyright, spotifyPublisher, spotifyURL string) (string, error) {
 
-	tidalURL, err := t.GetTidalURLFromSpotify(spotifyTrackID)
+	tidalURL, err := t.GetTidalURLFromSpotify(ctx, spotifyTrackID)
 	if err != nil {
 		return "", fmt.Errorf("songlink couldn't find Tidal URL: %w", err)
 	}
 
-	return t.DownloadByURLWithFallback(tidalURL, outputDir, quality, filenameFormat, includeTrackNumber, position, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, useAlbumTrackNumber, spotifyCoverURL, embedMaxQualityCover, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks, spotifyTotalDiscs, spotifyCopyright, spotifyPublisher, spotifyURL)
+	return t.DownloadByURLWithFallback(ctx, tidalURL, outputDir, quality, filenameFormat, includeTrackNumber, position, spotifyTrackName, spotifyArtistName, spotifyAlbumName, spotifyAlbumArtist, spotifyReleaseDate, useAlbumTrackNumber, spotifyCoverURL, embedMaxQualityCover, spotifyTrackNumber, spotifyDiscNumber, spotifyTotalTracks, spotifyTotalDiscs, spotifyCopyright, spotifyPublisher, spotifyURL)
 }
 
 type SegmentTemplate struct {
@@ -786,12 +810,10 @@ func parseManifest(manifestB64 string) (directURL string, initURL string, mediaU
 			return "", "", nil, fmt.Errorf("no URLs in BTS manifest")
 		}
 
-		fmt.Printf("Manifest: BTS format (%s, %s)\n", btsManifest.MimeType, btsManifest.Codecs)
+		tidalLog.Debug("parsed manifest", "format", "BTS", "mime_type", btsManifest.MimeType, "codecs", btsManifest.Codecs)
 		return btsManifest.URLs[0], "", nil, nil
 	}
 
-	fmt.Println("Manifest: DASH format")
-
 	var mpd MPD
 	var segTemplate *SegmentTemplate
 
@@ -826,7 +848,7 @@ func parseManifest(manifestB64 string) (directURL string, initURL string, mediaU
 		}
 
 		if selectedBandwidth > 0 {
-			fmt.Printf("Selected stream: Codec=%s, Bandwidth=%d bps\n", selectedCodecs, selectedBandwidth)
+			tidalLog.Debug("selected stream", "codecs", selectedCodecs, "bandwidth", selectedBandwidth)
 		}
 	}
 
@@ -846,7 +868,7 @@ func parseManifest(manifestB64 string) (directURL string, initURL string, mediaU
 		initURL = strings.ReplaceAll(initURL, "&amp;", "&")
 		mediaTemplate = strings.ReplaceAll(mediaTemplate, "&amp;", "&")
 
-		fmt.Printf("Parsed manifest via XML: %d segments\n", segmentCount)
+		tidalLog.Debug("parsed manifest", "format", "DASH", "parser", "xml", "segments", segmentCount)
 
 		for i := 1; i <= segmentCount; i++ {
 			mediaURL := strings.ReplaceAll(mediaTemplate, "$Number$", fmt.Sprintf("%d", i))
@@ -855,7 +877,7 @@ func parseManifest(manifestB64 string) (directURL string, initURL string, mediaU
 		return "", initURL, mediaURLs, nil
 	}
 
-	fmt.Println("Using regex fallback for DASH manifest...")
+	tidalLog.Debug("using regex fallback for DASH manifest")
 
 	initRe := regexp.MustCompile(`initialization="([^"]+)"`)
 	mediaRe := regexp.MustCompile(`media="([^"]+)"`)
@@ -892,7 +914,7 @@ func parseManifest(manifestB64 string) (directURL string, initURL string, mediaU
 		return "", "", nil, fmt.Errorf("no segments found in manifest (XML: %d, Regex: 0)", len(matches))
 	}
 
-	fmt.Printf("Parsed manifest via Regex: %d segments\n", segmentCount)
+	tidalLog.Debug("parsed manifest", "format", "DASH", "parser", "regex", "segments", segmentCount)
 
 	for i := 1; i <= segmentCount; i++ {
 		mediaURL := strings.ReplaceAll(mediaTemplate, "$Number$", fmt.Sprintf("%d", i))
@@ -908,43 +930,54 @@ type manifestResult struct {
 	err      error
 }
 
-func getDownloadURLParallel(apis []string, trackID int64, quality string) (string, string, error) {
+// Tracks only available in lossy quality can't be downloaded as FLAC
+func checkTidalQuality(track *TidalTrack) error {
+	switch track.AudioQuality {
+	case "LOW", "HIGH":
+		return newServiceError("tidal", ErrQualityUnavailable, fmt.Sprintf("track is only available in %s quality", track.AudioQuality), nil)
+	}
+	return nil
+}
+
+func getDownloadURLParallel(ctx context.Context, apis []string, trackID int64, quality string) (string, string, error) {
 	if len(apis) == 0 {
 		return "", "", fmt.Errorf("no APIs available")
 	}
 
 	resultChan := make(chan manifestResult, len(apis))
 
-	fmt.Printf("Requesting download URL from %d APIs in parallel...\n", len(apis))
+	tidalLog.Info("requesting download URL", "apis", len(apis))
 	for _, apiURL := range apis {
 		go func(api string) {
-
-			client := &http.Client{
-				Timeout: 15 * time.Second,
+			start := time.Now()
+			send := func(result manifestResult) {
+				observeMirror("tidal", api, start, result.err)
+				resultChan <- result
 			}
+			client := newHTTPClient("tidal", 15*time.Second)
 
 			url := fmt.Sprintf("%s/track/?id=%d&quality=%s", api, trackID, quality)
-			resp, err := client.Get(url)
+			resp, err := httpGet(ctx, client, url)
 			if err != nil {
-				resultChan <- manifestResult{apiURL: api, err: err}
+				send(manifestResult{apiURL: api, err: err})
 				return
 			}
 			defer resp.Body.Close()
 
 			if resp.StatusCode != 200 {
-				resultChan <- manifestResult{apiURL: api, err: fmt.Errorf("HTTP %d", resp.StatusCode)}
+				send(manifestResult{apiURL: api, err: statusError("tidal", resp)})
 				return
 			}
 
 			body, err := io.ReadAll(resp.Body)
 			if err != nil {
-				resultChan <- manifestResult{apiURL: api, err: err}
+				send(manifestResult{apiURL: api, err: err})
 				return
 			}
 
 			var v2Response TidalAPIResponseV2
 			if err := json.Unmarshal(body, &v2Response); err == nil && v2Response.Data.Manifest != "" {
-				resultChan <- manifestResult{apiURL: api, manifest: v2Response.Data.Manifest, err: nil}
+				send(manifestResult{apiURL: api, manifest: v2Response.Data.Manifest, err: nil})
 				return
 			}
 
@@ -953,24 +986,22 @@ func getDownloadURLParallel(apis []string, trackID int64, quality string) (strin
 				for _, item := range v1Responses {
 					if item.OriginalTrackURL != "" {
 
-						resultChan <- manifestResult{apiURL: api, manifest: "DIRECT:" + item.OriginalTrackURL, err: nil}
+						send(manifestResult{apiURL: api, manifest: "DIRECT:" + item.OriginalTrackURL, err: nil})
 						return
 					}
 				}
 			}
 
-			resultChan <- manifestResult{apiURL: api, err: fmt.Errorf("no download URL or manifest in response")}
+			send(manifestResult{apiURL: api, err: fmt.Errorf("no download URL or manifest in response")})
 		}(apiURL)
 	}
 
 	var lastError error
-	var errors []string
-
 	for i := 0; i < len(apis); i++ {
 		result := <-resultChan
 		if result.err == nil && result.manifest != "" {
 
-			fmt.Printf("✓ Got response from: %s\n", result.apiURL)
+			tidalLog.Info("got download URL", "api", result.apiURL)
 
 			if strings.HasPrefix(result.manifest, "DIRECT:") {
 				return result.apiURL, strings.TrimPrefix(result.manifest, "DIRECT:"), nil
@@ -978,21 +1009,12 @@ func getDownloadURLParallel(apis []string, trackID int64, quality string) (strin
 
 			return result.apiURL, "MANIFEST:" + result.manifest, nil
 		} else {
-			errMsg := result.err.Error()
-			if len(errMsg) > 50 {
-				errMsg = errMsg[:50] + "..."
-			}
-			errors = append(errors, fmt.Sprintf("%s: %s", result.apiURL, errMsg))
+			tidalLog.Warn("API failed", "api", result.apiURL, "error", result.err)
 			lastError = result.err
 		}
 	}
 
-	fmt.Println("All APIs failed:")
-	for _, e := range errors {
-		fmt.Printf("  ✗ %s\n", e)
-	}
-
-	return "", "", fmt.Errorf("all %d APIs failed. Last error: %v", len(apis), lastError)
+	return "", "", newServiceError("tidal", ErrUpstreamDown, fmt.Sprintf("all %d APIs failed", len(apis)), lastError)
 }
 
 func buildTidalFilename(title, artist, album, albumArtist, releaseDate string, trackNumber, discNumber int, format string, includeTrackNumber bool, position int, useAlbumTrackNumber bool) string {
@@ -0,0 +1,143 @@
+package backend
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type WatchedArtist struct {
+	ArtistID        string   `json:"artist_id"`
+	Name            string   `json:"name"`
+	Groups          []string `json:"groups"`
+	LastReleaseDate string   `json:"last_release_date"`
+	LastReleaseIDs  []string `json:"last_release_ids"`
+	AddedAt         int64    `json:"added_at"`
+	LastChecked     int64    `json:"last_checked"`
+}
+
+const watchBucket = "WatchedArtists"
+
+var ValidWatchGroups = []string{"album", "single", "compilation"}
+
+func (w *WatchedArtist) wantsGroup(releaseType string) bool {
+	return len(w.Groups) == 0 || slices.Contains(w.Groups, releaseType)
+}
+
+// NewReleases returns releases in the watched groups that are newer than the
+// last seen release, oldest first.
+func (w *WatchedArtist) NewReleases(releases []ArtistRelease) []ArtistRelease {
+	var fresh []ArtistRelease
+	for _, release := range releases {
+		if !w.wantsGroup(release.Type) {
+			continue
+		}
+		switch {
+		case release.ReleaseDate > w.LastReleaseDate:
+		case release.ReleaseDate == w.LastReleaseDate && !slices.Contains(w.LastReleaseIDs, release.ID):
+		default:
+			continue
+		}
+		fresh = append(fresh, release)
+	}
+
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].ReleaseDate < fresh[j].ReleaseDate
+	})
+	return fresh
+}
+
+// MarkSeen advances the last seen release to the newest release in the
+// watched groups.
+func (w *WatchedArtist) MarkSeen(releases []ArtistRelease) {
+	for _, release := range releases {
+		if !w.wantsGroup(release.Type) {
+			continue
+		}
+		switch {
+		case release.ReleaseDate > w.LastReleaseDate:
+			w.LastReleaseDate = release.ReleaseDate
+			w.LastReleaseIDs = []string{release.ID}
+		case release.ReleaseDate == w.LastReleaseDate && !slices.Contains(w.LastReleaseIDs, release.ID):
+			w.LastReleaseIDs = append(w.LastReleaseIDs, release.ID)
+		}
+	}
+	w.LastChecked = time.Now().Unix()
+}
+
+func SaveWatchedArtist(artist WatchedArtist, appName string) error {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return err
+		}
+	}
+
+	return historyDB.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(watchBucket))
+		if err != nil {
+			return err
+		}
+
+		if artist.AddedAt == 0 {
+			artist.AddedAt = time.Now().Unix()
+		}
+
+		buf, err := json.Marshal(artist)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(artist.ArtistID), buf)
+	})
+}
+
+func GetWatchedArtists(appName string) ([]WatchedArtist, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return nil, err
+		}
+	}
+
+	var artists []WatchedArtist
+	err := historyDB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(watchBucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var artist WatchedArtist
+			if err := json.Unmarshal(v, &artist); err == nil {
+				artists = append(artists, artist)
+			}
+			return nil
+		})
+	})
+
+	sort.Slice(artists, func(i, j int) bool {
+		return artists[i].Name < artists[j].Name
+	})
+
+	return artists, err
+}
+
+func RemoveWatchedArtist(artistID string, appName string) (bool, error) {
+	if historyDB == nil {
+		if err := InitHistoryDB(appName); err != nil {
+			return false, err
+		}
+	}
+
+	found := false
+	err := historyDB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(watchBucket))
+		if b == nil || b.Get([]byte(artistID)) == nil {
+			return nil
+		}
+		found = true
+		return b.Delete([]byte(artistID))
+	})
+
+	return found, err
+}
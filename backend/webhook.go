@@ -0,0 +1,126 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Webhook events
+const (
+	WebhookBatchComplete = "batch_complete"
+	WebhookSyncComplete  = "sync_complete"
+	WebhookWatchComplete = "watch_complete"
+)
+
+// WebhookSignatureHeader carries "sha256=" plus the hex HMAC-SHA256 of the
+// request body, keyed with the webhook secret.
+const WebhookSignatureHeader = "X-SpotiFLAC-Signature"
+
+type WebhookConfig struct {
+	URL          string
+	Secret       string
+	TemplatePath string
+}
+
+// WebhookPayload is the default request body and the data passed to custom
+// templates.
+type WebhookPayload struct {
+	Event       string   `json:"event"`
+	Time        int64    `json:"time"`
+	Name        string   `json:"name,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Total       int      `json:"total"`
+	Completed   int      `json:"completed"`
+	Skipped     int      `json:"skipped"`
+	Failed      int      `json:"failed"`
+	Queued      int      `json:"queued,omitempty"`
+	Interrupted bool     `json:"interrupted"`
+	Failures    []string `json:"failures"`
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": strings.Join,
+}
+
+// Body renders the request body: the payload as JSON, or the template
+// at TemplatePath, which must produce valid JSON.
+func (c WebhookConfig) Body(payload WebhookPayload) ([]byte, error) {
+	if payload.Failures == nil {
+		payload.Failures = []string{}
+	}
+	if c.TemplatePath == "" {
+		return json.Marshal(payload)
+	}
+
+	text, err := os.ReadFile(NormalizePath(c.TemplatePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook template: %w", err)
+	}
+	tmpl, err := template.New("webhook").Funcs(webhookTemplateFuncs).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, payload); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, fmt.Errorf("webhook template did not produce valid JSON")
+	}
+	return body.Bytes(), nil
+}
+
+// SignWebhook returns the signature header value for body
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SendWebhook posts the payload to the webhook URL. Network errors, 429s and
+// 5xx responses are retried with DefaultRetryPolicy; any 2xx is success.
+func SendWebhook(ctx context.Context, config WebhookConfig, payload WebhookPayload) error {
+	body, err := config.Body(payload)
+	if err != nil {
+		return err
+	}
+
+	client := newHTTPClient("webhook", 15*time.Second)
+	return DefaultRetryPolicy.Do(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, "POST", config.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "SpotiFLAC")
+		req.Header.Set("X-SpotiFLAC-Event", payload.Event)
+		if config.Secret != "" {
+			req.Header.Set(WebhookSignatureHeader, SignWebhook(config.Secret, body))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return statusError("webhook", resp)
+		}
+		return nil
+	})
+}
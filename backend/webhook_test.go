@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookBody(t *testing.T) {
+	payload := WebhookPayload{Event: WebhookBatchComplete, Name: "Album", Total: 3, Completed: 2, Failed: 1, Failures: []string{"a", "b"}}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{name: "default", want: `{"event":"batch_complete","time":0,"name":"Album","total":3,"completed":2,"skipped":0,"failed":1,"interrupted":false,"failures":["a","b"]}`},
+		{name: "template", template: `{"text": {{json (printf "%s: %d/%d" .Name .Completed .Total)}}, "failures": {{json (join .Failures ", ")}}}`, want: `{"text": "Album: 2/3", "failures": "a, b"}`},
+		{name: "invalid JSON", template: `{"text": "{{.Name}}"`, wantErr: true},
+		{name: "invalid template", template: `{{.Name`, wantErr: true},
+		{name: "unknown field", template: `{{.Missing}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config WebhookConfig
+			if tt.template != "" {
+				config.TemplatePath = filepath.Join(t.TempDir(), "webhook.tmpl")
+				if err := os.WriteFile(config.TemplatePath, []byte(tt.template), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			body, err := config.Body(payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Body() error = %v, want error %v", err, tt.wantErr)
+			}
+			if string(body) != tt.want {
+				t.Errorf("Body() = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	defer func(policy RetryPolicy) { DefaultRetryPolicy = policy }(DefaultRetryPolicy)
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "accepted", statuses: []int{http.StatusNoContent}, wantCalls: 1},
+		{name: "retried on 5xx", statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}, wantCalls: 3},
+		{name: "gives up after retries", statuses: []int{500, 500, 500}, wantCalls: 3, wantErr: true},
+		{name: "4xx is not retried", statuses: []int{http.StatusBadRequest, http.StatusOK}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if got, want := r.Header.Get(WebhookSignatureHeader), SignWebhook("secret", body); got != want {
+					t.Errorf("signature = %q, want %q", got, want)
+				}
+				if !json.Valid(body) {
+					t.Errorf("body is not JSON: %s", body)
+				}
+				if event := r.Header.Get("X-SpotiFLAC-Event"); event != WebhookSyncComplete {
+					t.Errorf("event header = %q", event)
+				}
+
+				mu.Lock()
+				status := tt.statuses[calls]
+				calls++
+				mu.Unlock()
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			config := WebhookConfig{URL: server.URL, Secret: "secret"}
+			err := SendWebhook(context.Background(), config, WebhookPayload{Event: WebhookSyncComplete, Name: "Playlist"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SendWebhook() error = %v, want error %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("server got %d requests, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	// HMAC-SHA256 test case 2 from RFC 4231
+	got := SignWebhook("Jefe", []byte("what do ya want for nothing?"))
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("SignWebhook() = %s, want %s", got, want)
+	}
+}
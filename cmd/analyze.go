@@ -41,6 +41,10 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("analysis failed: %w", err)
 	}
 
+	if machineOutput() {
+		return writeResult(result)
+	}
+
 	if analyzeFormat == "json" {
 		jsonBytes, err := json.MarshalIndent(result, "", "  ")
 		if err != nil {
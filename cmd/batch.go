@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"spotiflac/backend"
+)
+
+type downloadOptions struct {
+	OutputDir            string
+	Service              string
+	Quality              string
+	FilenameFormat       string
+	TidalAPI             string
+	SkipExisting         string
+	EmbedLyrics          bool
+	EmbedMaxQualityCover bool
+	EmbedCredits         bool
+	ReplayGain           bool
+	TrackNumber          bool
+	UseAlbumTrack        bool
+
+	// Album, playlist or file being downloaded, passed on to hooks
+	CollectionType string
+	CollectionName string
+	Hooks          hookConfig
+}
+
+type batchSummary struct {
+	Total       int
+	Completed   int
+	Skipped     int
+	Failed      int
+	Interrupted bool
+	Failures    []string
+}
+
+func downloadOptionsFromFlags() downloadOptions {
+	quality := downloadQuality
+	if quality == "" {
+		quality = downloadFormat
+	}
+
+	return downloadOptions{
+		OutputDir:            downloadOutputDir,
+		Service:              downloadService,
+		Quality:              quality,
+		FilenameFormat:       downloadFilenameFormat,
+		TidalAPI:             downloadTidalAPI,
+		SkipExisting:         downloadSkipExisting,
+		EmbedLyrics:          downloadEmbedLyrics,
+		EmbedMaxQualityCover: downloadEmbedMaxQuality,
+		EmbedCredits:         downloadEmbedCredits,
+		ReplayGain:           downloadReplayGain,
+		TrackNumber:          downloadTrackNumber,
+		UseAlbumTrack:        downloadUseAlbumTrack,
+	}
+}
+
+// Options for unattended downloads (queue, watch) come from the saved config
+func downloadOptionsFromConfig() downloadOptions {
+	config, err := loadConfig(getConfigPath())
+	if err != nil {
+		config = getDefaultConfig()
+	}
+
+	getString := func(key string) string {
+		if v, ok := config[key].(string); ok {
+			return v
+		}
+		if v, ok := getDefaultConfig()[key].(string); ok {
+			return v
+		}
+		return ""
+	}
+	getBool := func(key string) bool {
+		v, _ := config[key].(bool)
+		return v
+	}
+
+	opts := downloadOptions{
+		OutputDir:            getString("download-path"),
+		Service:              getString("downloader"),
+		FilenameFormat:       getString("filename-format"),
+		TidalAPI:             "auto",
+		SkipExisting:         getString("skip-existing"),
+		EmbedLyrics:          getBool("embed-lyrics"),
+		EmbedMaxQualityCover: getBool("embed-max-quality"),
+		EmbedCredits:         getBool("embed-credits"),
+		ReplayGain:           getBool("replaygain"),
+		TrackNumber:          getBool("track-number"),
+	}
+	if opts.Service == "qobuz" {
+		opts.Quality = getString("qobuz-quality")
+	} else {
+		opts.Quality = getString("tidal-quality")
+	}
+
+	return opts
+}
+
+func (o downloadOptions) prepare() (downloadOptions, error) {
+	if o.OutputDir == "" {
+		o.OutputDir = backend.GetDefaultMusicPath()
+	}
+	o.OutputDir = backend.NormalizePath(o.OutputDir)
+
+	if err := os.MkdirAll(o.OutputDir, 0755); err != nil {
+		return o, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if o.Service == "" || o.Service == "auto" {
+		o.Service = "tidal" // Default to Tidal
+	}
+	if o.Quality == "" {
+		o.Quality = "LOSSLESS"
+	}
+	o.Hooks = loadHooks()
+
+	return o, nil
+}
+
+func downloadSpotifyTrack(ctx context.Context, spotifyID string, opts downloadOptions, position int) (DownloadResponse, *TrackInfo, error) {
+	fmt.Printf("📍 Fetching metadata for: %s\n", spotifyID)
+	spotifyURL := fmt.Sprintf("https://open.spotify.com/track/%s", spotifyID)
+
+	trackData, err := backend.GetFilteredSpotifyData(ctx, spotifyURL, false, 0)
+	if err != nil {
+		return DownloadResponse{}, nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	trackInfo := extractTrackInfo(trackData)
+	if trackInfo == nil {
+		return DownloadResponse{}, nil, fmt.Errorf("failed to extract track information")
+	}
+
+	backend.UpdateItemInfo(spotifyID, trackInfo.Title, trackInfo.Artist, trackInfo.Album, trackInfo.ISRC)
+	// No lookup is made unless genre-limit is set
+	trackInfo.Genres = backend.GenresForArtist(ctx, trackInfo.ArtistID, "SpotiFLAC")
+	if opts.EmbedCredits {
+		credits, err := backend.FetchTrackCredits(ctx, spotifyID)
+		if err != nil {
+			fmt.Printf("⚠️  Failed to fetch credits: %v\n", err)
+		}
+		trackInfo.Credits = credits
+	}
+
+	fmt.Printf("📀 Title: %s\n", trackInfo.Title)
+	fmt.Printf("🎤 Artist: %s\n", trackInfo.Artist)
+	fmt.Printf("💿 Album: %s\n", trackInfo.Album)
+	if len(trackInfo.Genres) > 0 {
+		fmt.Printf("🎸 Genre: %s\n", backend.JoinMultiValue(trackInfo.Genres))
+	}
+
+	fmt.Printf("⬇️  Downloading from %s with quality %s...\n", opts.Service, opts.Quality)
+
+	req := DownloadRequest{
+		ISRC:                 trackInfo.ISRC,
+		Service:              opts.Service,
+		SpotifyID:            spotifyID,
+		TrackName:            trackInfo.Title,
+		ArtistName:           trackInfo.Artist,
+		AlbumName:            trackInfo.Album,
+		AlbumArtist:          trackInfo.AlbumArtist,
+		ReleaseDate:          trackInfo.ReleaseDate,
+		CoverURL:             trackInfo.CoverURL,
+		Publisher:            trackInfo.Label,
+		SpotifyAlbumID:       trackInfo.AlbumID,
+		Explicit:             trackInfo.Explicit,
+		Artists:              trackInfo.Artists,
+		AlbumArtists:         trackInfo.AlbumArtists,
+		Genres:               trackInfo.Genres,
+		Credits:              trackInfo.Credits,
+		OutputDir:            opts.OutputDir,
+		AudioFormat:          opts.Quality,
+		FilenameFormat:       opts.FilenameFormat,
+		TrackNumber:          opts.TrackNumber,
+		Position:             position,
+		UseAlbumTrackNumber:  opts.UseAlbumTrack,
+		EmbedLyrics:          opts.EmbedLyrics,
+		EmbedMaxQualityCover: opts.EmbedMaxQualityCover,
+		ApiURL:               opts.TidalAPI,
+		SkipExisting:         backend.SkipExistingMode(opts.SkipExisting),
+	}
+
+	resp, err := downloadTrack(ctx, req)
+	if err != nil {
+		return resp, trackInfo, fmt.Errorf("download failed: %w", err)
+	}
+	if !resp.Success {
+		// The service reported a failure without a cause to classify
+		return resp, trackInfo, &backend.ServiceError{Kind: backend.ErrUpstreamDown, Service: opts.Service, Message: resp.Error}
+	}
+
+	// Albums are measured together once the last track is in
+	if opts.ReplayGain && !resp.AlreadyExists && opts.CollectionType != "album" {
+		for _, result := range backend.ApplyReplayGain([]string{resp.File}, false) {
+			printReplayGainResult(result)
+		}
+	}
+
+	return resp, trackInfo, nil
+}
+
+// runBatch downloads tracks one after another, recording each one in the
+// progress queue. onResult is called after every track, successful or not.
+func runBatch(ctx context.Context, tracks []backend.ImportTrack, opts downloadOptions, onResult func(track backend.ImportTrack, resp DownloadResponse, err error)) batchSummary {
+	summary := batchSummary{Total: len(tracks)}
+	var albumFiles []string
+
+	for _, track := range tracks {
+		backend.AddToQueue(track.SpotifyID, track.Name, track.Artists, track.Album, "")
+	}
+
+	for i, track := range tracks {
+		if ctx.Err() != nil {
+			backend.CancelAllQueuedItems()
+			summary.Interrupted = true
+			break
+		}
+
+		if paused, resume := backend.DownloadsPaused(time.Now()); paused {
+			fmt.Printf("\n⏸️  Downloads paused until %s (download-windows)\n", resume.Format("15:04"))
+			if err := backend.WaitForDownloadWindow(ctx); err != nil {
+				backend.CancelAllQueuedItems()
+				summary.Interrupted = true
+				break
+			}
+			fmt.Println("▶️  Resuming downloads")
+		}
+
+		fmt.Printf("\n[%d/%d] ", i+1, len(tracks))
+		backend.StartDownloadItem(track.SpotifyID)
+
+		// Only transient failures (rate limits, outages, network errors,
+		// corrupt output) are retried; the rest are reported straight away
+		var resp DownloadResponse
+		var info *TrackInfo
+		err := backend.DefaultRetryPolicy.Do(ctx, func() error {
+			var err error
+			resp, info, err = downloadSpotifyTrack(ctx, track.SpotifyID, opts, i+1)
+			return err
+		})
+		finishQueueItem(ctx, opts.Service, track.SpotifyID, resp, err)
+		if info == nil {
+			info = &TrackInfo{Title: track.Name, Artist: track.Artists, Album: track.Album}
+		}
+		runTrackHook(ctx, opts, track.SpotifyID, info, resp, err)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// Partial files are removed by the downloader; the track stays pending
+			summary.Interrupted = true
+			fmt.Println("⏹️  Interrupted")
+		case err != nil:
+			summary.Failed++
+			summary.Failures = append(summary.Failures, fmt.Sprintf("%s: [%s] %v", track.SpotifyID, backend.ErrorKind(err), err))
+			fmt.Printf("❌ %v\n", err)
+		case resp.AlreadyExists:
+			summary.Skipped++
+			fmt.Printf("⏭️  %s\n", resp.Message)
+		default:
+			summary.Completed++
+			fmt.Printf("✅ %s\n", resp.Message)
+		}
+		if err == nil && resp.File != "" {
+			albumFiles = append(albumFiles, resp.File)
+		}
+
+		if onResult != nil {
+			onResult(track, resp, err)
+		}
+	}
+
+	if opts.ReplayGain && opts.CollectionType == "album" && !summary.Interrupted && len(albumFiles) > 0 {
+		fmt.Printf("\n🔊 Measuring loudness of %d tracks...\n", len(albumFiles))
+		results := backend.ApplyReplayGain(albumFiles, true)
+		for _, result := range results {
+			printReplayGainResult(result)
+		}
+		printAlbumGain(results)
+	}
+
+	runAlbumHook(ctx, opts, summary)
+	return summary
+}
+
+func downloadOutcome(ctx context.Context, resp DownloadResponse, err error) backend.DownloadStatus {
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return backend.StatusInterrupted
+	case err != nil:
+		return backend.StatusFailed
+	case resp.AlreadyExists:
+		return backend.StatusSkipped
+	default:
+		return backend.StatusCompleted
+	}
+}
+
+// finishQueueItem records the outcome of a download, after any retries, in
+// the progress queue and the download metrics
+func finishQueueItem(ctx context.Context, service, id string, resp DownloadResponse, err error) {
+	status := downloadOutcome(ctx, resp, err)
+	backend.RecordDownload(service, status)
+	switch status {
+	case backend.StatusInterrupted:
+		backend.InterruptDownloadItem(id)
+	case backend.StatusFailed:
+		backend.FailDownloadItem(id, err.Error())
+	case backend.StatusSkipped:
+		backend.SkipDownloadItem(id, resp.File)
+	default:
+		var size float64
+		if info, statErr := os.Stat(resp.File); statErr == nil {
+			size = float64(info.Size()) / (1024 * 1024)
+		}
+		backend.CompleteDownloadItem(id, resp.File, size)
+	}
+}
+
+func printBatchSummary(summary batchSummary) {
+	if summary.Interrupted {
+		fmt.Printf("\n⏹️  Batch interrupted: %d completed, %d skipped, %d failed, %d not downloaded (of %d)\n",
+			summary.Completed, summary.Skipped, summary.Failed, summary.Total-summary.Completed-summary.Skipped-summary.Failed, summary.Total)
+	} else {
+		fmt.Printf("\n📋 Batch finished: %d completed, %d skipped, %d failed (of %d)\n",
+			summary.Completed, summary.Skipped, summary.Failed, summary.Total)
+	}
+	for _, failure := range summary.Failures {
+		fmt.Printf("   ❌ %s\n", failure)
+	}
+}
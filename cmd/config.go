@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"spotiflac/backend"
 
@@ -154,10 +156,141 @@ var configSetCmd = &cobra.Command{
 			config[key] = value == "true" || value == "yes" || value == "1"
 			fmt.Printf("✅ embed-lyrics set to: %v\n", config[key])
 
+		case "embed-credits":
+			config[key] = value == "true" || value == "yes" || value == "1"
+			fmt.Printf("✅ embed-credits set to: %v\n", config[key])
+
+		case "replaygain":
+			config[key] = value == "true" || value == "yes" || value == "1"
+			fmt.Printf("✅ replaygain set to: %v\n", config[key])
+
 		case "track-number":
 			config[key] = value == "true" || value == "yes" || value == "1"
 			fmt.Printf("✅ track-number set to: %v\n", config[key])
 
+		case "skip-existing":
+			if !isValidSkipExisting(value) {
+				return fmt.Errorf("invalid skip-existing: %s (must be: isrc, filename or none)", value)
+			}
+			config[key] = value
+			fmt.Printf("✅ skip-existing set to: %s\n", value)
+
+		case "limit-rate":
+			rate, err := backend.ParseRate(value)
+			if err != nil {
+				return err
+			}
+			config[key] = value
+			fmt.Printf("✅ limit-rate set to: %s\n", backend.FormatRate(rate))
+
+		case "download-windows":
+			if _, err := backend.ParseRateWindows(value); err != nil {
+				return err
+			}
+			config[key] = value
+			fmt.Printf("✅ download-windows set to: %s\n", value)
+
+		case "proxy":
+			if value != "" {
+				if err := (backend.HTTPConfig{Proxy: value}).Validate(); err != nil {
+					return err
+				}
+			}
+			config[key] = value
+			fmt.Printf("✅ proxy set to: %s\n", value)
+
+		case "ca-bundle":
+			if value != "" {
+				if err := (backend.HTTPConfig{CABundle: value}).Validate(); err != nil {
+					return err
+				}
+			}
+			config[key] = value
+			fmt.Printf("✅ ca-bundle set to: %s\n", value)
+
+		case "insecure-tls":
+			config[key] = value
+			if value == "" {
+				fmt.Println("✅ insecure-tls disabled")
+			} else {
+				fmt.Printf("⚠️  insecure-tls set to: %s (certificates for these services are not verified)\n", value)
+			}
+
+		case "tag-merge":
+			if _, err := backend.ParseTagMergeMode(value); err != nil {
+				return err
+			}
+			config[key] = value
+			fmt.Printf("✅ tag-merge set to: %s\n", value)
+
+		case "multi-value-separator":
+			if value == "" {
+				return fmt.Errorf("multi-value-separator cannot be empty")
+			}
+			config[key] = value
+			fmt.Printf("✅ multi-value-separator set to: %q\n", value)
+
+		case "genre-limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				return fmt.Errorf("invalid genre-limit: %s (must be 0 or a positive number)", value)
+			}
+			config[key] = value
+			if limit == 0 {
+				fmt.Println("✅ genre tags disabled")
+			} else {
+				fmt.Printf("✅ genre-limit set to: %d\n", limit)
+			}
+
+		case "genre-map":
+			if value != "" {
+				expanded, err := expandPath(value)
+				if err != nil {
+					return fmt.Errorf("invalid path: %w", err)
+				}
+				value = expanded
+				if _, err := backend.LoadGenreMapping(value); err != nil {
+					return err
+				}
+			}
+			config[key] = value
+			fmt.Printf("✅ genre-map set to: %s\n", value)
+
+		case "http-timeouts":
+			if _, err := backend.ParseHTTPTimeouts(value); err != nil {
+				return err
+			}
+			config[key] = value
+			fmt.Printf("✅ http-timeouts set to: %s\n", value)
+
+		case "webhook-url":
+			if value != "" && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+				return fmt.Errorf("invalid webhook-url: %s (must be an http or https URL)", value)
+			}
+			config[key] = value
+			fmt.Printf("✅ webhook-url set to: %s\n", value)
+
+		case "webhook-secret":
+			config[key] = value
+			fmt.Println("✅ webhook-secret set")
+
+		case "webhook-template":
+			if value != "" {
+				if _, err := os.Stat(backend.NormalizePath(value)); err != nil {
+					return fmt.Errorf("invalid webhook-template: %w", err)
+				}
+			}
+			config[key] = value
+			fmt.Printf("✅ webhook-template set to: %s\n", value)
+
+		case "hook", "hook-on-track-complete", "hook-on-album-complete", "hook-on-failure":
+			config[key] = value
+			if value == "" {
+				fmt.Printf("✅ %s disabled\n", key)
+			} else {
+				fmt.Printf("✅ %s set to: %s\n", key, value)
+			}
+
 		default:
 			return fmt.Errorf("unknown configuration key: %s", key)
 		}
@@ -243,15 +376,35 @@ func saveConfig(path string, config map[string]interface{}) error {
 func getDefaultConfig() map[string]interface{} {
 	defaultPath := backend.GetDefaultMusicPath()
 	return map[string]interface{}{
-		"download-path":     defaultPath,
-		"downloader":        "auto",
-		"tidal-quality":     "LOSSLESS",
-		"qobuz-quality":     "6",
-		"filename-format":   "title-artist",
-		"folder-structure":  "none",
-		"embed-lyrics":      false,
-		"embed-max-quality": false,
-		"track-number":      false,
+		"download-path":          defaultPath,
+		"downloader":             "auto",
+		"tidal-quality":          "LOSSLESS",
+		"qobuz-quality":          "6",
+		"filename-format":        "title-artist",
+		"folder-structure":       "none",
+		"embed-lyrics":           false,
+		"embed-max-quality":      false,
+		"embed-credits":          false,
+		"replaygain":             false,
+		"track-number":           false,
+		"skip-existing":          "isrc",
+		"limit-rate":             "0",
+		"download-windows":       "",
+		"hook":                   "",
+		"hook-on-track-complete": "",
+		"hook-on-album-complete": "",
+		"hook-on-failure":        "",
+		"webhook-url":            "",
+		"webhook-secret":         "",
+		"webhook-template":       "",
+		"proxy":                  "",
+		"ca-bundle":              "",
+		"insecure-tls":           "",
+		"http-timeouts":          "",
+		"tag-merge":              "overwrite",
+		"multi-value-separator":  ", ",
+		"genre-limit":            "0",
+		"genre-map":              "",
 	}
 }
 
@@ -303,6 +456,15 @@ func isValidFilenameFormat(value string) bool {
 	return valid[value]
 }
 
+func isValidSkipExisting(value string) bool {
+	valid := map[string]bool{
+		"isrc":     true,
+		"filename": true,
+		"none":     true,
+	}
+	return valid[value]
+}
+
 func isValidFolderStructure(value string) bool {
 	valid := map[string]bool{
 		"none":                    true,
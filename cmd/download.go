@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,18 +13,26 @@ import (
 )
 
 var downloadCmd = &cobra.Command{
-	Use:   "download <spotify-url|spotify-id>",
-	Short: "Download a Spotify track in FLAC quality",
+	Use:   "download [spotify-url|spotify-id]",
+	Short: "Download a Spotify track, album or playlist in FLAC quality",
 	Long: `Download a Spotify track in FLAC quality from Tidal, Qobuz, or Amazon Music.
 
 Supports Spotify URLs (https://open.spotify.com/track/...) or Spotify track IDs.
+Album and playlist URLs download every track; add --m3u8 and/or --xspf to
+write a playlist file next to them.
+
+With --from-file, reads a list of tracks instead: plain text with one URL/URI
+per line, "Artist - Title" lines, or a CSV with a track URI or ISRC column
+(Exportify format). Lines without a URI are matched through Spotify search.
 
 Examples:
   spotflac download https://open.spotify.com/track/4cOdK2wGLETKBW3PvgPWqLv
   spotflac download 4cOdK2wGLETKBW3PvgPWqLv --service tidal
   spotflac download 4cOdK2wGLETKBW3PvgPWqLv -o ~/Music --embed-lyrics
-  spotflac download 4cOdK2wGLETKBW3PvgPWqLv --service qobuz --quality 24`,
-	Args: cobra.ExactArgs(1),
+  spotflac download 4cOdK2wGLETKBW3PvgPWqLv --service qobuz --quality 24
+  spotflac download https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M --m3u8 --xspf
+  spotflac download --from-file playlist.csv --report report.json`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runDownload,
 }
 
@@ -36,13 +45,21 @@ var (
 	downloadFolderTemplate  string
 	downloadEmbedLyrics     bool
 	downloadEmbedMaxQuality bool
+	downloadEmbedCredits    bool
+	downloadReplayGain      bool
 	downloadTrackNumber     bool
 	downloadUseAlbumTrack   bool
 	downloadTidalAPI        string
+	downloadFromFile        string
+	downloadMinConfidence   float64
+	downloadReportPath      string
+	downloadM3U8            bool
+	downloadXSPF            bool
+	downloadSkipExisting    string
 )
 
 func init() {
-	downloadCmd.Flags().StringVarP(&downloadOutputDir, "output", "o", "", "Output directory (default: music folder)")
+	downloadCmd.Flags().StringVarP(&downloadOutputDir, "output-dir", "o", "", "Output directory (default: music folder)")
 	downloadCmd.Flags().StringVarP(&downloadService, "service", "s", "auto", "Streaming service: auto, tidal, qobuz, amazon")
 	downloadCmd.Flags().StringVarP(&downloadQuality, "quality", "q", "", "Audio quality (tidal: LOSSLESS|HI_RES_LOSSLESS, qobuz: 6|7, amazon: original)")
 	downloadCmd.Flags().StringVarP(&downloadFormat, "format", "f", "LOSSLESS", "Audio format (deprecated, use --quality)")
@@ -50,14 +67,44 @@ func init() {
 	downloadCmd.Flags().StringVar(&downloadFolderTemplate, "folder", "none", "Folder structure: none|artist|album|artist-album|year-album|year-artist-album|custom")
 	downloadCmd.Flags().BoolVar(&downloadEmbedLyrics, "embed-lyrics", false, "Embed lyrics in FLAC files")
 	downloadCmd.Flags().BoolVar(&downloadEmbedMaxQuality, "embed-max-quality-cover", false, "Embed maximum quality album cover")
+	downloadCmd.Flags().BoolVar(&downloadEmbedCredits, "credits", false, "Fetch songwriter, producer and performer credits and embed them")
+	downloadCmd.Flags().BoolVar(&downloadReplayGain, "replaygain", false, "Measure loudness and write ReplayGain tags (album gain for albums)")
 	downloadCmd.Flags().BoolVar(&downloadTrackNumber, "track-number", false, "Include track number in filename")
 	downloadCmd.Flags().BoolVar(&downloadUseAlbumTrack, "use-album-track", false, "Use album track number instead of position")
 	downloadCmd.Flags().StringVar(&downloadTidalAPI, "tidal-api", "auto", "Tidal API endpoint (auto or custom URL)")
+	downloadCmd.Flags().StringVar(&downloadFromFile, "from-file", "", "Download every track listed in a text or CSV file")
+	downloadCmd.Flags().Float64Var(&downloadMinConfidence, "min-confidence", backend.DefaultImportConfidence, "Minimum search match score (0-1) for lines without a Spotify URI")
+	downloadCmd.Flags().StringVar(&downloadReportPath, "report", "", "Write the import report to a JSON file")
+	downloadCmd.Flags().BoolVar(&downloadM3U8, "m3u8", false, "Write an .m3u8 playlist for album/playlist downloads")
+	downloadCmd.Flags().BoolVar(&downloadXSPF, "xspf", false, "Write an .xspf playlist for album/playlist downloads")
+	downloadCmd.Flags().StringVar(&downloadSkipExisting, "skip-existing", "isrc", "Skip tracks already downloaded: isrc (library index), filename, none")
+	addMetricsFlag(downloadCmd)
 }
 
 func runDownload(cmd *cobra.Command, args []string) error {
+	if downloadFromFile == "" && len(args) == 0 {
+		return fmt.Errorf("requires a Spotify URL/ID or --from-file")
+	}
+	if !isValidSkipExisting(downloadSkipExisting) {
+		return fmt.Errorf("invalid --skip-existing value: %s (use isrc, filename or none)", downloadSkipExisting)
+	}
+
+	opts, err := downloadOptionsFromFlags().prepare()
+	if err != nil {
+		return err
+	}
+
+	if downloadFromFile != "" {
+		return runDownloadFromFile(cmd, downloadFromFile, opts)
+	}
+
 	spotifyInput := args[0]
 
+	isSpotifyURL := strings.Contains(spotifyInput, "spotify.com") || strings.HasPrefix(spotifyInput, "spotify:")
+	if isSpotifyURL && !backend.IsSpotifyTrackURL(spotifyInput) {
+		return runDownloadCollection(cmd, spotifyInput, opts)
+	}
+
 	// Parse Spotify URL or ID
 	spotifyID := spotifyInput
 	if strings.Contains(spotifyInput, "spotify.com") {
@@ -71,89 +118,136 @@ func runDownload(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid Spotify ID (must be 22 characters)")
 	}
 
-	// Determine output directory
-	if downloadOutputDir == "" {
-		downloadOutputDir = backend.GetDefaultMusicPath()
+	backend.AddToQueue(spotifyID, "", "", "", "")
+	backend.StartDownloadItem(spotifyID)
+	resp, info, err := downloadSpotifyTrack(cmd.Context(), spotifyID, opts, 0)
+	finishQueueItem(cmd.Context(), opts.Service, spotifyID, resp, err)
+	runTrackHook(cmd.Context(), opts, spotifyID, info, resp, err)
+	if err != nil {
+		return err
 	}
-	downloadOutputDir = backend.NormalizePath(downloadOutputDir)
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(downloadOutputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	if machineOutput() {
+		return writeResult(newDownloadResult(spotifyID, resp, nil))
 	}
 
-	// Fetch Spotify metadata
-	fmt.Printf("📍 Fetching metadata for: %s\n", spotifyID)
-	spotifyURL := fmt.Sprintf("https://open.spotify.com/track/%s", spotifyID)
+	fmt.Printf("✅ %s\n", resp.Message)
+	if resp.File != "" {
+		fmt.Printf("📁 Saved to: %s\n", resp.File)
+	}
 
-	trackData, err := backend.GetFilteredSpotifyData(cmd.Context(), spotifyURL, false, 0)
+	return nil
+}
+
+func runDownloadCollection(cmd *cobra.Command, spotifyURL string, opts downloadOptions) error {
+	fmt.Printf("📍 Fetching tracks for: %s\n", spotifyURL)
+	collection, err := backend.GetSpotifyCollection(cmd.Context(), spotifyURL)
 	if err != nil {
 		return fmt.Errorf("failed to fetch metadata: %w", err)
 	}
 
-	// Extract track info
-	trackInfo := extractTrackInfo(trackData)
-	if trackInfo == nil {
-		return fmt.Errorf("failed to extract track information")
-	}
+	fmt.Printf("📋 %s (%s, %d tracks)\n", collection.Name, collection.Type, len(collection.Tracks))
 
-	fmt.Printf("📀 Title: %s\n", trackInfo.Title)
-	fmt.Printf("🎤 Artist: %s\n", trackInfo.Artist)
-	fmt.Printf("💿 Album: %s\n", trackInfo.Album)
+	opts.CollectionType = collection.Type
+	opts.CollectionName = collection.Name
 
-	// Determine service
-	service := downloadService
-	if service == "auto" {
-		service = "tidal" // Default to Tidal
+	var tracks []backend.ImportTrack
+	for _, t := range collection.Tracks {
+		if t.SpotifyID != "" {
+			tracks = append(tracks, backend.ImportTrack{SpotifyID: t.SpotifyID, Name: t.Name, Artists: t.Artists, Album: t.AlbumName, Duration: t.DurationMS})
+		}
 	}
 
-	// Set quality
-	quality := downloadQuality
-	if quality == "" {
-		quality = downloadFormat
-	}
-	if quality == "" {
-		quality = "LOSSLESS"
+	files := make(map[string]string)
+	var results []downloadResult
+	summary := runBatch(cmd.Context(), tracks, opts, func(track backend.ImportTrack, resp DownloadResponse, err error) {
+		if err == nil && resp.File != "" {
+			files[track.SpotifyID] = resp.File
+		}
+		results = append(results, newDownloadResult(track.SpotifyID, resp, err))
+	})
+	printBatchSummary(summary)
+	sendWebhook(cmd.Context(), batchWebhookPayload(backend.WebhookBatchComplete, collection.Name, collection.Type, summary))
+
+	result := newBatchResult(summary, results)
+	result.Name = collection.Name
+	result.Type = collection.Type
+
+	if downloadM3U8 || downloadXSPF {
+		var entries []backend.PlaylistFileEntry
+		for _, track := range tracks {
+			if path, ok := files[track.SpotifyID]; ok {
+				entries = append(entries, backend.PlaylistFileEntry{Path: path, Title: track.Name, Artist: track.Artists, Album: track.Album, DurationMS: track.Duration})
+			}
+		}
+
+		var exts []string
+		if downloadM3U8 {
+			exts = append(exts, ".m3u8")
+		}
+		if downloadXSPF {
+			exts = append(exts, ".xspf")
+		}
+		for _, ext := range exts {
+			playlistPath := backend.PlaylistFilePath(opts.OutputDir, collection.Name, ext)
+			if err := backend.WritePlaylistFile(playlistPath, collection.Name, entries); err != nil {
+				return fmt.Errorf("failed to write playlist: %w", err)
+			}
+			fmt.Printf("📁 Playlist: %s\n", playlistPath)
+			result.Playlists = append(result.Playlists, playlistPath)
+		}
 	}
 
-	fmt.Printf("⬇️  Downloading from %s with quality %s...\n", service, quality)
+	if machineOutput() {
+		if err := writeResult(result); err != nil {
+			return err
+		}
+	}
 
-	// Build download request
-	req := DownloadRequest{
-		ISRC:                 trackInfo.ISRC,
-		Service:              service,
-		SpotifyID:            spotifyID,
-		TrackName:            trackInfo.Title,
-		ArtistName:           trackInfo.Artist,
-		AlbumName:            trackInfo.Album,
-		AlbumArtist:          trackInfo.AlbumArtist,
-		ReleaseDate:          trackInfo.ReleaseDate,
-		CoverURL:             trackInfo.CoverURL,
-		OutputDir:            downloadOutputDir,
-		AudioFormat:          quality,
-		FilenameFormat:       downloadFilenameFormat,
-		TrackNumber:          downloadTrackNumber,
-		UseAlbumTrackNumber:  downloadUseAlbumTrack,
-		EmbedLyrics:          downloadEmbedLyrics,
-		EmbedMaxQualityCover: downloadEmbedMaxQuality,
-		ApiURL:               downloadTidalAPI,
+	if summary.Interrupted {
+		return cmd.Context().Err()
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", summary.Failed, summary.Total)
 	}
+	return nil
+}
 
-	// Execute download
-	resp, err := downloadTrack(req)
+func runDownloadFromFile(cmd *cobra.Command, path string, opts downloadOptions) error {
+	report, err := importFile(cmd.Context(), path, downloadMinConfidence, downloadReportPath)
 	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
+		return err
 	}
 
-	if !resp.Success {
-		return fmt.Errorf("download failed: %s", resp.Error)
+	tracks := report.Tracks()
+	if len(tracks) == 0 {
+		return fmt.Errorf("no tracks resolved from %s", path)
 	}
-
-	fmt.Printf("✅ %s\n", resp.Message)
-	if resp.File != "" {
-		fmt.Printf("📁 Saved to: %s\n", resp.File)
+	opts.CollectionType = "file"
+	opts.CollectionName = filepath.Base(path)
+
+	var results []downloadResult
+	summary := runBatch(cmd.Context(), tracks, opts, func(track backend.ImportTrack, resp DownloadResponse, err error) {
+		results = append(results, newDownloadResult(track.SpotifyID, resp, err))
+	})
+	printBatchSummary(summary)
+	sendWebhook(cmd.Context(), batchWebhookPayload(backend.WebhookBatchComplete, filepath.Base(path), "file", summary))
+
+	if machineOutput() {
+		result := newBatchResult(summary, results)
+		result.Name = filepath.Base(path)
+		result.Type = "file"
+		if err := writeResult(result); err != nil {
+			return err
+		}
 	}
 
+	if summary.Interrupted {
+		return cmd.Context().Err()
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", summary.Failed, summary.Total)
+	}
 	return nil
 }
 
@@ -175,12 +269,21 @@ type TrackInfo struct {
 	ReleaseDate string
 	CoverURL    string
 	ISRC        string
+	Label       string
+	AlbumID     string
+	Explicit    bool
+
+	Artists      []string
+	AlbumArtists []string
+	ArtistID     string
+	Genres       []string
+	Credits      backend.Credits
 }
 
 func extractTrackInfo(data interface{}) *TrackInfo {
 	// Handle backend.TrackResponse struct
 	if trackResp, ok := data.(backend.TrackResponse); ok {
-		return &TrackInfo{
+		info := &TrackInfo{
 			Title:       trackResp.Track.Name,
 			Artist:      trackResp.Track.Artists,
 			Album:       trackResp.Track.AlbumName,
@@ -188,7 +291,24 @@ func extractTrackInfo(data interface{}) *TrackInfo {
 			CoverURL:    trackResp.Track.Images,
 			ReleaseDate: trackResp.Track.ReleaseDate,
 			ISRC:        trackResp.Track.ISRC,
+			Label:       trackResp.Track.Publisher,
+			AlbumID:     trackResp.Track.AlbumID,
+			Explicit:    trackResp.Track.Explicit,
+
+			Artists:      artistNames(trackResp.Track.ArtistsData),
+			AlbumArtists: artistNames(trackResp.Track.AlbumArtistsData),
+		}
+		if len(trackResp.Track.ArtistsData) > 0 {
+			info.ArtistID = trackResp.Track.ArtistsData[0].ID
 		}
+		// Rejoin with the configured separator; filenames use this form
+		if len(info.Artists) > 0 {
+			info.Artist = backend.JoinMultiValue(info.Artists)
+		}
+		if len(info.AlbumArtists) > 0 {
+			info.AlbumArtist = backend.JoinMultiValue(info.AlbumArtists)
+		}
+		return info
 	}
 
 	// Also handle map-based responses for compatibility
@@ -245,6 +365,14 @@ func extractTrackInfo(data interface{}) *TrackInfo {
 	return nil
 }
 
+func artistNames(artists []backend.ArtistSimple) []string {
+	var names []string
+	for _, artist := range artists {
+		names = append(names, artist.Name)
+	}
+	return names
+}
+
 type DownloadRequest struct {
 	ISRC                 string
 	Service              string
@@ -270,6 +398,13 @@ type DownloadRequest struct {
 	SpotifyTotalDiscs    int
 	Copyright            string
 	Publisher            string
+	SpotifyAlbumID       string
+	Explicit             bool
+	Artists              []string
+	AlbumArtists         []string
+	Genres               []string
+	Credits              backend.Credits
+	SkipExisting         backend.SkipExistingMode
 }
 
 type DownloadResponse struct {
@@ -281,7 +416,7 @@ type DownloadResponse struct {
 	ItemID        string
 }
 
-func downloadTrack(req DownloadRequest) (DownloadResponse, error) {
+func downloadTrack(ctx context.Context, req DownloadRequest) (DownloadResponse, error) {
 	if req.OutputDir == "" {
 		req.OutputDir = "."
 	} else {
@@ -296,36 +431,86 @@ func downloadTrack(req DownloadRequest) (DownloadResponse, error) {
 		req.FilenameFormat = "title-artist"
 	}
 
+	if req.SkipExisting == "" {
+		req.SkipExisting = backend.SkipExistingISRC
+	}
+
 	var filename string
 	var err error
 
-	// Check if file already exists
-	if req.TrackName != "" && req.ArtistName != "" {
-		expectedFilename := backend.BuildExpectedFilename(req.TrackName, req.ArtistName, req.AlbumName, req.AlbumArtist, req.ReleaseDate, req.FilenameFormat, req.TrackNumber, req.Position, req.SpotifyDiscNumber, req.UseAlbumTrackNumber)
-		expectedPath := filepath.Join(req.OutputDir, expectedFilename)
+	existingFiles := backend.ExistingSkip
+	if req.SkipExisting == backend.SkipExistingNone {
+		existingFiles = backend.ExistingOverwrite
+	}
 
-		if fileInfo, err := os.Stat(expectedPath); err == nil && fileInfo.Size() > 100*1024 {
+	// Check the library index first, it finds files under any name. Copies
+	// outside the output folder belong to another download and don't count.
+	if req.SkipExisting == backend.SkipExistingISRC {
+		if entry, err := backend.LookupLibrary(req.ISRC, req.SpotifyID, "SpotiFLAC"); err == nil && entry != nil && backend.IsWithinDir(req.OutputDir, entry.Path) {
 			return DownloadResponse{
 				Success:       true,
-				Message:       "File already exists",
-				File:          expectedPath,
+				Message:       "Already in library",
+				File:          entry.Path,
 				AlreadyExists: true,
 			}, nil
 		}
 	}
 
+	// Check if file already exists
+	if req.SkipExisting != backend.SkipExistingNone && req.TrackName != "" && req.ArtistName != "" {
+		expectedFilename := backend.BuildExpectedFilename(req.TrackName, req.ArtistName, req.AlbumName, req.AlbumArtist, req.ReleaseDate, req.FilenameFormat, req.TrackNumber, req.Position, req.SpotifyDiscNumber, req.UseAlbumTrackNumber)
+		expectedPath := filepath.Join(req.OutputDir, expectedFilename)
+
+		if fileInfo, err := os.Stat(expectedPath); err == nil && fileInfo.Size() > 100*1024 {
+			sameTrack := true
+			if req.SkipExisting == backend.SkipExistingISRC {
+				sameTrack = isSameTrack(expectedPath, req.ISRC, req.SpotifyID)
+			}
+
+			if sameTrack {
+				if req.SkipExisting == backend.SkipExistingISRC {
+					backend.IndexLibraryFile(backend.LibraryEntry{Path: expectedPath, ISRC: req.ISRC, SpotifyID: req.SpotifyID, Title: req.TrackName, Artist: req.ArtistName}, "SpotiFLAC")
+				}
+				return DownloadResponse{
+					Success:       true,
+					Message:       "File already exists",
+					File:          expectedPath,
+					AlreadyExists: true,
+				}, nil
+			}
+
+			// A different song with the same sanitized name, keep both
+			existingFiles = backend.ExistingRename
+		}
+	}
+
+	release := backend.ReleaseTags{
+		SpotifyAlbumID: req.SpotifyAlbumID,
+		Explicit:       req.Explicit,
+		Artists:        req.Artists,
+		AlbumArtists:   req.AlbumArtists,
+		Genres:         req.Genres,
+		Credits:        req.Credits,
+	}
+
 	// Download based on service
 	switch req.Service {
 	case "amazon":
 		downloader := backend.NewAmazonDownloader()
-		filename, err = downloader.DownloadBySpotifyID(req.SpotifyID, req.OutputDir, req.AudioFormat, req.FilenameFormat, req.TrackNumber, req.Position, req.TrackName, req.ArtistName, req.AlbumName, req.AlbumArtist, req.ReleaseDate, req.CoverURL, req.SpotifyTrackNumber, req.SpotifyDiscNumber, req.SpotifyTotalTracks, req.EmbedMaxQualityCover, req.SpotifyTotalDiscs, req.Copyright, req.Publisher, fmt.Sprintf("https://open.spotify.com/track/%s", req.SpotifyID))
+		downloader.ExistingFiles = existingFiles
+		downloader.Release = release
+		filename, err = downloader.DownloadBySpotifyID(ctx, req.SpotifyID, req.OutputDir, req.AudioFormat, req.FilenameFormat, req.TrackNumber, req.Position, req.TrackName, req.ArtistName, req.AlbumName, req.AlbumArtist, req.ReleaseDate, req.CoverURL, req.SpotifyTrackNumber, req.SpotifyDiscNumber, req.SpotifyTotalTracks, req.EmbedMaxQualityCover, req.SpotifyTotalDiscs, req.Copyright, req.Publisher, fmt.Sprintf("https://open.spotify.com/track/%s", req.SpotifyID))
 
 	case "tidal":
 		downloader := backend.NewTidalDownloader(req.ApiURL)
-		filename, err = downloader.Download(req.SpotifyID, req.OutputDir, req.AudioFormat, req.FilenameFormat, req.TrackNumber, req.Position, req.TrackName, req.ArtistName, req.AlbumName, req.AlbumArtist, req.ReleaseDate, req.UseAlbumTrackNumber, req.CoverURL, req.EmbedMaxQualityCover, req.SpotifyTrackNumber, req.SpotifyDiscNumber, req.SpotifyTotalTracks, req.SpotifyTotalDiscs, req.Copyright, req.Publisher, fmt.Sprintf("https://open.spotify.com/track/%s", req.SpotifyID))
+		downloader.ExistingFiles = existingFiles
+		downloader.Release = release
+		filename, err = downloader.Download(ctx, req.SpotifyID, req.OutputDir, req.AudioFormat, req.FilenameFormat, req.TrackNumber, req.Position, req.TrackName, req.ArtistName, req.AlbumName, req.AlbumArtist, req.ReleaseDate, req.UseAlbumTrackNumber, req.CoverURL, req.EmbedMaxQualityCover, req.SpotifyTrackNumber, req.SpotifyDiscNumber, req.SpotifyTotalTracks, req.SpotifyTotalDiscs, req.Copyright, req.Publisher, fmt.Sprintf("https://open.spotify.com/track/%s", req.SpotifyID))
 
 	case "qobuz":
 		downloader := backend.NewQobuzDownloader()
+		downloader.ExistingFiles = existingFiles
+		downloader.Release = release
 		quality := req.AudioFormat
 		if quality == "" {
 			quality = "6"
@@ -334,9 +519,9 @@ func downloadTrack(req DownloadRequest) (DownloadResponse, error) {
 		// Try to get ISRC from Deezer if not provided
 		if req.ISRC == "" && req.SpotifyID != "" {
 			client := backend.NewSongLinkClient()
-			deezerURL, err := client.GetDeezerURLFromSpotify(req.SpotifyID)
+			deezerURL, err := client.GetDeezerURLFromSpotify(ctx, req.SpotifyID)
 			if err == nil {
-				req.ISRC, _ = backend.GetDeezerISRC(deezerURL)
+				req.ISRC, _ = backend.GetDeezerISRC(ctx, deezerURL)
 			}
 		}
 
@@ -347,7 +532,7 @@ func downloadTrack(req DownloadRequest) (DownloadResponse, error) {
 			}, fmt.Errorf("ISRC is required for Qobuz")
 		}
 
-		filename, err = downloader.DownloadByISRC(req.ISRC, req.OutputDir, quality, req.FilenameFormat, req.TrackNumber, req.Position, req.TrackName, req.ArtistName, req.AlbumName, req.AlbumArtist, req.ReleaseDate, req.UseAlbumTrackNumber, req.CoverURL, req.EmbedMaxQualityCover, req.SpotifyTrackNumber, req.SpotifyDiscNumber, req.SpotifyTotalTracks, req.SpotifyTotalDiscs, req.Copyright, req.Publisher, fmt.Sprintf("https://open.spotify.com/track/%s", req.SpotifyID))
+		filename, err = downloader.DownloadByISRC(ctx, req.ISRC, req.OutputDir, quality, req.FilenameFormat, req.TrackNumber, req.Position, req.TrackName, req.ArtistName, req.AlbumName, req.AlbumArtist, req.ReleaseDate, req.UseAlbumTrackNumber, req.CoverURL, req.EmbedMaxQualityCover, req.SpotifyTrackNumber, req.SpotifyDiscNumber, req.SpotifyTotalTracks, req.SpotifyTotalDiscs, req.Copyright, req.Publisher, fmt.Sprintf("https://open.spotify.com/track/%s", req.SpotifyID))
 
 	default:
 		return DownloadResponse{
@@ -356,6 +541,10 @@ func downloadTrack(req DownloadRequest) (DownloadResponse, error) {
 		}, fmt.Errorf("unknown service: %s", req.Service)
 	}
 
+	if err == nil && !strings.HasPrefix(filename, "EXISTS:") {
+		err = backend.VerifyAudioFile(filename)
+	}
+
 	if err != nil {
 		// Cleanup partial file
 		if filename != "" && !strings.HasPrefix(filename, "EXISTS:") {
@@ -375,18 +564,30 @@ func downloadTrack(req DownloadRequest) (DownloadResponse, error) {
 		filename = strings.TrimPrefix(filename, "EXISTS:")
 	}
 
-	// Embed lyrics if requested
+	// Embed lyrics if requested. This runs inline so an exit can't cut off
+	// a half-written FLAC.
 	if !alreadyExists && req.SpotifyID != "" && req.EmbedLyrics && strings.HasSuffix(filename, ".flac") {
-		go func(filePath, spotifyID, trackName, artistName string) {
-			lyricsClient := backend.NewLyricsClient()
-			lyricsResp, _, err := lyricsClient.FetchLyricsAllSources(spotifyID, trackName, artistName, 0)
-			if err == nil && lyricsResp != nil && len(lyricsResp.Lines) > 0 {
-				lyrics := lyricsClient.ConvertToLRC(lyricsResp, trackName, artistName)
-				if lyrics != "" {
-					backend.EmbedLyricsOnly(filePath, lyrics)
-				}
+		lyricsClient := backend.NewLyricsClient()
+		lyricsResp, _, err := lyricsClient.FetchLyricsAllSources(ctx, req.SpotifyID, req.TrackName, req.ArtistName, 0)
+		if err == nil && lyricsResp != nil && len(lyricsResp.Lines) > 0 {
+			lyrics := lyricsClient.ConvertToLRC(lyricsResp, req.TrackName, req.ArtistName)
+			if lyrics != "" {
+				backend.EmbedLyricsOnly(filename, lyrics)
 			}
-		}(filename, req.SpotifyID, req.TrackName, req.ArtistName)
+		}
+	}
+
+	if !alreadyExists || req.SkipExisting == backend.SkipExistingISRC {
+		entry := backend.LibraryEntry{
+			Path:      filename,
+			ISRC:      req.ISRC,
+			SpotifyID: req.SpotifyID,
+			Title:     req.TrackName,
+			Artist:    req.ArtistName,
+		}
+		if err := backend.IndexLibraryFile(entry, "SpotiFLAC"); err != nil {
+			fmt.Printf("⚠️  Failed to update library index: %v\n", err)
+		}
 	}
 
 	message := "Download completed successfully"
@@ -394,20 +595,20 @@ func downloadTrack(req DownloadRequest) (DownloadResponse, error) {
 		message = "File already exists"
 	} else {
 		// Add to history
-		go func(fPath, track, artist, album, sID, cover string) {
-			item := backend.HistoryItem{
-				SpotifyID:   sID,
-				Title:       track,
-				Artists:     artist,
-				Album:       album,
-				CoverURL:    cover,
-				Quality:     "Unknown",
-				Format:      "FLAC",
-				Path:        fPath,
-				DurationStr: "--:--",
-			}
-			backend.AddHistoryItem(item, "SpotiFLAC")
-		}(filename, req.TrackName, req.ArtistName, req.AlbumName, req.SpotifyID, req.CoverURL)
+		item := backend.HistoryItem{
+			SpotifyID:   req.SpotifyID,
+			Title:       req.TrackName,
+			Artists:     req.ArtistName,
+			Album:       req.AlbumName,
+			CoverURL:    req.CoverURL,
+			Quality:     "Unknown",
+			Format:      "FLAC",
+			Path:        filename,
+			DurationStr: "--:--",
+		}
+		if err := backend.AddHistoryItem(item, "SpotiFLAC"); err != nil {
+			fmt.Printf("⚠️  Failed to save history: %v\n", err)
+		}
 	}
 
 	return DownloadResponse{
@@ -417,3 +618,19 @@ func downloadTrack(req DownloadRequest) (DownloadResponse, error) {
 		AlreadyExists: alreadyExists,
 	}, nil
 }
+
+// isSameTrack reports whether an existing file holds the requested track.
+// Files without identifying tags are assumed to match.
+func isSameTrack(path, isrc, spotifyID string) bool {
+	meta, err := backend.ReadAudioMetadata(path)
+	if err != nil || (meta.ISRC == "" && meta.SpotifyID == "") {
+		return true
+	}
+	if isrc != "" && strings.EqualFold(meta.ISRC, isrc) {
+		return true
+	}
+	if spotifyID != "" && meta.SpotifyID == spotifyID {
+		return true
+	}
+	return false
+}
@@ -35,6 +35,10 @@ var historyListCmd = &cobra.Command{
 			return fmt.Errorf("failed to load history: %w", err)
 		}
 
+		if machineOutput() {
+			return writeResult(newHistoryResult(items))
+		}
+
 		if len(items) == 0 {
 			fmt.Println("📭 No download history")
 			return nil
@@ -66,13 +70,10 @@ var historySearchCmd = &cobra.Command{
 			return fmt.Errorf("failed to load history: %w", err)
 		}
 
-		var matching []backend.HistoryItem
-		for _, item := range items {
-			if strings.Contains(strings.ToLower(item.Title), query) ||
-				strings.Contains(strings.ToLower(item.Artists), query) ||
-				strings.Contains(strings.ToLower(item.Album), query) {
-				matching = append(matching, item)
-			}
+		matching := filterHistory(items, query)
+
+		if machineOutput() {
+			return writeResult(newHistoryResult(matching))
 		}
 
 		if len(matching) == 0 {
@@ -142,6 +143,33 @@ var historyClearCmd = &cobra.Command{
 	},
 }
 
+// filterHistory returns items whose title, artists or album contain query
+func filterHistory(items []backend.HistoryItem, query string) []backend.HistoryItem {
+	query = strings.ToLower(query)
+
+	var matching []backend.HistoryItem
+	for _, item := range items {
+		if strings.Contains(strings.ToLower(item.Title), query) ||
+			strings.Contains(strings.ToLower(item.Artists), query) ||
+			strings.Contains(strings.ToLower(item.Album), query) {
+			matching = append(matching, item)
+		}
+	}
+	return matching
+}
+
+type historyResult struct {
+	Count int                   `json:"count"`
+	Items []backend.HistoryItem `json:"items"`
+}
+
+func newHistoryResult(items []backend.HistoryItem) historyResult {
+	if items == nil {
+		items = []backend.HistoryItem{}
+	}
+	return historyResult{Count: len(items), Items: items}
+}
+
 func init() {
 	historyCmd.AddCommand(historyListCmd)
 	historyCmd.AddCommand(historySearchCmd)
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"spotiflac/backend"
+)
+
+// hookConfig maps hook events to shell commands. The "hook" config key
+// applies to every event without its own hook-<event> entry.
+type hookConfig map[string]string
+
+var hookEvents = []string{backend.HookTrackComplete, backend.HookAlbumComplete, backend.HookFailure}
+
+// hookConfigKey returns the config key for an event, e.g. hook-on-failure
+func hookConfigKey(event string) string {
+	return "hook-" + strings.ReplaceAll(event, "_", "-")
+}
+
+func loadHooks() hookConfig {
+	config, err := loadConfig(getConfigPath())
+	if err != nil {
+		return nil
+	}
+
+	hooks := make(hookConfig)
+	global, _ := config["hook"].(string)
+	for _, event := range hookEvents {
+		if command, _ := config[hookConfigKey(event)].(string); command != "" {
+			hooks[event] = command
+		} else if global != "" {
+			hooks[event] = global
+		}
+	}
+	return hooks
+}
+
+// runHook runs the hook for payload.Event, if one is configured. A failing
+// hook is reported but never fails the download.
+func runHook(ctx context.Context, hooks hookConfig, payload backend.HookPayload) {
+	command := hooks[payload.Event]
+	if command == "" {
+		return
+	}
+	if err := backend.RunHook(ctx, command, payload); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
+
+// runTrackHook reports a finished track download. Interrupted downloads have
+// no hook.
+func runTrackHook(ctx context.Context, opts downloadOptions, spotifyID string, info *TrackInfo, resp DownloadResponse, err error) {
+	status := downloadOutcome(ctx, resp, err)
+	if status == backend.StatusInterrupted {
+		return
+	}
+
+	payload := backend.HookPayload{
+		Event:          backend.HookTrackComplete,
+		Status:         string(status),
+		File:           resp.File,
+		SpotifyID:      spotifyID,
+		Service:        opts.Service,
+		Quality:        opts.Quality,
+		CollectionType: opts.CollectionType,
+		CollectionName: opts.CollectionName,
+	}
+	if info != nil {
+		payload.ISRC = info.ISRC
+		payload.Title = info.Title
+		payload.Artist = info.Artist
+		payload.Album = info.Album
+	}
+	if err != nil {
+		payload.Event = backend.HookFailure
+		payload.Error = err.Error()
+		payload.ErrorKind = backend.ErrorKind(err)
+	}
+
+	runHook(ctx, opts.Hooks, payload)
+}
+
+// runAlbumHook reports a finished album, playlist or file batch
+func runAlbumHook(ctx context.Context, opts downloadOptions, summary batchSummary) {
+	if opts.CollectionType == "" || summary.Total == 0 || summary.Interrupted {
+		return
+	}
+
+	status := backend.StatusCompleted
+	if summary.Failed > 0 {
+		status = backend.StatusFailed
+	}
+	runHook(ctx, opts.Hooks, backend.HookPayload{
+		Event:          backend.HookAlbumComplete,
+		Status:         string(status),
+		Service:        opts.Service,
+		Quality:        opts.Quality,
+		CollectionType: opts.CollectionType,
+		CollectionName: opts.CollectionName,
+		Total:          summary.Total,
+		Completed:      summary.Completed,
+		Skipped:        summary.Skipped,
+		Failed:         summary.Failed,
+	})
+}
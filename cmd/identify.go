@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var identifyCmd = &cobra.Command{
+	Use:   "identify <dir|file...>",
+	Short: "Tag untagged files by matching their names on Spotify",
+	Long: `Identify FLAC, MP3 and M4A files that have no useful tags and tag them
+with the metadata and cover art of the matching Spotify track.
+
+The title, artist and track number are parsed from file names like
+"03 - Artist - Title", "Artist - 03 - Title", "03. Title" or "Artist - Title",
+with a missing artist taken from an "Artist - Album" folder or from
+Artist/Album folders. Existing TITLE and ARTIST tags are used instead when
+present. Candidates are scored on title, artist and duration; files without
+a confident, unambiguous match are left untouched and listed for review.
+Files that already have a Spotify ID or ISRC are skipped, use retag for those.
+
+Examples:
+  spotflac identify ~/Music/Unsorted
+  spotflac identify ~/Music/Unsorted --dry-run
+  spotflac identify "03 - Artist - Title.flac" --review review.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runIdentify,
+}
+
+var (
+	identifyDryRun        bool
+	identifyMinConfidence float64
+	identifyReviewPath    string
+)
+
+func init() {
+	identifyCmd.Flags().BoolVar(&identifyDryRun, "dry-run", false, "Only show the matches, don't write tags")
+	identifyCmd.Flags().Float64Var(&identifyMinConfidence, "min-confidence", backend.DefaultImportConfidence, "Minimum match score (0-1) to tag a file")
+	identifyCmd.Flags().StringVar(&identifyReviewPath, "review", "", "Write the files that need review, with their candidates, to a JSON file")
+}
+
+type identifyCommandResult struct {
+	Total   int                       `json:"total"`
+	Matched int                       `json:"matched"`
+	Review  int                       `json:"review"`
+	Skipped int                       `json:"skipped"`
+	Failed  int                       `json:"failed"`
+	Applied bool                      `json:"applied"`
+	Files   []*backend.IdentifyResult `json:"files"`
+}
+
+func runIdentify(cmd *cobra.Command, args []string) error {
+	var files []string
+	for _, arg := range args {
+		path := backend.NormalizePath(arg)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		audioFiles, err := backend.ListAudioFiles(path)
+		if err != nil {
+			return err
+		}
+		for _, file := range audioFiles {
+			files = append(files, file.Path)
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no audio files found")
+	}
+
+	identifier := &backend.Identifier{
+		MinConfidence:   identifyMinConfidence,
+		MaxQualityCover: downloadOptionsFromConfig().EmbedMaxQualityCover,
+		DryRun:          identifyDryRun,
+		AppName:         "SpotiFLAC",
+	}
+
+	result := identifyCommandResult{Total: len(files), Applied: !identifyDryRun}
+	var review []*backend.IdentifyResult
+	fmt.Printf("🔍 Identifying %d files...\n", len(files))
+	for _, file := range files {
+		if err := cmd.Context().Err(); err != nil {
+			return err
+		}
+		identified := identifier.Identify(cmd.Context(), file)
+		result.Files = append(result.Files, identified)
+		printIdentifyResult(identified)
+		switch identified.Status {
+		case backend.IdentifyMatched:
+			result.Matched++
+		case backend.IdentifyReview:
+			result.Review++
+			review = append(review, identified)
+		case backend.IdentifySkipped:
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+	}
+
+	if identifyReviewPath != "" && len(review) > 0 {
+		data, err := json.MarshalIndent(review, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode review list: %w", err)
+		}
+		if err := os.WriteFile(identifyReviewPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write review list: %w", err)
+		}
+		fmt.Printf("📁 Review list saved to: %s\n", identifyReviewPath)
+	}
+
+	if machineOutput() {
+		if err := writeResult(result); err != nil {
+			return err
+		}
+	}
+	verb := "tagged"
+	if identifyDryRun {
+		verb = "matched"
+	}
+	fmt.Printf("\n📊 Summary: %d %s, %d need review, %d skipped, %d failed\n", result.Matched, verb, result.Review, result.Skipped, result.Failed)
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d files could not be identified", result.Failed, result.Total)
+	}
+	return nil
+}
+
+func printIdentifyResult(result *backend.IdentifyResult) {
+	name := filepath.Base(result.File)
+	switch result.Status {
+	case backend.IdentifyMatched:
+		fmt.Printf("✅ %s → %s - %s (%.2f)\n", name, result.Match.Artists, result.Match.Name, result.Confidence)
+	case backend.IdentifyReview:
+		fmt.Printf("⚠️  %s: %s\n", name, result.Reason)
+		for _, candidate := range result.Candidates {
+			fmt.Printf("       ? %s - %s (%.2f)  %s\n", candidate.Artists, candidate.Name, candidate.Score, candidate.ExternalURL)
+		}
+	case backend.IdentifySkipped:
+		fmt.Printf("⏭️  %s: %s\n", name, result.Reason)
+	default:
+		fmt.Printf("❌ %s: %s\n", name, result.Reason)
+	}
+}
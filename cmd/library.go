@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var libraryCmd = &cobra.Command{
+	Use:   "library",
+	Short: "Manage the library index used for duplicate detection",
+	Long: `The library index maps ISRCs and Spotify track IDs to files on disk.
+
+Downloads are added automatically. With --skip-existing=isrc (the default) a
+track that is already indexed is skipped no matter which folder or filename
+it was saved under. Use "library scan" to index files downloaded before the
+index existed; only files with ISRC or SPOTIFY_TRACK_ID tags can be indexed.
+
+Examples:
+  spotflac library scan ~/Music
+  spotflac library stats`,
+}
+
+var libraryScanCmd = &cobra.Command{
+	Use:   "scan <dir>",
+	Short: "Index audio files by their embedded ISRC and Spotify ID tags",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := backend.NormalizePath(args[0])
+
+		fmt.Printf("🔍 Scanning %s...\n", dir)
+		indexed, untagged, err := backend.ScanLibrary(dir, "SpotiFLAC")
+		if err != nil {
+			return fmt.Errorf("failed to scan library: %w", err)
+		}
+
+		fmt.Printf("✅ Indexed %d files (%d without ISRC or Spotify ID tags)\n", indexed, untagged)
+		return nil
+	},
+}
+
+var libraryStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show library index size",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		count, err := backend.GetLibraryStats("SpotiFLAC")
+		if err != nil {
+			return fmt.Errorf("failed to read library index: %w", err)
+		}
+
+		fmt.Printf("📋 %d files in the library index\n", count)
+		return nil
+	},
+}
+
+func init() {
+	libraryCmd.AddCommand(libraryScanCmd)
+	libraryCmd.AddCommand(libraryStatsCmd)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"spotiflac/backend"
+)
+
+var (
+	logVerbose int
+	logQuiet   bool
+	logFile    string
+
+	logFileHandle *os.File
+)
+
+// setupLogging routes backend logs to stderr at the level chosen with
+// --verbose/--quiet, and additionally to --log-file at debug level. Stdout
+// is left to command output.
+func setupLogging() error {
+	if logQuiet && logVerbose > 0 {
+		return fmt.Errorf("--quiet and --verbose cannot be used together")
+	}
+
+	level := slog.LevelWarn
+	switch {
+	case logQuiet:
+		level = slog.LevelError
+	case logVerbose >= 2:
+		level = slog.LevelDebug
+	case logVerbose == 1:
+		level = slog.LevelInfo
+	}
+
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	if logFile != "" {
+		file, err := os.OpenFile(backend.NormalizePath(logFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		logFileHandle = file
+		handler = teeHandler{handler, slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug})}
+	}
+
+	backend.SetLogHandler(handler)
+	slog.SetDefault(slog.New(handler))
+
+	if logQuiet {
+		backend.SetProgressOutput(io.Discard)
+	}
+	return nil
+}
+
+func closeLogging() {
+	if logFileHandle != nil {
+		logFileHandle.Close()
+		logFileHandle = nil
+	}
+}
+
+// teeHandler sends each record to every handler that accepts its level.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, record.Level) {
+			errs = append(errs, h.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := make(teeHandler, len(t))
+	for i, h := range t {
+		next[i] = h.WithAttrs(attrs)
+	}
+	return next
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	next := make(teeHandler, len(t))
+	for i, h := range t {
+		next[i] = h.WithGroup(name)
+	}
+	return next
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var metricsAddr string
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	backend.WriteMetrics(w, "SpotiFLAC")
+}
+
+// addMetricsFlag adds --metrics-addr to a long-running batch command
+func addMetricsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address while running, e.g. 127.0.0.1:9480")
+}
+
+// startMetricsServer serves /metrics on metricsAddr until ctx is done
+func startMetricsServer(ctx context.Context) error {
+	if metricsAddr == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", metricsAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on --metrics-addr: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go server.Serve(listener)
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	fmt.Printf("📈 Metrics on http://%s/metrics\n", listener.Addr())
+	return nil
+}
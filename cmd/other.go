@@ -19,7 +19,7 @@ Supports conversion to MP3, M4A, OGG, and other formats.
 Examples:
   spotflac convert song.flac --format mp3
   spotflac convert song.flac --format mp3 --bitrate 320k
-  spotflac convert file1.flac file2.flac --format ogg --output ~/converted`,
+  spotflac convert file1.flac file2.flac --format ogg -o ~/converted`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runConvert,
 }
@@ -35,7 +35,7 @@ func init() {
 	convertCmd.Flags().StringVarP(&convertFormat, "format", "f", "mp3", "Output format: mp3, m4a, ogg, opus, flac")
 	convertCmd.Flags().StringVarP(&convertBitrate, "bitrate", "b", "320k", "Bitrate: 128k, 192k, 256k, 320k, etc.")
 	convertCmd.Flags().StringVar(&convertCodec, "codec", "", "Codec override (optional)")
-	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", "", "Output directory (default: same as input)")
+	convertCmd.Flags().StringVarP(&convertOutput, "output-dir", "o", "", "Output directory (default: same as input)")
 }
 
 func runConvert(cmd *cobra.Command, args []string) error {
@@ -62,11 +62,21 @@ func runConvert(cmd *cobra.Command, args []string) error {
 		Codec:        convertCodec,
 	}
 
-	results, err := backend.ConvertAudio(req)
+	results, err := backend.ConvertAudio(cmd.Context(), req)
 	if err != nil {
 		return fmt.Errorf("conversion failed: %w", err)
 	}
 
+	if machineOutput() {
+		converted := 0
+		for _, result := range results {
+			if result.Success {
+				converted++
+			}
+		}
+		return writeResult(convertResult{Format: convertFormat, Total: len(inputFiles), Converted: converted, Results: results})
+	}
+
 	successCount := 0
 	for i, result := range results {
 		if result.Success {
@@ -81,6 +91,20 @@ func runConvert(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+type convertResult struct {
+	Format    string                       `json:"format"`
+	Total     int                          `json:"total"`
+	Converted int                          `json:"converted"`
+	Results   []backend.ConvertAudioResult `json:"results"`
+}
+
+type lyricsResult struct {
+	SpotifyID string               `json:"spotify_id"`
+	Source    string               `json:"source"`
+	SyncType  string               `json:"sync_type"`
+	Lines     []backend.LyricsLine `json:"lines"`
+}
+
 var lyricsCmd = &cobra.Command{
 	Use:   "lyrics",
 	Short: "Download and manage lyrics",
@@ -97,7 +121,7 @@ var lyricsDownloadCmd = &cobra.Command{
 		fmt.Printf("📥 Downloading lyrics for: %s\n", spotifyID)
 
 		client := backend.NewLyricsClient()
-		lyricsResp, source, err := client.FetchLyricsAllSources(spotifyID, "", "", 0)
+		lyricsResp, source, err := client.FetchLyricsAllSources(cmd.Context(), spotifyID, "", "", 0)
 		if err != nil {
 			return fmt.Errorf("failed to fetch lyrics: %w", err)
 		}
@@ -106,6 +130,10 @@ var lyricsDownloadCmd = &cobra.Command{
 			return fmt.Errorf("no lyrics found")
 		}
 
+		if machineOutput() {
+			return writeResult(lyricsResult{SpotifyID: spotifyID, Source: source, SyncType: lyricsResp.SyncType, Lines: lyricsResp.Lines})
+		}
+
 		fmt.Printf("✅ Found %d lines from: %s\n", len(lyricsResp.Lines), source)
 		fmt.Printf("Sync type: %s\n\n", lyricsResp.SyncType)
 
@@ -139,7 +167,7 @@ var coverDownloadCmd = &cobra.Command{
 		}
 
 		client := backend.NewCoverClient()
-		resp, err := client.DownloadCover(req)
+		resp, err := client.DownloadCover(cmd.Context(), req)
 		if err != nil {
 			return fmt.Errorf("download failed: %w", err)
 		}
@@ -148,6 +176,10 @@ var coverDownloadCmd = &cobra.Command{
 			return fmt.Errorf("download failed: %s", resp.Error)
 		}
 
+		if machineOutput() {
+			return writeResult(resp)
+		}
+
 		fmt.Printf("✅ %s\n", resp.Message)
 		if resp.File != "" {
 			fmt.Printf("📁 Saved to: %s\n", resp.File)
@@ -188,35 +220,39 @@ func runAvailability(cmd *cobra.Command, args []string) error {
 	fmt.Printf("🔍 Checking availability for: %s\n", spotifyID)
 
 	client := backend.NewSongLinkClient()
-	availability, err := client.CheckTrackAvailability(spotifyID, availabilityISRC)
+	availability, err := client.CheckTrackAvailability(cmd.Context(), spotifyID, availabilityISRC)
 	if err != nil {
 		return fmt.Errorf("failed to check availability: %w", err)
 	}
 
+	if machineOutput() {
+		return writeResult(availability)
+	}
+
 	printAvailability(availability)
 	return nil
 }
 
-func printAvailability(data interface{}) {
-	if mapData, ok := data.(map[string]interface{}); ok {
-		fmt.Println("\n📡 Streaming Service Availability")
-		fmt.Println("════════════════════════════════════════════")
-
-		services := []string{"spotify", "tidal", "qobuz", "amazonmusic", "applemusic", "deezer", "youtube"}
-
-		for _, service := range services {
-			available := false
-			if val, exists := mapData[service]; exists && val != nil {
-				available = true
-			}
-
-			status := "❌ Not available"
-			if available {
-				status = "✅ Available"
-			}
+func printAvailability(availability *backend.TrackAvailability) {
+	fmt.Println("\n📡 Streaming Service Availability")
+	fmt.Println("════════════════════════════════════════════")
+
+	services := []struct {
+		name      string
+		available bool
+	}{
+		{"tidal", availability.Tidal},
+		{"qobuz", availability.Qobuz},
+		{"amazonmusic", availability.Amazon},
+	}
 
-			fmt.Printf("%-15s %s\n", formatServiceName(service), status)
+	for _, service := range services {
+		status := "❌ Not available"
+		if service.available {
+			status = "✅ Available"
 		}
+
+		fmt.Printf("%-15s %s\n", formatServiceName(service.name), status)
 	}
 }
 
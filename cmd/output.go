@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputText   = "text"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+)
+
+var (
+	outputMode string
+
+	// resultOut is the real stdout. In json/ndjson mode os.Stdout is pointed
+	// at stderr so the human-readable messages don't mix with results.
+	resultOut io.Writer = os.Stdout
+)
+
+func isValidOutputMode(mode string) bool {
+	valid := map[string]bool{outputText: true, outputJSON: true, outputNDJSON: true}
+	return valid[mode]
+}
+
+func setupOutput(cmd *cobra.Command) error {
+	if !isValidOutputMode(outputMode) {
+		// --output used to be the directory flag of download and convert, so
+		// a value that isn't a format still works there
+		dirFlag := cmd.Flags().Lookup("output-dir")
+		if dirFlag == nil {
+			return fmt.Errorf("invalid --output value: %s (use text, json or ndjson)", outputMode)
+		}
+		if !dirFlag.Changed {
+			if err := dirFlag.Value.Set(outputMode); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(os.Stderr, "⚠️  --output <dir> is deprecated, use -o/--output-dir")
+		outputMode = outputText
+	}
+	if outputMode == outputText {
+		return nil
+	}
+
+	resultOut = os.Stdout
+	os.Stdout = os.Stderr
+
+	if outputMode == outputNDJSON {
+		backend.SetDownloadEventHandler(newEventWriter(resultOut).handle)
+	}
+	return nil
+}
+
+// machineOutput reports whether results should be written as JSON
+func machineOutput() bool {
+	return outputMode == outputJSON || outputMode == outputNDJSON
+}
+
+// writeResult writes a command result: indented in json mode, on a single
+// line in ndjson mode.
+func writeResult(v any) error {
+	var data []byte
+	var err error
+	if outputMode == outputJSON {
+		data, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	_, err = fmt.Fprintln(resultOut, string(data))
+	return err
+}
+
+// writeErrorResult reports a failed command on stdout so scripts reading
+// JSON always get a parseable final line.
+func writeErrorResult(err error) {
+	result := errorResult{Error: err.Error(), Kind: backend.ErrorKind(err)}
+	if outputMode == outputNDJSON {
+		writeResult(struct {
+			Event string `json:"event"`
+			errorResult
+		}{"error", result})
+		return
+	}
+	writeResult(result)
+}
+
+type errorResult struct {
+	Error string `json:"error"`
+	Kind  string `json:"kind"`
+}
+
+// downloadEvent is one NDJSON line: the event name plus the DownloadItem
+// fields at the time of the event.
+type downloadEvent struct {
+	Event string `json:"event"`
+	Time  int64  `json:"time"`
+	backend.DownloadItem
+}
+
+type eventWriter struct {
+	mu           sync.Mutex
+	out          io.Writer
+	lastProgress map[string]time.Time
+}
+
+// Progress events are rate limited per item
+const progressEventInterval = 500 * time.Millisecond
+
+func newEventWriter(out io.Writer) *eventWriter {
+	return &eventWriter{out: out, lastProgress: make(map[string]time.Time)}
+}
+
+func (w *eventWriter) handle(event string, item backend.DownloadItem) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	if event == backend.EventProgress {
+		if now.Sub(w.lastProgress[item.ID]) < progressEventInterval {
+			return
+		}
+		w.lastProgress[item.ID] = now
+	}
+
+	data, err := json.Marshal(downloadEvent{Event: event, Time: now.Unix(), DownloadItem: item})
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(w.out, string(data))
+}
+
+// downloadResult is the JSON schema for one downloaded track
+type downloadResult struct {
+	SpotifyID     string `json:"spotify_id"`
+	Success       bool   `json:"success"`
+	File          string `json:"file,omitempty"`
+	AlreadyExists bool   `json:"already_exists"`
+	Message       string `json:"message,omitempty"`
+	Error         string `json:"error,omitempty"`
+	ErrorKind     string `json:"error_kind,omitempty"`
+}
+
+func newDownloadResult(spotifyID string, resp DownloadResponse, err error) downloadResult {
+	result := downloadResult{
+		SpotifyID:     spotifyID,
+		Success:       err == nil,
+		File:          resp.File,
+		AlreadyExists: resp.AlreadyExists,
+		Message:       resp.Message,
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorKind = backend.ErrorKind(err)
+	}
+	return result
+}
+
+// batchResult is the JSON schema for album, playlist and file downloads
+type batchResult struct {
+	Name        string           `json:"name,omitempty"`
+	Type        string           `json:"type,omitempty"`
+	Total       int              `json:"total"`
+	Completed   int              `json:"completed"`
+	Skipped     int              `json:"skipped"`
+	Failed      int              `json:"failed"`
+	Interrupted bool             `json:"interrupted"`
+	Tracks      []downloadResult `json:"tracks"`
+	Playlists   []string         `json:"playlists,omitempty"`
+}
+
+func newBatchResult(summary batchSummary, tracks []downloadResult) batchResult {
+	if tracks == nil {
+		tracks = []downloadResult{}
+	}
+	return batchResult{
+		Total:       summary.Total,
+		Completed:   summary.Completed,
+		Skipped:     summary.Skipped,
+		Failed:      summary.Failed,
+		Interrupted: summary.Interrupted,
+		Tracks:      tracks,
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSetupOutputDirectoryAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		dirFlag bool
+		dirSet  string
+		wantDir string
+		wantErr bool
+	}{
+		{name: "format", output: outputText, dirFlag: true},
+		{name: "directory alias", output: "/music", dirFlag: true, wantDir: "/music"},
+		{name: "output-dir wins", output: "/music", dirFlag: true, dirSet: "/other", wantDir: "/other"},
+		{name: "no directory flag", output: "/music", wantErr: true},
+	}
+
+	defer func(mode string) { outputMode = mode }(outputMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dir string
+			cmd := &cobra.Command{Use: "test"}
+			if tt.dirFlag {
+				cmd.Flags().StringVarP(&dir, "output-dir", "o", "", "")
+			}
+			if tt.dirSet != "" {
+				cmd.Flags().Set("output-dir", tt.dirSet)
+			}
+			outputMode = tt.output
+
+			err := setupOutput(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setupOutput() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if outputMode != outputText {
+				t.Errorf("outputMode = %q, want text", outputMode)
+			}
+			if dir != tt.wantDir {
+				t.Errorf("output-dir = %q, want %q", dir, tt.wantDir)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var playlistCmd = &cobra.Command{
+	Use:   "playlist",
+	Short: "Create playlist files",
+	Long: `Create M3U8 or XSPF playlist files from download history or a folder.
+
+The format is chosen from the output file extension (.m3u8, .m3u or .xspf).
+Paths are written relative to the playlist file.
+
+Examples:
+  spotflac playlist export ~/Music/recent.m3u8 --from-history --limit 50
+  spotflac playlist export ~/Music/Album/album.xspf --from-dir ~/Music/Album`,
+}
+
+var playlistExportCmd = &cobra.Command{
+	Use:   "export <output-file>",
+	Short: "Build a playlist file from history or a folder scan",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputPath := backend.NormalizePath(args[0])
+
+		if (playlistFromDir == "") == !playlistFromHistory {
+			return fmt.Errorf("specify exactly one of --from-history or --from-dir")
+		}
+
+		var entries []backend.PlaylistFileEntry
+		if playlistFromDir != "" {
+			scanned, err := backend.PlaylistEntriesFromDir(backend.NormalizePath(playlistFromDir))
+			if err != nil {
+				return fmt.Errorf("failed to scan folder: %w", err)
+			}
+			entries = scanned
+		} else {
+			items, err := backend.GetHistoryItems("SpotiFLAC")
+			if err != nil {
+				return fmt.Errorf("failed to load history: %w", err)
+			}
+
+			if playlistSearch != "" {
+				query := strings.ToLower(playlistSearch)
+				var matching []backend.HistoryItem
+				for _, item := range items {
+					if strings.Contains(strings.ToLower(item.Title), query) ||
+						strings.Contains(strings.ToLower(item.Artists), query) ||
+						strings.Contains(strings.ToLower(item.Album), query) {
+						matching = append(matching, item)
+					}
+				}
+				items = matching
+			}
+			if playlistLimit > 0 && len(items) > playlistLimit {
+				items = items[:playlistLimit]
+			}
+
+			entries = backend.PlaylistEntriesFromHistory(items)
+		}
+
+		if len(entries) == 0 {
+			return fmt.Errorf("no audio files found")
+		}
+
+		title := playlistTitle
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+		}
+
+		if err := backend.WritePlaylistFile(outputPath, title, entries); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Wrote %d tracks to: %s\n", len(entries), outputPath)
+		return nil
+	},
+}
+
+var (
+	playlistFromDir     string
+	playlistFromHistory bool
+	playlistSearch      string
+	playlistLimit       int
+	playlistTitle       string
+)
+
+func init() {
+	playlistExportCmd.Flags().StringVar(&playlistFromDir, "from-dir", "", "Scan a folder for audio files")
+	playlistExportCmd.Flags().BoolVar(&playlistFromHistory, "from-history", false, "Use downloaded tracks from history")
+	playlistExportCmd.Flags().StringVar(&playlistSearch, "search", "", "Only include history items matching this text")
+	playlistExportCmd.Flags().IntVar(&playlistLimit, "limit", 0, "Only include the most recent N history items")
+	playlistExportCmd.Flags().StringVar(&playlistTitle, "title", "", "Playlist title (default: file name)")
+
+	playlistCmd.AddCommand(playlistExportCmd)
+}
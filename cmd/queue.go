@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Manage the pending download queue",
+	Long: `Collect tracks into a persistent queue and download them later.
+
+The queue uses the saved configuration (spotflac config) for download options.
+
+Examples:
+  spotflac queue import playlist.csv
+  spotflac queue import tracks.txt --min-confidence 0.9 --report report.json
+  spotflac queue list
+  spotflac queue run
+  spotflac queue clear --finished`,
+}
+
+var queueImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Add tracks from a text or CSV file to the queue",
+	Long: `Add tracks from a file to the queue.
+
+Accepted formats:
+  - Plain text, one Spotify URL, URI or track ID per line
+  - "Artist - Title" lines, matched through Spotify search
+  - CSV with a track URI or ISRC column (Exportify format)
+
+Lines starting with # are ignored.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := importFile(cmd.Context(), args[0], queueMinConfidence, queueReportPath)
+		if err != nil {
+			return err
+		}
+
+		var pending []backend.PendingTrack
+		for _, track := range report.Tracks() {
+			pending = append(pending, backend.PendingTrack{
+				SpotifyID: track.SpotifyID,
+				Title:     track.Name,
+				Artists:   track.Artists,
+				Album:     track.Album,
+				Source:    args[0],
+			})
+		}
+
+		added, err := backend.AddPendingTracks(pending, "SpotiFLAC")
+		if err != nil {
+			return fmt.Errorf("failed to update queue: %w", err)
+		}
+
+		fmt.Printf("✅ Added %d tracks to the queue (%d already queued)\n", added, len(pending)-added)
+		return nil
+	},
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show queued tracks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tracks, err := backend.GetPendingTracks("SpotiFLAC")
+		if err != nil {
+			return fmt.Errorf("failed to load queue: %w", err)
+		}
+
+		if len(tracks) == 0 {
+			fmt.Println("📭 Queue is empty")
+			return nil
+		}
+
+		fmt.Printf("📋 Queue (%d items):\n\n", len(tracks))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Status\tSpotify ID\tTitle\tArtist")
+		fmt.Fprintln(w, "─────────────────────────────────────────────────────")
+
+		for _, track := range tracks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", track.Status, track.SpotifyID, track.Title, track.Artists)
+		}
+		w.Flush()
+
+		return nil
+	},
+}
+
+var queueRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Download all queued tracks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPendingQueue(cmd.Context(), queueRetryFailed)
+	},
+}
+
+var queueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove tracks from the queue",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := backend.ClearPendingTracks(queueClearFinished, "SpotiFLAC")
+		if err != nil {
+			return fmt.Errorf("failed to clear queue: %w", err)
+		}
+
+		fmt.Printf("✅ Removed %d items from the queue\n", removed)
+		return nil
+	},
+}
+
+var (
+	queueMinConfidence float64
+	queueReportPath    string
+	queueRetryFailed   bool
+	queueClearFinished bool
+)
+
+func init() {
+	queueImportCmd.Flags().Float64Var(&queueMinConfidence, "min-confidence", backend.DefaultImportConfidence, "Minimum search match score (0-1) for lines without a Spotify URI")
+	queueImportCmd.Flags().StringVar(&queueReportPath, "report", "", "Write the import report to a JSON file")
+	queueRunCmd.Flags().BoolVar(&queueRetryFailed, "retry-failed", false, "Also retry tracks that failed previously")
+	addMetricsFlag(queueRunCmd)
+	queueClearCmd.Flags().BoolVar(&queueClearFinished, "finished", false, "Only remove completed and skipped tracks")
+
+	queueCmd.AddCommand(queueImportCmd)
+	queueCmd.AddCommand(queueListCmd)
+	queueCmd.AddCommand(queueRunCmd)
+	queueCmd.AddCommand(queueClearCmd)
+}
+
+func runPendingQueue(ctx context.Context, retryFailed bool) error {
+	tracks, err := backend.GetPendingTracks("SpotiFLAC")
+	if err != nil {
+		return fmt.Errorf("failed to load queue: %w", err)
+	}
+
+	pending := make(map[string]backend.PendingTrack)
+	var batch []backend.ImportTrack
+	for _, track := range tracks {
+		if track.Status == backend.StatusQueued || track.Status == backend.StatusInterrupted || (retryFailed && track.Status == backend.StatusFailed) {
+			pending[track.SpotifyID] = track
+			batch = append(batch, backend.ImportTrack{SpotifyID: track.SpotifyID, Name: track.Title, Artists: track.Artists, Album: track.Album})
+		}
+	}
+
+	if len(batch) == 0 {
+		fmt.Println("📭 Nothing to download")
+		return nil
+	}
+
+	opts, err := downloadOptionsFromConfig().prepare()
+	if err != nil {
+		return err
+	}
+
+	summary := runBatch(ctx, batch, opts, func(track backend.ImportTrack, resp DownloadResponse, err error) {
+		backend.UpdatePendingTrack(pendingResult(ctx, pending[track.SpotifyID], resp, err), "SpotiFLAC")
+	})
+	printBatchSummary(summary)
+	sendWebhook(ctx, batchWebhookPayload(backend.WebhookBatchComplete, "queue", "queue", summary))
+
+	if summary.Interrupted {
+		return ctx.Err()
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", summary.Failed, summary.Total)
+	}
+	return nil
+}
+
+// pendingResult returns item updated with the outcome of its download
+func pendingResult(ctx context.Context, item backend.PendingTrack, resp DownloadResponse, err error) backend.PendingTrack {
+	switch {
+	case err != nil && ctx.Err() != nil:
+		item.Status = backend.StatusInterrupted
+	case err != nil:
+		item.Status = backend.StatusFailed
+		item.Error = err.Error()
+	case resp.AlreadyExists:
+		item.Status = backend.StatusSkipped
+		item.Path = resp.File
+	default:
+		item.Status = backend.StatusCompleted
+		item.Path = resp.File
+	}
+	return item
+}
+
+func importFile(ctx context.Context, path string, minConfidence float64, reportPath string) (*backend.ImportReport, error) {
+	entries, err := backend.ParseImportFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries found in %s", path)
+	}
+
+	fmt.Printf("🔍 Resolving %d entries from %s...\n", len(entries), path)
+	report := backend.ResolveImportEntries(ctx, entries, minConfidence)
+	report.Source = path
+
+	printImportReport(report)
+
+	if reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode report: %w", err)
+		}
+		if err := os.WriteFile(reportPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Printf("📁 Report saved to: %s\n", reportPath)
+	}
+
+	return report, nil
+}
+
+func printImportReport(report *backend.ImportReport) {
+	fmt.Printf("\n📋 Import report: %d resolved, %d ambiguous, %d failed\n",
+		report.Count(backend.ImportResolved), report.Count(backend.ImportAmbiguous), report.Count(backend.ImportFailed))
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case backend.ImportResolved:
+			if len(result.Tracks) == 1 && result.Tracks[0].Name != "" {
+				fmt.Printf("  ✅ line %d: %s → %s - %s (%.2f)\n", result.Entry.Line, result.Entry.Raw, result.Tracks[0].Artists, result.Tracks[0].Name, result.Confidence)
+			} else {
+				fmt.Printf("  ✅ line %d: %s → %d track(s)\n", result.Entry.Line, result.Entry.Raw, len(result.Tracks))
+			}
+		case backend.ImportAmbiguous:
+			fmt.Printf("  ⚠️  line %d: %s (best %.2f)\n", result.Entry.Line, result.Entry.Raw, result.Confidence)
+			for _, candidate := range result.Candidates {
+				fmt.Printf("       ? %s - %s  %s\n", candidate.Artists, candidate.Name, candidate.ExternalURL)
+			}
+		case backend.ImportFailed:
+			fmt.Printf("  ❌ line %d: %s (%s)\n", result.Entry.Line, result.Entry.Raw, result.Error)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var replayGainCmd = &cobra.Command{
+	Use:   "replaygain <dir|file...>",
+	Short: "Measure loudness and write ReplayGain tags",
+	Long: `Measure the loudness of FLAC files (ITU-R BS.1770, no ffmpeg needed) and
+write REPLAYGAIN_TRACK_GAIN/PEAK tags.
+
+Each directory is treated as one album and also gets REPLAYGAIN_ALBUM_GAIN/PEAK,
+measured over all of its FLAC files. Files named directly only get track gain.
+
+Examples:
+  spotflac replaygain ~/Music/Album
+  spotflac replaygain ~/Music --recursive
+  spotflac replaygain song.flac`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runReplayGain,
+}
+
+var (
+	replayGainTrackOnly bool
+	replayGainRecursive bool
+)
+
+func init() {
+	replayGainCmd.Flags().BoolVar(&replayGainTrackOnly, "track-only", false, "Only write track gain, even for directories")
+	replayGainCmd.Flags().BoolVarP(&replayGainRecursive, "recursive", "r", false, "Treat every subdirectory as an album of its own")
+}
+
+type replayGainCommandResult struct {
+	Total   int                        `json:"total"`
+	Failed  int                        `json:"failed"`
+	Results []backend.ReplayGainResult `json:"results"`
+}
+
+func runReplayGain(cmd *cobra.Command, args []string) error {
+	var files []string
+	albums := make(map[string][]string)
+	for _, arg := range args {
+		path := backend.NormalizePath(arg)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		if err := collectFLACFiles(path, replayGainRecursive, albums); err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	if len(files) == 0 && len(albums) == 0 {
+		return fmt.Errorf("no FLAC files found")
+	}
+
+	var all []backend.ReplayGainResult
+	dirs := make([]string, 0, len(albums))
+	for dir := range albums {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		fmt.Printf("\n💿 %s (%d tracks)\n", dir, len(albums[dir]))
+		results := backend.ApplyReplayGain(albums[dir], !replayGainTrackOnly)
+		for _, result := range results {
+			printReplayGainResult(result)
+		}
+		printAlbumGain(results)
+		all = append(all, results...)
+	}
+	if len(files) > 0 {
+		results := backend.ApplyReplayGain(files, false)
+		for _, result := range results {
+			printReplayGainResult(result)
+		}
+		all = append(all, results...)
+	}
+
+	failed := 0
+	for _, result := range all {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if machineOutput() {
+		if err := writeResult(replayGainCommandResult{Total: len(all), Failed: failed, Results: all}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\n📊 Summary: %d/%d files tagged\n", len(all)-failed, len(all))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be tagged", failed, len(all))
+	}
+	return nil
+}
+
+// collectFLACFiles adds the FLAC files in dir to albums, keyed by the
+// directory they are in
+func collectFLACFiles(dir string, recursive bool, albums map[string][]string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".flac") {
+			parent := filepath.Dir(path)
+			albums[parent] = append(albums[parent], path)
+		}
+		return nil
+	})
+}
+
+func printReplayGainResult(result backend.ReplayGainResult) {
+	name := filepath.Base(result.File)
+	switch {
+	case result.Error != "":
+		fmt.Printf("❌ %s: %s\n", name, result.Error)
+	case result.Loudness == nil:
+		fmt.Printf("🔊 %s: silent, no gain\n", name)
+	default:
+		fmt.Printf("🔊 %s: %.2f dB (%.1f LUFS, peak %.6f)\n", name, result.ReplayGain.TrackGain, *result.Loudness, result.ReplayGain.TrackPeak)
+	}
+}
+
+func printAlbumGain(results []backend.ReplayGainResult) {
+	for _, result := range results {
+		if rg := result.ReplayGain; rg != nil && rg.HasAlbum {
+			fmt.Printf("💿 Album gain: %.2f dB (peak %.6f)\n", rg.AlbumGain, rg.AlbumPeak)
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var retagCmd = &cobra.Command{
+	Use:   "retag <dir|file...>",
+	Short: "Refresh the tags of existing files from Spotify",
+	Long: `Update the tags and cover art of FLAC, MP3 and M4A files with current
+Spotify metadata.
+
+Each file is identified by its SPOTIFY_TRACK_ID tag, then its ISRC, and
+otherwise by searching for its title and artist, checked against its
+duration. A file whose ISRC only turns up tracks that don't match its title
+is searched for instead. The changes are shown first and only written after confirmation.
+Only fields Spotify has values for are changed; everything else is kept.
+With tag-merge set to fill-missing only missing fields are added.
+
+Examples:
+  spotflac retag ~/Music/Album
+  spotflac retag ~/Music --dry-run
+  spotflac retag ~/Music --yes --no-cover`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRetag,
+}
+
+var (
+	retagYes           bool
+	retagDryRun        bool
+	retagNoCover       bool
+	retagMinConfidence float64
+)
+
+func init() {
+	retagCmd.Flags().BoolVarP(&retagYes, "yes", "y", false, "Apply the changes without asking")
+	retagCmd.Flags().BoolVar(&retagDryRun, "dry-run", false, "Only show the changes")
+	retagCmd.Flags().BoolVar(&retagNoCover, "no-cover", false, "Leave cover art alone")
+	retagCmd.Flags().Float64Var(&retagMinConfidence, "min-confidence", backend.DefaultImportConfidence, "Minimum match score (0-1) for files without a Spotify ID")
+}
+
+type retagCommandResult struct {
+	Total     int                  `json:"total"`
+	Changed   int                  `json:"changed"`
+	Unmatched int                  `json:"unmatched"`
+	Applied   bool                 `json:"applied"`
+	Files     []*backend.RetagPlan `json:"files"`
+}
+
+func runRetag(cmd *cobra.Command, args []string) error {
+	var files []string
+	for _, arg := range args {
+		path := backend.NormalizePath(arg)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		audioFiles, err := backend.ListAudioFiles(path)
+		if err != nil {
+			return err
+		}
+		for _, file := range audioFiles {
+			files = append(files, file.Path)
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no audio files found")
+	}
+
+	retagger := &backend.Retagger{
+		MinConfidence:   retagMinConfidence,
+		SkipCover:       retagNoCover,
+		MaxQualityCover: downloadOptionsFromConfig().EmbedMaxQualityCover,
+		AppName:         "SpotiFLAC",
+	}
+
+	result := retagCommandResult{Total: len(files)}
+	var changed []*backend.RetagPlan
+	fmt.Printf("🔍 Matching %d files...\n", len(files))
+	for _, file := range files {
+		if err := cmd.Context().Err(); err != nil {
+			return err
+		}
+		plan := retagger.Plan(cmd.Context(), file)
+		result.Files = append(result.Files, plan)
+		printRetagPlan(plan)
+		switch {
+		case plan.Error != "":
+			result.Unmatched++
+		case plan.HasChanges():
+			changed = append(changed, plan)
+		}
+	}
+	result.Changed = len(changed)
+
+	fmt.Printf("\n📊 %d files to update, %d up to date, %d not matched\n", len(changed), len(files)-len(changed)-result.Unmatched, result.Unmatched)
+	if len(changed) == 0 || retagDryRun {
+		if machineOutput() {
+			return writeResult(result)
+		}
+		return nil
+	}
+
+	if !retagYes {
+		fmt.Printf("⚠️  Write these changes to %d files? [y/N]: ", len(changed))
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("❌ Cancelled")
+			if machineOutput() {
+				return writeResult(result)
+			}
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, plan := range changed {
+		if err := plan.Apply(); err != nil {
+			failed++
+			plan.Error = err.Error()
+			fmt.Printf("❌ %s: %v\n", plan.File, err)
+			continue
+		}
+		fmt.Printf("✅ %s\n", plan.File)
+	}
+	result.Applied = true
+
+	if machineOutput() {
+		if err := writeResult(result); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("\n📊 Summary: %d/%d files updated\n", len(changed)-failed, len(changed))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be updated", failed, len(changed))
+	}
+	return nil
+}
+
+func printRetagPlan(plan *backend.RetagPlan) {
+	if plan.Error != "" {
+		fmt.Printf("\n❌ %s: %s\n", plan.File, plan.Error)
+		return
+	}
+	if !plan.HasChanges() {
+		fmt.Printf("\n✔️  %s: up to date\n", plan.File)
+		return
+	}
+
+	how := map[string]string{
+		backend.MatchedBySpotifyID: "Spotify ID",
+		backend.MatchedByISRC:      "ISRC",
+		backend.MatchedBySearch:    fmt.Sprintf("search, score %.2f", plan.Confidence),
+	}[plan.MatchedBy]
+	fmt.Printf("\n📄 %s (matched by %s)\n", plan.File, how)
+	for _, change := range plan.Changes {
+		newValue := strings.Join(change.New, " | ")
+		if len(change.Old) == 0 {
+			fmt.Printf("   + %s: %s\n", change.Field, newValue)
+		} else {
+			fmt.Printf("   ~ %s: %s → %s\n", change.Field, strings.Join(change.Old, " | "), newValue)
+		}
+	}
+	if plan.Cover != "" {
+		fmt.Printf("   🖼️  Cover art %s\n", plan.Cover)
+	}
+}
@@ -1,6 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"spotiflac/backend"
+
 	"github.com/spf13/cobra"
 )
 
@@ -21,13 +31,70 @@ Examples:
 	CompletionOptions: cobra.CompletionOptions{
 		DisableDefaultCmd: false,
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupOutput(cmd); err != nil {
+			return err
+		}
+		if err := setupLogging(); err != nil {
+			return err
+		}
+		if err := applyRateLimit(); err != nil {
+			return err
+		}
+		if err := applyHTTPConfig(); err != nil {
+			return err
+		}
+		if err := applyTagSettings(); err != nil {
+			return err
+		}
+		return startMetricsServer(cmd.Context())
+	},
 }
 
+var (
+	limitRate string
+	proxyURL  string
+	tagMerge  string
+)
+
+// Execute runs the CLI. The first Ctrl+C (or SIGTERM) cancels the command's
+// context so downloads stop and clean up their partial files; a second one
+// terminates immediately.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	go func() {
+		select {
+		case <-signals:
+			fmt.Println("\n⚠️  Interrupted, stopping downloads... (press Ctrl+C again to force quit)")
+			signal.Stop(signals)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	defer closeLogging()
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil && machineOutput() {
+		writeErrorResult(err)
+	}
+	return err
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&outputMode, "output", outputText, "Result format: text, json or ndjson (ndjson streams download events)")
+	rootCmd.PersistentFlags().CountVarP(&logVerbose, "verbose", "v", "Show backend log messages on stderr (-v info, -vv debug)")
+	rootCmd.PersistentFlags().BoolVar(&logQuiet, "quiet", false, "Only log errors and hide progress lines")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Also write debug-level logs as JSON lines to this file")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "HTTP or SOCKS5 proxy for all requests, e.g. socks5://127.0.0.1:1080 (overrides config proxy)")
+	rootCmd.PersistentFlags().StringVar(&tagMerge, "tag-merge", "", "How embedding treats existing tags: overwrite, fill-missing or replace-all (overrides config tag-merge)")
+	rootCmd.PersistentFlags().StringVar(&limitRate, "limit-rate", "", "Limit total download bandwidth, e.g. 500K or 5M (overrides config limit-rate)")
+
 	rootCmd.AddCommand(downloadCmd)
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(metadataCmd)
@@ -38,4 +105,97 @@ func init() {
 	rootCmd.AddCommand(lyricsCmd)
 	rootCmd.AddCommand(coverCmd)
 	rootCmd.AddCommand(availabilityCmd)
+	rootCmd.AddCommand(queueCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(playlistCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(libraryCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(replayGainCmd)
+	rootCmd.AddCommand(tagCmd)
+	rootCmd.AddCommand(retagCmd)
+	rootCmd.AddCommand(identifyCmd)
+}
+
+// applyRateLimit sets the shared bandwidth limit from --limit-rate or the
+// config, plus the download-windows schedule from the config.
+func applyRateLimit() error {
+	config, _ := loadConfig(getConfigPath())
+
+	value := limitRate
+	if value == "" {
+		value, _ = config["limit-rate"].(string)
+	}
+	rate, err := backend.ParseRate(value)
+	if err != nil {
+		return err
+	}
+	backend.SetRateLimit(rate)
+
+	spec, _ := config["download-windows"].(string)
+	windows, err := backend.ParseRateWindows(spec)
+	if err != nil {
+		return fmt.Errorf("invalid download-windows in config: %w", err)
+	}
+	backend.SetRateWindows(windows)
+
+	return nil
+}
+
+// applyHTTPConfig sets up the proxy, CA bundle, insecure TLS services and
+// timeouts used by every backend HTTP client.
+func applyHTTPConfig() error {
+	config, _ := loadConfig(getConfigPath())
+
+	httpConfig := backend.HTTPConfig{Proxy: proxyURL}
+	if httpConfig.Proxy == "" {
+		httpConfig.Proxy, _ = config["proxy"].(string)
+	}
+	httpConfig.CABundle, _ = config["ca-bundle"].(string)
+
+	insecure, _ := config["insecure-tls"].(string)
+	for _, service := range strings.Split(insecure, ",") {
+		if service = strings.TrimSpace(service); service != "" {
+			httpConfig.InsecureTLS = append(httpConfig.InsecureTLS, service)
+		}
+	}
+
+	spec, _ := config["http-timeouts"].(string)
+	timeouts, err := backend.ParseHTTPTimeouts(spec)
+	if err != nil {
+		return fmt.Errorf("invalid http-timeouts in config: %w", err)
+	}
+	httpConfig.Timeouts = timeouts
+
+	return backend.SetHTTPConfig(httpConfig)
+}
+
+// applyTagSettings sets the tag merge policy from --tag-merge or the config,
+// plus the config's multi-value separator
+func applyTagSettings() error {
+	config, _ := loadConfig(getConfigPath())
+
+	value := tagMerge
+	if value == "" {
+		value, _ = config["tag-merge"].(string)
+	}
+	mode, err := backend.ParseTagMergeMode(value)
+	if err != nil {
+		return err
+	}
+	backend.SetTagMergeMode(mode)
+
+	if separator, ok := config["multi-value-separator"].(string); ok && separator != "" {
+		backend.SetMultiValueSeparator(separator)
+	}
+
+	limit := 0
+	if value, ok := config["genre-limit"].(string); ok && value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			return fmt.Errorf("invalid genre-limit: %s", value)
+		}
+	}
+	genreMap, _ := config["genre-map"].(string)
+	backend.SetGenreSettings(limit, genreMap)
+	return nil
 }
@@ -46,6 +46,13 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("search failed: %w", err)
 	}
 
+	if machineOutput() {
+		if results == nil {
+			results = []backend.SearchResult{}
+		}
+		return writeResult(searchResult{Query: query, Type: searchType, Results: results})
+	}
+
 	if len(results) == 0 {
 		fmt.Println("❌ No results found")
 		return nil
@@ -67,6 +74,12 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+type searchResult struct {
+	Query   string                 `json:"query"`
+	Type    string                 `json:"type"`
+	Results []backend.SearchResult `json:"results"`
+}
+
 func printTrackResults(results []backend.SearchResult) {
 	for i, result := range results {
 		fmt.Printf("%d. %s\n", i+1, result.Name)
@@ -156,6 +169,10 @@ func runMetadata(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to fetch metadata: %w", err)
 	}
 
+	if machineOutput() {
+		return writeResult(data)
+	}
+
 	if metadataFormat == "json" {
 		jsonBytes, err := json.MarshalIndent(data, "", "  ")
 		if err != nil {
@@ -0,0 +1,586 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local HTTP API for the download queue",
+	Long: `Run a daemon that downloads queued tracks and exposes a JSON API.
+
+Tracks are kept in the same persistent queue as "spotflac queue" and are
+downloaded one at a time with the options from the saved configuration.
+
+Endpoints:
+  GET  /api/queue              Queued tracks and the current session
+  POST /api/queue              Enqueue {"urls": ["https://open.spotify.com/..."]}
+  POST /api/queue/{id}/cancel  Cancel a queued or running track
+  POST /api/queue/{id}/retry   Queue a failed, skipped or cancelled track again
+  GET  /api/progress           Progress of the running download
+  GET  /api/events             Server-Sent Events stream of download events
+  GET  /api/history?q=         Download history, optionally filtered
+  GET  /api/search?q=&type=    Spotify search
+  GET  /metrics                Prometheus metrics
+
+POST requests must be sent as application/json, and requests from web pages
+on other origins are refused. Listening beyond loopback requires --token;
+clients then send "Authorization: Bearer <token>".
+
+Examples:
+  spotflac serve
+  spotflac serve --listen 127.0.0.1:9000
+  spotflac serve --listen 0.0.0.0:8480 --token "$SPOTFLAC_TOKEN"`,
+	RunE: runServe,
+}
+
+var (
+	serveListen string
+	serveToken  string
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8480", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Require this bearer token on every request (needed beyond loopback)")
+}
+
+type daemon struct {
+	wake   chan struct{}
+	events *eventBroker
+	token  string
+
+	mu            sync.Mutex
+	currentID     string
+	cancelCurrent context.CancelFunc
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if serveToken == "" && !isLoopbackAddr(serveListen) {
+		return fmt.Errorf("refusing to listen on %s without --token; use a loopback address or set a token", serveListen)
+	}
+	// A download that was running when the daemon last stopped starts over
+	if err := resetRunningTracks(); err != nil {
+		return fmt.Errorf("failed to load queue: %w", err)
+	}
+
+	d := &daemon{
+		wake:   make(chan struct{}, 1),
+		events: newEventBroker(),
+		token:  serveToken,
+	}
+	backend.SetDownloadEventHandler(d.events.publish)
+
+	listener, err := net.Listen("tcp", serveListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           d.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		d.runWorker(ctx)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("🌐 Listening on http://%s\n", listener.Addr())
+	err = server.Serve(listener)
+	<-workerDone
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (d *daemon) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/queue", d.handleQueue)
+	mux.HandleFunc("POST /api/queue", requireJSON(d.handleEnqueue))
+	mux.HandleFunc("POST /api/queue/{id}/cancel", requireJSON(d.handleCancel))
+	mux.HandleFunc("POST /api/queue/{id}/retry", requireJSON(d.handleRetry))
+	mux.HandleFunc("GET /api/progress", d.handleProgress)
+	mux.HandleFunc("GET /api/events", d.handleEvents)
+	mux.HandleFunc("GET /api/history", d.handleHistory)
+	mux.HandleFunc("GET /api/search", d.handleSearch)
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	return d.guard(mux)
+}
+
+// guard refuses requests from web pages on other origins, and requests
+// without the bearer token when one is set
+func (d *daemon) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				writeAPIError(w, http.StatusForbidden, fmt.Errorf("cross-origin requests are not allowed"))
+				return
+			}
+		}
+		if d.token != "" {
+			want := "Bearer " + d.token
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAPIError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid token"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireJSON rejects requests that aren't sent as application/json. Browsers
+// can't send that cross-site without a preflight, which the API never allows.
+func requireJSON(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			writeAPIError(w, http.StatusUnsupportedMediaType, fmt.Errorf("content type must be application/json"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// resetRunningTracks marks tracks left downloading by a daemon that
+// stopped mid-download as interrupted, so the worker picks them up again
+func resetRunningTracks() error {
+	tracks, err := backend.GetPendingTracks("SpotiFLAC")
+	if err != nil {
+		return err
+	}
+	for _, track := range tracks {
+		if track.Status == backend.StatusDownloading {
+			track.Status = backend.StatusInterrupted
+			if err := backend.UpdatePendingTrack(track, "SpotiFLAC"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runWorker downloads queued tracks one at a time until ctx is cancelled
+func (d *daemon) runWorker(ctx context.Context) {
+	for ctx.Err() == nil {
+		track, err := d.nextPending()
+		if err != nil {
+			fmt.Printf("❌ Failed to load queue: %v\n", err)
+		}
+		if track == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.wake:
+			case <-time.After(time.Minute):
+			}
+			continue
+		}
+		d.download(ctx, *track)
+	}
+}
+
+func (d *daemon) nextPending() (*backend.PendingTrack, error) {
+	tracks, err := backend.GetPendingTracks("SpotiFLAC")
+	if err != nil {
+		return nil, err
+	}
+	for _, track := range tracks {
+		if track.Status == backend.StatusQueued || track.Status == backend.StatusInterrupted {
+			return &track, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *daemon) download(ctx context.Context, queued backend.PendingTrack) {
+	opts, err := downloadOptionsFromConfig().prepare()
+	if err != nil {
+		queued.Status = backend.StatusFailed
+		queued.Error = err.Error()
+		backend.UpdatePendingTrack(queued, "SpotiFLAC")
+		return
+	}
+
+	itemCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	track, err := d.claim(queued.SpotifyID, cancel)
+	if err != nil {
+		fmt.Printf("❌ Failed to start %s: %v\n", queued.SpotifyID, err)
+		return
+	}
+	if track == nil {
+		// Cancelled or removed since it was picked
+		return
+	}
+	defer func() {
+		d.mu.Lock()
+		d.currentID = ""
+		d.cancelCurrent = nil
+		d.mu.Unlock()
+	}()
+
+	finish := func(resp DownloadResponse, err error) {
+		item := pendingResult(itemCtx, *track, resp, err)
+		if item.Status == backend.StatusInterrupted && ctx.Err() == nil {
+			// Stopped through the cancel endpoint rather than by shutdown
+			item.Status = backend.StatusSkipped
+			item.Error = "Cancelled"
+		}
+		backend.UpdatePendingTrack(item, "SpotiFLAC")
+	}
+
+	recorded := false
+	batch := []backend.ImportTrack{{SpotifyID: track.SpotifyID, Name: track.Title, Artists: track.Artists, Album: track.Album}}
+	runBatch(itemCtx, batch, opts, func(_ backend.ImportTrack, resp DownloadResponse, err error) {
+		recorded = true
+		finish(resp, err)
+	})
+	if !recorded {
+		// runBatch stops without a result when cancelled before the track
+		// starts, e.g. while waiting for a download window
+		err := itemCtx.Err()
+		if err == nil {
+			err = fmt.Errorf("download did not start")
+		}
+		finish(DownloadResponse{}, err)
+	}
+}
+
+// claim marks the track as downloading and makes it the current one, unless
+// it was cancelled in the meantime. Holding d.mu means a cancel either finds
+// the track still queued or running.
+func (d *daemon) claim(id string, cancel context.CancelFunc) (*backend.PendingTrack, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	track, err := backend.GetPendingTrack(id, "SpotiFLAC")
+	if err != nil || track == nil {
+		return nil, err
+	}
+	if track.Status != backend.StatusQueued && track.Status != backend.StatusInterrupted {
+		return nil, nil
+	}
+	track.Status = backend.StatusDownloading
+	track.Error = ""
+	if err := backend.UpdatePendingTrack(*track, "SpotiFLAC"); err != nil {
+		return nil, err
+	}
+	d.currentID = track.SpotifyID
+	d.cancelCurrent = cancel
+	return track, nil
+}
+
+func (d *daemon) notifyWorker() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+type queueResponse struct {
+	Pending []backend.PendingTrack    `json:"pending"`
+	Session backend.DownloadQueueInfo `json:"session"`
+}
+
+func (d *daemon) handleQueue(w http.ResponseWriter, r *http.Request) {
+	tracks, err := backend.GetPendingTracks("SpotiFLAC")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if tracks == nil {
+		tracks = []backend.PendingTrack{}
+	}
+	writeAPIJSON(w, http.StatusOK, queueResponse{Pending: tracks, Session: backend.GetDownloadQueue()})
+}
+
+type enqueueRequest struct {
+	URLs []string `json:"urls"`
+}
+
+type enqueueResponse struct {
+	Added         int                   `json:"added"`
+	AlreadyQueued int                   `json:"already_queued"`
+	Report        *backend.ImportReport `json:"report"`
+}
+
+func (d *daemon) handleEnqueue(w http.ResponseWriter, r *http.Request) {
+	var req enqueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	entries := backend.ParseImportLines(req.URLs)
+	if len(entries) == 0 {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("no urls given"))
+		return
+	}
+
+	report := backend.ResolveImportEntries(r.Context(), entries, backend.DefaultImportConfidence)
+	report.Source = "serve"
+
+	var pending []backend.PendingTrack
+	for _, track := range report.Tracks() {
+		pending = append(pending, backend.PendingTrack{
+			SpotifyID: track.SpotifyID,
+			Title:     track.Name,
+			Artists:   track.Artists,
+			Album:     track.Album,
+			Source:    "serve",
+		})
+	}
+
+	added, err := backend.AddPendingTracks(pending, "SpotiFLAC")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	d.notifyWorker()
+
+	writeAPIJSON(w, http.StatusAccepted, enqueueResponse{Added: added, AlreadyQueued: len(pending) - added, Report: report})
+}
+
+func (d *daemon) handleCancel(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.currentID == id && d.cancelCurrent != nil {
+		d.cancelCurrent()
+		writeAPIJSON(w, http.StatusAccepted, map[string]string{"id": id, "status": "cancelling"})
+		return
+	}
+
+	track, ok := d.lookupPending(w, id)
+	if !ok {
+		return
+	}
+	if track.Status != backend.StatusQueued && track.Status != backend.StatusInterrupted {
+		writeAPIError(w, http.StatusConflict, fmt.Errorf("track is %s", track.Status))
+		return
+	}
+
+	track.Status = backend.StatusSkipped
+	track.Error = "Cancelled"
+	if err := backend.UpdatePendingTrack(*track, "SpotiFLAC"); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, track)
+}
+
+func (d *daemon) handleRetry(w http.ResponseWriter, r *http.Request) {
+	track, ok := d.lookupPending(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if track.Status == backend.StatusQueued || track.Status == backend.StatusDownloading || track.Status == backend.StatusCompleted {
+		writeAPIError(w, http.StatusConflict, fmt.Errorf("track is %s", track.Status))
+		return
+	}
+
+	track.Status = backend.StatusQueued
+	track.Error = ""
+	if err := backend.UpdatePendingTrack(*track, "SpotiFLAC"); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	d.notifyWorker()
+	writeAPIJSON(w, http.StatusOK, track)
+}
+
+func (d *daemon) lookupPending(w http.ResponseWriter, id string) (*backend.PendingTrack, bool) {
+	track, err := backend.GetPendingTrack(id, "SpotiFLAC")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if track == nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("track not in queue: %s", id))
+		return nil, false
+	}
+	return track, true
+}
+
+type progressResponse struct {
+	backend.ProgressInfo
+	CurrentID string `json:"current_id,omitempty"`
+}
+
+func (d *daemon) handleProgress(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	current := d.currentID
+	d.mu.Unlock()
+
+	writeAPIJSON(w, http.StatusOK, progressResponse{ProgressInfo: backend.GetDownloadProgress(), CurrentID: current})
+}
+
+func (d *daemon) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	events, unsubscribe := d.events.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, data)
+		}
+		flusher.Flush()
+	}
+}
+
+func (d *daemon) handleHistory(w http.ResponseWriter, r *http.Request) {
+	items, err := backend.GetHistoryItems("SpotiFLAC")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if query := r.URL.Query().Get("q"); query != "" {
+		items = filterHistory(items, query)
+	}
+	writeAPIJSON(w, http.StatusOK, newHistoryResult(items))
+}
+
+func (d *daemon) handleSearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := params.Get("q")
+	if query == "" {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("missing q parameter"))
+		return
+	}
+
+	searchType := params.Get("type")
+	if searchType == "" {
+		searchType = "track"
+	}
+	limit := 10
+	if value := params.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %s", value))
+			return
+		}
+		limit = n
+	}
+
+	results, err := backend.SearchSpotifyByType(r.Context(), query, searchType, limit, 0)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	if results == nil {
+		results = []backend.SearchResult{}
+	}
+	writeAPIJSON(w, http.StatusOK, searchResult{Query: query, Type: searchType, Results: results})
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIJSON(w, status, errorResult{Error: err.Error(), Kind: backend.ErrorKind(err)})
+}
+
+// eventBroker fans download events out to every connected SSE client. Slow
+// clients miss events rather than blocking downloads.
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan downloadEvent]struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{subscribers: make(map[chan downloadEvent]struct{})}
+}
+
+func (b *eventBroker) subscribe() (<-chan downloadEvent, func()) {
+	ch := make(chan downloadEvent, 64)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+func (b *eventBroker) publish(event string, item backend.DownloadItem) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := downloadEvent{Event: event, Time: time.Now().Unix(), DownloadItem: item}
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeGuard(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		method      string
+		contentType string
+		origin      string
+		auth        string
+		want        int
+	}{
+		{name: "json post", method: "POST", contentType: "application/json", want: http.StatusOK},
+		{name: "json with charset", method: "POST", contentType: "application/json; charset=utf-8", want: http.StatusOK},
+		{name: "form post", method: "POST", contentType: "application/x-www-form-urlencoded", want: http.StatusUnsupportedMediaType},
+		{name: "text post", method: "POST", contentType: "text/plain", want: http.StatusUnsupportedMediaType},
+		{name: "no content type", method: "POST", want: http.StatusUnsupportedMediaType},
+		{name: "get needs no content type", method: "GET", want: http.StatusOK},
+		{name: "same origin", method: "POST", contentType: "application/json", origin: "http://example.com", want: http.StatusOK},
+		{name: "other origin", method: "POST", contentType: "application/json", origin: "http://evil.test", want: http.StatusForbidden},
+		{name: "other origin get", method: "GET", origin: "http://evil.test", want: http.StatusForbidden},
+		{name: "missing token", token: "secret", method: "GET", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", method: "GET", auth: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "right token", token: "secret", method: "GET", auth: "Bearer secret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &daemon{token: tt.token}
+			mux := http.NewServeMux()
+			ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+			mux.HandleFunc("GET /", ok)
+			mux.HandleFunc("POST /", requireJSON(ok))
+
+			req := httptest.NewRequest(tt.method, "http://example.com/api/queue", strings.NewReader("{}"))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			d.guard(mux).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:8480", true},
+		{"localhost:8480", true},
+		{"[::1]:8480", true},
+		{":8480", false},
+		{"0.0.0.0:8480", false},
+		{"192.168.1.2:8480", false},
+		{"nas.local:8480", false},
+		{"127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isLoopbackAddr(tt.addr); got != tt.want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <playlist-url> <dir>",
+	Short: "Mirror a Spotify playlist into a local folder",
+	Long: `Keep a folder in step with a Spotify playlist.
+
+Each run compares the playlist against the folder's state file (.spotiflac-sync.json)
+and only downloads tracks that are new. Tracks removed from the playlist can be
+moved to a .trash folder. An ordered M3U8 named after the playlist is rewritten
+after every run. Download options come from the saved configuration.
+
+Examples:
+  spotflac sync https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M ~/Music/TopHits
+  spotflac sync spotify:playlist:37i9dQZF1DXcBWIGoYBM5M ~/Music/TopHits --trash`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSync,
+}
+
+var (
+	syncTrash    bool
+	syncNoM3U8   bool
+	syncService  string
+	syncQuality  string
+	syncFilename string
+)
+
+func init() {
+	syncCmd.Flags().BoolVar(&syncTrash, "trash", false, "Move tracks removed from the playlist to a .trash folder")
+	syncCmd.Flags().BoolVar(&syncNoM3U8, "no-m3u8", false, "Do not write an M3U8 playlist file")
+	syncCmd.Flags().StringVarP(&syncService, "service", "s", "", "Streaming service (default: from config)")
+	syncCmd.Flags().StringVarP(&syncQuality, "quality", "q", "", "Audio quality (default: from config)")
+	syncCmd.Flags().StringVar(&syncFilename, "filename", "", "Filename format (default: from config)")
+	addMetricsFlag(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	playlistURL := args[0]
+
+	opts := downloadOptionsFromConfig()
+	opts.OutputDir = args[1]
+	if syncService != "" {
+		opts.Service = syncService
+	}
+	if syncQuality != "" {
+		opts.Quality = syncQuality
+	}
+	if syncFilename != "" {
+		opts.FilenameFormat = syncFilename
+	}
+
+	opts, err := opts.prepare()
+	if err != nil {
+		return err
+	}
+	dir := opts.OutputDir
+
+	fmt.Printf("📍 Fetching playlist: %s\n", playlistURL)
+	data, err := backend.GetFilteredSpotifyData(cmd.Context(), playlistURL, false, 0)
+	if err != nil {
+		return fmt.Errorf("failed to fetch playlist: %w", err)
+	}
+
+	playlist, ok := data.(backend.PlaylistResponsePayload)
+	if !ok {
+		return fmt.Errorf("not a playlist URL: %s", playlistURL)
+	}
+
+	state, err := backend.LoadSyncState(dir)
+	if err != nil {
+		return err
+	}
+	state.PlaylistID = playlistURL
+	state.PlaylistName = playlist.PlaylistInfo.Owner.Name
+
+	diff := backend.DiffPlaylist(state, playlist.TrackList)
+	fmt.Printf("📋 %s: %d tracks, %d new, %d removed, %d unchanged\n",
+		state.PlaylistName, len(playlist.TrackList), len(diff.Added), len(diff.Removed), diff.Kept)
+
+	for _, removed := range diff.Removed {
+		if syncTrash && removed.Path != "" {
+			if target, err := backend.MoveToTrash(dir, removed.Path); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			} else {
+				fmt.Printf("🗑️  Moved to trash: %s\n", target)
+			}
+		}
+		delete(state.Tracks, removed.SpotifyID)
+	}
+
+	var batch []backend.ImportTrack
+	added := make(map[string]backend.AlbumTrackMetadata)
+	for _, track := range diff.Added {
+		added[track.SpotifyID] = track
+		batch = append(batch, backend.ImportTrack{SpotifyID: track.SpotifyID, Name: track.Name, Artists: track.Artists, Album: track.AlbumName})
+	}
+
+	opts.CollectionType = "playlist"
+	opts.CollectionName = state.PlaylistName
+	summary := runBatch(cmd.Context(), batch, opts, func(track backend.ImportTrack, resp DownloadResponse, err error) {
+		if err != nil || resp.File == "" {
+			return
+		}
+		meta := added[track.SpotifyID]
+		state.Tracks[track.SpotifyID] = backend.SyncedTrack{
+			SpotifyID:  meta.SpotifyID,
+			Title:      meta.Name,
+			Artists:    meta.Artists,
+			Album:      meta.AlbumName,
+			DurationMS: meta.DurationMS,
+			Path:       resp.File,
+		}
+		if err := backend.SaveSyncState(dir, state); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	})
+
+	state.SetOrder(playlist.TrackList)
+	if err := backend.SaveSyncState(dir, state); err != nil {
+		return err
+	}
+
+	if !syncNoM3U8 {
+		playlistPath := backend.PlaylistFilePath(dir, state.PlaylistName, ".m3u8")
+		if err := backend.WriteM3U8(playlistPath, state.PlaylistEntries()); err != nil {
+			return fmt.Errorf("failed to write playlist: %w", err)
+		}
+		fmt.Printf("📁 Playlist: %s\n", playlistPath)
+	}
+
+	if len(batch) > 0 {
+		printBatchSummary(summary)
+	} else {
+		fmt.Println("✅ Already in sync")
+	}
+	sendWebhook(cmd.Context(), batchWebhookPayload(backend.WebhookSyncComplete, state.PlaylistName, "playlist", summary))
+
+	if summary.Interrupted {
+		return cmd.Context().Err()
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", summary.Failed, summary.Total)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "View and edit tags of local files",
+	Long: `View and edit the tags of FLAC, MP3 and M4A files.
+
+Fields use Vorbis comment names (TITLE, ARTIST, ALBUMARTIST, DATE,
+TRACKNUMBER, ...) for every format; they are mapped to the matching ID3v2
+frames and MP4 atoms, and other names become TXXX frames or freeform atoms.
+Only the fields named are changed, everything else in the file is kept.
+
+Files can be given as paths or glob patterns. Repeating a field in "set"
+writes several values.
+
+Examples:
+  spotflac tag show song.flac
+  spotflac tag set "Album/*.flac" ALBUM="Deluxe Edition" DATE=2021
+  spotflac tag set song.mp3 ARTIST=Alice ARTIST=Bob --cover cover.jpg
+  spotflac tag remove "*.m4a" COMMENT ENCODER
+  spotflac tag copy original.flac converted.mp3`,
+}
+
+var tagShowCmd = &cobra.Command{
+	Use:   "show <file|glob...>",
+	Short: "Show the tags of files",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTagShow,
+}
+
+var tagSetCmd = &cobra.Command{
+	Use:   "set <file|glob...> FIELD=value...",
+	Short: "Set tag fields, replacing their current values",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTagSet,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <file|glob...> FIELD...",
+	Short: "Remove tag fields",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTagRemove,
+}
+
+var tagCopyCmd = &cobra.Command{
+	Use:   "copy <source> <file|glob...>",
+	Short: "Copy tags and cover art from one file to others",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTagCopy,
+}
+
+var (
+	tagShowFields  []string
+	tagSetCover    string
+	tagRemoveCover bool
+	tagCopyFields  []string
+	tagCopyNoCover bool
+)
+
+func init() {
+	tagShowCmd.Flags().StringSliceVar(&tagShowFields, "field", nil, "Only show these fields, in full")
+	tagSetCmd.Flags().StringVar(&tagSetCover, "cover", "", "Embed this image as cover art")
+	tagRemoveCmd.Flags().BoolVar(&tagRemoveCover, "cover", false, "Remove the cover art")
+	tagCopyCmd.Flags().StringSliceVar(&tagCopyFields, "field", nil, "Only copy these fields")
+	tagCopyCmd.Flags().BoolVar(&tagCopyNoCover, "no-cover", false, "Don't copy the cover art")
+
+	tagCmd.AddCommand(tagShowCmd)
+	tagCmd.AddCommand(tagSetCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagCopyCmd)
+}
+
+type tagShowResult struct {
+	File string `json:"file"`
+	*backend.FileTags
+	Error string `json:"error,omitempty"`
+}
+
+type tagEditResult struct {
+	File  string `json:"file"`
+	Error string `json:"error,omitempty"`
+}
+
+type tagEditCommandResult struct {
+	Total   int             `json:"total"`
+	Failed  int             `json:"failed"`
+	Results []tagEditResult `json:"results"`
+}
+
+func runTagShow(cmd *cobra.Command, args []string) error {
+	files, err := expandTagFiles(args)
+	if err != nil {
+		return err
+	}
+	only := make([]string, len(tagShowFields))
+	for i, name := range tagShowFields {
+		only[i] = backend.CanonicalTagName(name)
+	}
+
+	var results []tagShowResult
+	failed := 0
+	for _, file := range files {
+		tags, err := backend.ReadTags(file)
+		if err != nil {
+			failed++
+			results = append(results, tagShowResult{File: file, Error: err.Error()})
+			fmt.Printf("❌ %s: %v\n", file, err)
+			continue
+		}
+		if len(only) > 0 {
+			tags.Fields = slices.DeleteFunc(tags.Fields, func(field backend.TagField) bool {
+				return !slices.Contains(only, field.Name)
+			})
+		}
+		results = append(results, tagShowResult{File: file, FileTags: tags})
+
+		fmt.Printf("\n📄 %s\n", file)
+		if len(tags.Fields) == 0 {
+			fmt.Println("   (no tags)")
+		}
+		for _, field := range tags.Fields {
+			value := field.Value
+			if lines := strings.Split(value, "\n"); len(lines) > 1 && len(only) == 0 {
+				value = fmt.Sprintf("%s … (%d lines)", strings.TrimSpace(lines[0]), len(lines))
+			}
+			fmt.Printf("   %s: %s\n", field.Name, value)
+		}
+		if len(only) == 0 {
+			if tags.HasCover {
+				fmt.Printf("   🖼️  Cover art: %.1f KB\n", float64(len(tags.Cover))/1024)
+			} else {
+				fmt.Println("   🖼️  Cover art: none")
+			}
+		}
+	}
+
+	if machineOutput() {
+		if err := writeResult(results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be read", failed, len(files))
+	}
+	return nil
+}
+
+func runTagSet(cmd *cobra.Command, args []string) error {
+	patterns, assignments := splitTagArgs(args, func(arg string) bool {
+		name, _, ok := strings.Cut(arg, "=")
+		return ok && name != "" && !strings.ContainsAny(name, `/\*?[`)
+	})
+	if len(assignments) == 0 && tagSetCover == "" {
+		return fmt.Errorf("nothing to set: give FIELD=value pairs or --cover")
+	}
+
+	var edit backend.TagEdit
+	for _, assignment := range assignments {
+		name, value, _ := strings.Cut(assignment, "=")
+		edit.Set = append(edit.Set, backend.TagField{Name: name, Value: value})
+	}
+	if tagSetCover != "" {
+		cover, err := os.ReadFile(backend.NormalizePath(tagSetCover))
+		if err != nil {
+			return fmt.Errorf("failed to read cover image: %w", err)
+		}
+		edit.Cover = cover
+	}
+
+	files, err := expandTagFiles(patterns)
+	if err != nil {
+		return err
+	}
+	return editTags(files, edit)
+}
+
+func runTagRemove(cmd *cobra.Command, args []string) error {
+	patterns, names := splitTagArgs(args, func(arg string) bool {
+		return !strings.ContainsAny(arg, `/\*?[.=`)
+	})
+	if len(names) == 0 && !tagRemoveCover {
+		return fmt.Errorf("nothing to remove: give field names or --cover")
+	}
+
+	files, err := expandTagFiles(patterns)
+	if err != nil {
+		return err
+	}
+	edit := backend.TagEdit{Remove: names, RemoveCover: tagRemoveCover}
+	return editTags(files, edit)
+}
+
+func runTagCopy(cmd *cobra.Command, args []string) error {
+	source := backend.NormalizePath(args[0])
+	tags, err := backend.ReadTags(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	var edit backend.TagEdit
+	for _, field := range tags.Fields {
+		if len(tagCopyFields) == 0 || slices.ContainsFunc(tagCopyFields, func(name string) bool {
+			return backend.CanonicalTagName(name) == field.Name
+		}) {
+			edit.Set = append(edit.Set, field)
+		}
+	}
+	if !tagCopyNoCover {
+		edit.Cover = tags.Cover
+	}
+
+	files, err := expandTagFiles(args[1:])
+	if err != nil {
+		return err
+	}
+	files = slices.DeleteFunc(files, func(file string) bool { return file == source })
+	return editTags(files, edit)
+}
+
+// editTags applies edit to every file and reports the results
+func editTags(files []string, edit backend.TagEdit) error {
+	result := tagEditCommandResult{Total: len(files)}
+	for _, file := range files {
+		if err := backend.EditTags(file, edit); err != nil {
+			result.Failed++
+			result.Results = append(result.Results, tagEditResult{File: file, Error: err.Error()})
+			fmt.Printf("❌ %s: %v\n", file, err)
+			continue
+		}
+		result.Results = append(result.Results, tagEditResult{File: file})
+		fmt.Printf("✅ %s\n", file)
+	}
+
+	if machineOutput() {
+		if err := writeResult(result); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\n📊 Summary: %d/%d files updated\n", result.Total-result.Failed, result.Total)
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d files could not be updated", result.Failed, result.Total)
+	}
+	return nil
+}
+
+// splitTagArgs separates field arguments from file arguments. An argument
+// naming an existing file is always a file.
+func splitTagArgs(args []string, isField func(arg string) bool) (patterns, fields []string) {
+	for _, arg := range args {
+		if _, err := os.Stat(arg); err != nil && isField(arg) {
+			fields = append(fields, arg)
+		} else {
+			patterns = append(patterns, arg)
+		}
+	}
+	return patterns, fields
+}
+
+// expandTagFiles resolves paths and glob patterns to files, in order and
+// without duplicates
+func expandTagFiles(patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no files given")
+	}
+
+	var files []string
+	for _, pattern := range patterns {
+		pattern = backend.NormalizePath(pattern)
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", pattern)
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				if len(matches) == 1 {
+					return nil, fmt.Errorf("%s is a directory", match)
+				}
+				continue
+			}
+			if !slices.Contains(files, match) {
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
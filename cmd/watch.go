@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"spotiflac/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch artists for new releases",
+	Long: `Watch artists and queue their new releases for download.
+
+"watch check" compares each artist's discography with the last release it has
+seen and adds the tracks of newer releases to the download queue. Queued tracks
+are downloaded with the saved configuration by "queue run", or right away
+with --download.
+
+Examples:
+  spotflac watch add https://open.spotify.com/artist/06HL4z0CvFAxyc27GXpf02 --groups album,single
+  spotflac watch list
+  spotflac watch check --download
+  spotflac watch remove 06HL4z0CvFAxyc27GXpf02
+
+Cron example (every morning at 6):
+  0 6 * * * spotflac watch check --download`,
+}
+
+var watchAddCmd = &cobra.Command{
+	Use:   "add <artist-url|artist-id>",
+	Short: "Start watching an artist",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artistID, err := backend.ParseSpotifyArtistID(args[0])
+		if err != nil {
+			return err
+		}
+
+		groups, err := parseWatchGroups(watchGroups)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("📍 Fetching discography for: %s\n", artistID)
+		name, releases, err := backend.GetArtistReleases(cmd.Context(), artistID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch discography: %w", err)
+		}
+
+		artist := backend.WatchedArtist{
+			ArtistID: artistID,
+			Name:     name,
+			Groups:   groups,
+		}
+		// Only releases after today's newest one count as new
+		artist.MarkSeen(releases)
+
+		if err := backend.SaveWatchedArtist(artist, "SpotiFLAC"); err != nil {
+			return fmt.Errorf("failed to save watch: %w", err)
+		}
+
+		fmt.Printf("✅ Watching %s (%s), latest release: %s\n", name, strings.Join(groups, ", "), artist.LastReleaseDate)
+		return nil
+	},
+}
+
+var watchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show watched artists",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artists, err := backend.GetWatchedArtists("SpotiFLAC")
+		if err != nil {
+			return fmt.Errorf("failed to load watches: %w", err)
+		}
+
+		if len(artists) == 0 {
+			fmt.Println("📭 No watched artists")
+			return nil
+		}
+
+		fmt.Printf("📋 Watched artists (%d):\n\n", len(artists))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Artist\tID\tGroups\tLast Release\tLast Checked")
+		fmt.Fprintln(w, "─────────────────────────────────────────────────────")
+
+		for _, artist := range artists {
+			checked := "never"
+			if artist.LastChecked > 0 {
+				checked = time.Unix(artist.LastChecked, 0).Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", artist.Name, artist.ArtistID, strings.Join(artist.Groups, ","), artist.LastReleaseDate, checked)
+		}
+		w.Flush()
+
+		return nil
+	},
+}
+
+var watchRemoveCmd = &cobra.Command{
+	Use:   "remove <artist-url|artist-id>",
+	Short: "Stop watching an artist",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artistID, err := backend.ParseSpotifyArtistID(args[0])
+		if err != nil {
+			return err
+		}
+
+		found, err := backend.RemoveWatchedArtist(artistID, "SpotiFLAC")
+		if err != nil {
+			return fmt.Errorf("failed to remove watch: %w", err)
+		}
+		if !found {
+			return fmt.Errorf("artist is not watched: %s", artistID)
+		}
+
+		fmt.Printf("✅ Stopped watching %s\n", artistID)
+		return nil
+	},
+}
+
+var watchCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Queue new releases from watched artists",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artists, err := backend.GetWatchedArtists("SpotiFLAC")
+		if err != nil {
+			return fmt.Errorf("failed to load watches: %w", err)
+		}
+
+		if len(artists) == 0 {
+			fmt.Println("📭 No watched artists")
+			return nil
+		}
+
+		totalQueued := 0
+		checked := 0
+		var failed []string
+		for _, artist := range artists {
+			if cmd.Context().Err() != nil {
+				break
+			}
+			checked++
+
+			fmt.Printf("🔍 Checking %s...\n", artist.Name)
+			_, releases, err := backend.GetArtistReleases(cmd.Context(), artist.ArtistID)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", artist.Name, err)
+				failed = append(failed, artist.Name)
+				continue
+			}
+
+			fresh := artist.NewReleases(releases)
+			complete := true
+			for _, release := range fresh {
+				collection, err := backend.GetSpotifyCollection(cmd.Context(), release.ExternalURL)
+				if err != nil {
+					fmt.Printf("❌ %s: %v\n", release.Name, err)
+					complete = false
+					continue
+				}
+
+				var pending []backend.PendingTrack
+				for _, track := range collection.Tracks {
+					pending = append(pending, backend.PendingTrack{
+						SpotifyID: track.SpotifyID,
+						Title:     track.Name,
+						Artists:   track.Artists,
+						Album:     track.AlbumName,
+						Source:    "watch:" + artist.ArtistID,
+					})
+				}
+
+				added, err := backend.AddPendingTracks(pending, "SpotiFLAC")
+				if err != nil {
+					return fmt.Errorf("failed to update queue: %w", err)
+				}
+				totalQueued += added
+				fmt.Printf("🆕 %s - %s (%s, %s): %d tracks queued\n", artist.Name, release.Name, release.Type, release.ReleaseDate, added)
+			}
+
+			// Keep the old marker if a release could not be queued so the next run retries it
+			if complete {
+				artist.MarkSeen(releases)
+			}
+			if err := backend.SaveWatchedArtist(artist, "SpotiFLAC"); err != nil {
+				return fmt.Errorf("failed to save watch: %w", err)
+			}
+		}
+
+		fmt.Printf("✅ %d new tracks queued\n", totalQueued)
+		sendWebhook(cmd.Context(), backend.WebhookPayload{
+			Event:       backend.WebhookWatchComplete,
+			Name:        "watch",
+			Type:        "artists",
+			Total:       len(artists),
+			Completed:   checked - len(failed),
+			Failed:      len(failed),
+			Queued:      totalQueued,
+			Interrupted: cmd.Context().Err() != nil,
+			Failures:    failed,
+		})
+
+		if watchDownload && totalQueued > 0 {
+			if err := runPendingQueue(cmd.Context(), false); err != nil {
+				return err
+			}
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to check %d artists: %s", len(failed), strings.Join(failed, ", "))
+		}
+		return nil
+	},
+}
+
+var (
+	watchGroups   string
+	watchDownload bool
+)
+
+func init() {
+	watchAddCmd.Flags().StringVar(&watchGroups, "groups", "album,single", "Release groups to watch: album, single, compilation")
+	watchCheckCmd.Flags().BoolVar(&watchDownload, "download", false, "Download queued tracks after checking")
+	addMetricsFlag(watchCheckCmd)
+
+	watchCmd.AddCommand(watchAddCmd)
+	watchCmd.AddCommand(watchListCmd)
+	watchCmd.AddCommand(watchRemoveCmd)
+	watchCmd.AddCommand(watchCheckCmd)
+}
+
+func parseWatchGroups(value string) ([]string, error) {
+	var groups []string
+	for _, group := range strings.Split(value, ",") {
+		group = strings.ToLower(strings.TrimSpace(group))
+		if group == "" {
+			continue
+		}
+		if !slices.Contains(backend.ValidWatchGroups, group) {
+			return nil, fmt.Errorf("invalid group: %s (valid: %s)", group, strings.Join(backend.ValidWatchGroups, ", "))
+		}
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("at least one group is required")
+	}
+	return groups, nil
+}
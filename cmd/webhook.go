@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"spotiflac/backend"
+)
+
+// Webhooks are still delivered when a run is interrupted, within this limit
+const webhookTimeout = 2 * time.Minute
+
+func loadWebhook() backend.WebhookConfig {
+	config, err := loadConfig(getConfigPath())
+	if err != nil {
+		return backend.WebhookConfig{}
+	}
+
+	url, _ := config["webhook-url"].(string)
+	secret, _ := config["webhook-secret"].(string)
+	templatePath, _ := config["webhook-template"].(string)
+	return backend.WebhookConfig{URL: url, Secret: secret, TemplatePath: templatePath}
+}
+
+// sendWebhook delivers payload to the configured webhook, if any. Delivery
+// failures are reported but don't change the command's result.
+func sendWebhook(ctx context.Context, payload backend.WebhookPayload) {
+	config := loadWebhook()
+	if config.URL == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookTimeout)
+	defer cancel()
+
+	payload.Time = time.Now().Unix()
+	if err := backend.SendWebhook(ctx, config, payload); err != nil {
+		fmt.Printf("⚠️  Webhook failed: %v\n", err)
+	}
+}
+
+func batchWebhookPayload(event, name, collectionType string, summary batchSummary) backend.WebhookPayload {
+	return backend.WebhookPayload{
+		Event:       event,
+		Name:        name,
+		Type:        collectionType,
+		Total:       summary.Total,
+		Completed:   summary.Completed,
+		Skipped:     summary.Skipped,
+		Failed:      summary.Failed,
+		Interrupted: summary.Interrupted,
+		Failures:    summary.Failures,
+	}
+}
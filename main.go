@@ -12,10 +12,12 @@ func main() {
 	if err := backend.InitHistoryDB("SpotiFLAC"); err != nil {
 		// Non-fatal initialization error
 	}
-	defer backend.CloseHistoryDB()
 
-	// Execute root command
-	if err := cmd.Execute(); err != nil {
+	// Execute root command. The database is closed explicitly because
+	// os.Exit skips deferred calls.
+	err := cmd.Execute()
+	backend.CloseHistoryDB()
+	if err != nil {
 		os.Exit(1)
 	}
 }